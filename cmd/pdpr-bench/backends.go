@@ -0,0 +1,173 @@
+package main
+
+import (
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/crypto/hash/ghash"
+	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+// benchCase is a point of the grid. Parameters not used by the backend are zero
+type benchCase struct {
+	Backend     string `json:"backend"`
+	ModulusBits uint   `json:"modulus_bits"`
+	ChunkCount  uint   `json:"chunk_count,omitempty"`
+	BlockSize   int    `json:"block_size,omitempty"`
+	DataSize    int    `json:"data_size"`
+}
+
+// benchBackend prepares a single operation over random data and key, outside
+// of the measurements
+type benchBackend struct {
+	usesChunks bool
+	usesBlock  bool
+	prepare    func(c benchCase, data []byte, key []byte) (func() error, error)
+}
+
+var benchBackends = map[string]benchBackend{
+	"gcrypt": {false, false, prepareGCrypt},
+	"lthash": {true, true, prepareLtHash},
+	"ghash":  {true, true, prepareGHash},
+	"pdpr":   {true, false, preparePdpr},
+	"por":    {true, false, preparePor},
+}
+
+// porSamples is the number of blocks challenged by the por backend, as in por_bench_test.go
+const porSamples = 64
+
+var errVerificationFailed = errorutils.New("proof verification failed")
+
+// prepareGCrypt encrypts and decrypts
+func prepareGCrypt(c benchCase, data []byte, key []byte) (func() error, error) {
+	crypt, err := gcrypt.New(uint64(c.ModulusBits))
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		ciphertext, err := crypt.Encrypt(data, key)
+		if err != nil {
+			return err
+		}
+
+		crypt.Decrypt(ciphertext, key)
+		return nil
+	}, nil
+}
+
+// prepareLtHash computes the digest of the data, split in blocks
+func prepareLtHash(c benchCase, data []byte, key []byte) (func() error, error) {
+	// the benchmarks also sweep blocks that do not match the chunks, which
+	// lthash.Params.Check rejects
+	if _, err := lthash.New(c.ChunkCount, c.ModulusBits, c.BlockSize, key); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		hash, err := lthash.New(c.ChunkCount, c.ModulusBits, c.BlockSize, key)
+		if err != nil {
+			return err
+		}
+
+		return hash.ComputeDigest(data)
+	}, nil
+}
+
+// prepareGHash computes the digest of the data with a nonce
+func prepareGHash(c benchCase, data []byte, key []byte) (func() error, error) {
+	if _, err := ghash.NewWithParams(c.ChunkCount, c.ModulusBits, c.BlockSize, key); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		hash, err := ghash.NewWithParams(c.ChunkCount, c.ModulusBits, c.BlockSize, key)
+		if err != nil {
+			return err
+		}
+
+		if err := hash.SetNonce(key); err != nil {
+			return err
+		}
+
+		return hash.AddBytes(data)
+	}, nil
+}
+
+// preparePdpr runs the full protocol: encrypt, tag, prove and verify
+func preparePdpr(c benchCase, data []byte, key []byte) (func() error, error) {
+	params := pdpr.Params{ModulusBits: c.ModulusBits, ChunkCount: c.ChunkCount}
+	if err := params.Check(); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		ciphertext, err := pdpr.Encrypt(params, key, data)
+		if err != nil {
+			return err
+		}
+
+		tag, state, err := pdpr.NewTag(params, key, ciphertext, len(data))
+		if err != nil {
+			return err
+		}
+
+		challenge, err := tag.NextChallenge(params)
+		if err != nil {
+			return err
+		}
+
+		proof, err := pdpr.Prove(params, ciphertext, state, challenge)
+		if err != nil {
+			return err
+		}
+
+		if !pdpr.Verify(params, key, tag, challenge, proof) {
+			return errVerificationFailed
+		}
+
+		return nil
+	}, nil
+}
+
+// preparePor tags the data once, and then challenges, proves and verifies
+func preparePor(c benchCase, data []byte, key []byte) (func() error, error) {
+	params := por.Params{ModulusBitsize: c.ModulusBits, ChunkCount: c.ChunkCount}
+	tagger, err := por.NewTagger(params, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// NewTagger checked the modulus
+	ciphertext, err := gcrypt.MustNew(uint64(c.ModulusBits)).Encrypt(data, key)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := tagger.Blocks(ciphertext)
+	tags, err := tagger.Tag(blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := min(porSamples, len(blocks))
+
+	return func() error {
+		challenge, err := por.NewChallenge(uint64(c.ModulusBits), uint64(len(blocks)), samples)
+		if err != nil {
+			return err
+		}
+
+		proof, err := por.Prove(params, blocks, tags, challenge)
+		if err != nil {
+			return err
+		}
+
+		if !tagger.Verify(challenge, proof) {
+			return errVerificationFailed
+		}
+
+		return nil
+	}, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+func runCompare(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	alpha := fs.Float64("alpha", 0.05, "significance level")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return errorutils.New("compare: expected two result files")
+	}
+
+	before, err := readResults(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	after, err := readResults(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	afterResults := make(map[benchCase]result, len(after.Results))
+	for _, r := range after.Results {
+		afterResults[r.benchCase] = r
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "case\told\tnew\tdelta\tp")
+
+	for _, o := range before.Results {
+		n, ok := afterResults[o.benchCase]
+		if !ok {
+			continue
+		}
+
+		oldNs, newNs := o.nsPerOp(), n.nsPerOp()
+		delta := "~"
+		p := welchTTest(oldNs, newNs)
+		if p < *alpha {
+			delta = fmt.Sprintf("%+.1f%%", 100*(mean(newNs)-mean(oldNs))/mean(oldNs))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s ± %.1f%%\t%s ± %.1f%%\t%s\t%.3f\n", o.label(),
+			formatNs(mean(oldNs)), 100*stddev(oldNs)/mean(oldNs),
+			formatNs(mean(newNs)), 100*stddev(newNs)/mean(newNs),
+			delta, p)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package main
+
+import (
+	"time"
+)
+
+// cpuTime is not recorded on this platform
+func cpuTime() time.Duration {
+	return 0
+}
@@ -0,0 +1,93 @@
+// Command pdpr-bench benchmarks the PDPr primitives over grids of parameters,
+// and compares the results of two runs.
+//
+// Usage:
+//
+//	pdpr-bench run     [-backend pdpr,ghash,lthash,gcrypt,por] [-modulus 64,128] [-chunks 16]
+//	                   [-block 16] [-size 1024,65536] [-warmup 1] [-reps 5] [-mintime 200ms] -out results.json
+//	pdpr-bench compare [-alpha 0.05] old.json new.json
+//
+// Every flag of run but -out takes a comma-separated list, and run measures
+// every combination of them. Each case runs its warmups, then -reps
+// repetitions of at least -mintime each, recording the wall and CPU time and
+// the allocations per operation. Results are written as JSON, or as CSV when
+// -out ends in .csv; compare reads the JSON results only.
+//
+// compare reports the change of the mean time per operation of every case
+// found in both files, with the p-value of Welch's t-test; changes with a
+// p-value of at least -alpha are marked as not significant.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+type command struct {
+	summary string
+	run     func(args []string, out io.Writer) error
+}
+
+var commands = map[string]command{
+	"run":     {"benchmark a grid of parameters", runBench},
+	"compare": {"compare two result files", runCompare},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "pdpr-bench:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		usage(out)
+		return errorutils.New("no command given")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(out)
+		return errorutils.Newf("unknown command %q", args[0])
+	}
+
+	return cmd.run(args[1:], out)
+}
+
+func usage(out io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(out, "usage: pdpr-bench <command> [flags]")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-8s %s\n", name, commands[name].summary)
+	}
+}
+
+// parseFlags parses args, and checks that every flag in required was set
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for _, name := range required {
+		if !set[name] {
+			return errorutils.Newf("%s: -%s is required", fs.Name(), name)
+		}
+	}
+
+	return nil
+}
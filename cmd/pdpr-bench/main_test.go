@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+)
+
+func runPdprBench(ez *ez.EzTest, args ...string) string {
+	out := new(bytes.Buffer)
+	ez.AssertNoError(run(args, out))
+
+	return out.String()
+}
+
+func Test__PdprBench__RunThenCompare__ShouldWriteAndCompareResults(t *testing.T) {
+	ez := ez.New(t)
+	dir := t.TempDir()
+	jsonPath, csvPath := filepath.Join(dir, "results.json"), filepath.Join(dir, "results.csv")
+	flags := []string{"-backend", "gcrypt,ghash", "-modulus", "64", "-chunks", "4", "-block", "8,16", "-size", "16", "-reps", "2", "-mintime", "1ms"}
+
+	runPdprBench(ez, append([]string{"run", "-out", jsonPath}, flags...)...)
+	runPdprBench(ez, append([]string{"run", "-out", csvPath}, flags...)...)
+
+	rf, err := readResults(jsonPath)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(len(rf.Results), 3)
+	ez.AssertAreEqual(rf.Results[0].benchCase, benchCase{Backend: "gcrypt", ModulusBits: 64, DataSize: 16})
+	ez.AssertAreEqual(len(rf.Results[0].Samples), 2)
+	ez.Assert(rf.Results[0].Samples[0].NsPerOp > 0)
+
+	csv, err := os.ReadFile(csvPath)
+	ez.AssertNoError(err)
+	lines := strings.Split(strings.TrimSpace(string(csv)), "\n")
+	ez.AssertAreEqual(len(lines), 4)
+	ez.AssertAreEqual(lines[0], strings.Join(csvHeader, ","))
+
+	out := runPdprBench(ez, "compare", jsonPath, jsonPath)
+	ez.Assert(strings.Contains(out, "ghash/m64/c4/b16/s16"))
+	ez.Assert(strings.Contains(out, "~"))
+}
+
+func Test__PdprBench__Run__ShouldReject__InvalidGrids(t *testing.T) {
+	ez := ez.New(t)
+	out := filepath.Join(t.TempDir(), "results.json")
+
+	ez.AssertFalse(run([]string{"run", "-backend", "sha1", "-out", out}, new(bytes.Buffer)) == nil)
+	ez.AssertFalse(run([]string{"run", "-modulus", "100", "-backend", "gcrypt", "-out", out}, new(bytes.Buffer)) == nil)
+	ez.AssertFalse(run([]string{"run", "-size", "big", "-out", out}, new(bytes.Buffer)) == nil)
+	ez.AssertFalse(run([]string{"run"}, new(bytes.Buffer)) == nil)
+}
+
+func Test__WelchTTest__ShouldMatch__KnownValues(t *testing.T) {
+	ez := ez.New(t)
+	near := func(x float64, y float64) bool { return math.Abs(x-y) < 1e-4 }
+
+	// t = -1 with 8 degrees of freedom
+	ez.Assert(near(welchTTest([]float64{1, 2, 3, 4, 5}, []float64{2, 3, 4, 5, 6}), 0.3466))
+	ez.Assert(near(welchTTest([]float64{1, 2, 3, 4, 5}, []float64{1, 2, 3, 4, 5}), 1))
+	ez.Assert(welchTTest([]float64{1, 1.1, 0.9, 1, 1.05}, []float64{2, 2.1, 1.9, 2, 2.05}) < 1e-6)
+	ez.AssertAreEqual(welchTTest([]float64{1}, []float64{2, 3}), 1.0)
+
+	ez.Assert(near(incompleteBeta(1, 1, 0.3), 0.3))
+	ez.Assert(near(incompleteBeta(2, 3, 0.4), 0.5248))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"runtime"
+	"time"
+)
+
+// sample is a single repetition of a case
+type sample struct {
+	Iterations  int     `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	CPUNsPerOp  float64 `json:"cpu_ns_per_op"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op"`
+}
+
+// maxGrowth bounds the growth of the iterations between two attempts, as in package testing
+const maxGrowth = 100
+
+// measure runs op for at least minTime, increasing the iterations until then
+func measure(op func() error, minTime time.Duration) (sample, error) {
+	n := 1
+	for {
+		s, elapsed, err := runIterations(op, n)
+		if err != nil || elapsed >= minTime {
+			return s, err
+		}
+
+		// aim 20% past minTime, so that the next attempt is likely the last one
+		next := int(float64(n) * 1.2 * float64(minTime) / float64(max(elapsed, 1)))
+		n = max(n+1, min(next, n*maxGrowth))
+	}
+}
+
+func runIterations(op func() error, n int) (sample, time.Duration, error) {
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	cpuStart := cpuTime()
+	start := time.Now()
+
+	for i := 0; i < n; i++ {
+		if err := op(); err != nil {
+			return sample{}, 0, err
+		}
+	}
+
+	elapsed := time.Since(start)
+	cpu := cpuTime() - cpuStart
+	runtime.ReadMemStats(&after)
+
+	s := sample{
+		Iterations:  n,
+		NsPerOp:     float64(elapsed.Nanoseconds()) / float64(n),
+		CPUNsPerOp:  float64(cpu.Nanoseconds()) / float64(n),
+		AllocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(n),
+		BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / float64(n),
+	}
+
+	return s, elapsed, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// resultsFile is the JSON output of run, and the input of compare
+type resultsFile struct {
+	GoVersion string   `json:"go_version"`
+	GOOS      string   `json:"goos"`
+	GOARCH    string   `json:"goarch"`
+	CPUs      int      `json:"cpus"`
+	Warmup    int      `json:"warmup"`
+	Reps      int      `json:"reps"`
+	MinTime   string   `json:"min_time"`
+	Results   []result `json:"results"`
+}
+
+type result struct {
+	benchCase
+	Samples []sample `json:"samples"`
+}
+
+func (r result) nsPerOp() []float64 {
+	return r.field(func(s sample) float64 { return s.NsPerOp })
+}
+
+func (r result) field(f func(s sample) float64) []float64 {
+	xs := make([]float64, len(r.Samples))
+	for i, s := range r.Samples {
+		xs[i] = f(s)
+	}
+
+	return xs
+}
+
+// label names the case by its backend and the parameters it uses
+func (c benchCase) label() string {
+	parts := []string{c.Backend, fmt.Sprintf("m%d", c.ModulusBits)}
+	if c.ChunkCount != 0 {
+		parts = append(parts, fmt.Sprintf("c%d", c.ChunkCount))
+	}
+	if c.BlockSize != 0 {
+		parts = append(parts, fmt.Sprintf("b%d", c.BlockSize))
+	}
+	parts = append(parts, fmt.Sprintf("s%d", c.DataSize))
+
+	return strings.Join(parts, "/")
+}
+
+func writeResults(path string, rf *resultsFile) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(path, ".csv") {
+		err = writeCSV(f, rf)
+	} else {
+		err = writeJSON(f, rf)
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func writeJSON(w io.Writer, rf *resultsFile) error {
+	bs, err := json.MarshalIndent(rf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(bs, '\n'))
+	return err
+}
+
+var csvHeader = []string{
+	"backend", "modulus_bits", "chunk_count", "block_size", "data_size", "reps",
+	"ns_per_op", "ns_per_op_stddev", "cpu_ns_per_op", "allocs_per_op", "bytes_per_op", "mb_per_s",
+}
+
+// writeCSV writes a row per case, with the means of its repetitions
+func writeCSV(w io.Writer, rf *resultsFile) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, r := range rf.Results {
+		ns := mean(r.nsPerOp())
+		row := []string{
+			r.Backend,
+			strconv.FormatUint(uint64(r.ModulusBits), 10),
+			strconv.FormatUint(uint64(r.ChunkCount), 10),
+			strconv.Itoa(r.BlockSize),
+			strconv.Itoa(r.DataSize),
+			strconv.Itoa(len(r.Samples)),
+			formatFloat(ns),
+			formatFloat(stddev(r.nsPerOp())),
+			formatFloat(mean(r.field(func(s sample) float64 { return s.CPUNsPerOp }))),
+			formatFloat(mean(r.field(func(s sample) float64 { return s.AllocsPerOp }))),
+			formatFloat(mean(r.field(func(s sample) float64 { return s.BytesPerOp }))),
+			formatFloat(throughput(r.DataSize, ns)),
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func readResults(path string) (*resultsFile, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(resultsFile)
+	if err := json.Unmarshal(bs, r); err != nil {
+		return nil, errorutils.NewfWithInner(err, "%s is not a JSON results file", path)
+	}
+
+	return r, nil
+}
+
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'f', 2, 64)
+}
+
+// throughput is in MB/s of plaintext
+func throughput(dataSize int, nsPerOp float64) float64 {
+	if nsPerOp == 0 {
+		return 0
+	}
+
+	return float64(dataSize) / nsPerOp * 1e3
+}
+
+// formatNs rounds a duration to about 4 significant digits
+func formatNs(ns float64) string {
+	d := time.Duration(ns)
+	for _, unit := range []time.Duration{time.Second, time.Millisecond, time.Microsecond} {
+		if d >= unit {
+			return d.Round(unit / 1000).String()
+		}
+	}
+
+	return d.String()
+}
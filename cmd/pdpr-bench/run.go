@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+const keySize = 32
+
+func runBench(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	backendList := fs.String("backend", "pdpr", "backends: "+strings.Join(backendNames(), ", "))
+	modulusList := fs.String("modulus", "128", "modulus sizes in bits")
+	chunksList := fs.String("chunks", "1024", "chunk counts of the hashes")
+	blockList := fs.String("block", "16", "block sizes of the lthash and ghash backends, in bytes")
+	sizeList := fs.String("size", "1024", "plaintext sizes in bytes")
+	warmup := fs.Int("warmup", 1, "warmup runs of each case, not recorded")
+	reps := fs.Int("reps", 5, "repetitions of each case")
+	minTime := fs.Duration("mintime", 200*time.Millisecond, "minimum duration of each repetition")
+	outPath := fs.String("out", "", "results file, CSV if it ends in .csv and JSON otherwise")
+	if err := parseFlags(fs, args, "out"); err != nil {
+		return err
+	}
+
+	if *reps < 1 || *warmup < 0 {
+		return errorutils.New("run: -reps must be positive and -warmup not negative")
+	}
+
+	cases, err := grid(*backendList, *modulusList, *chunksList, *blockList, *sizeList)
+	if err != nil {
+		return err
+	}
+
+	rf := &resultsFile{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Warmup:    *warmup,
+		Reps:      *reps,
+		MinTime:   minTime.String(),
+	}
+
+	for _, c := range cases {
+		r, err := benchmark(c, *warmup, *reps, *minTime)
+		if err != nil {
+			return errorutils.NewfWithInner(err, "%s", c.label())
+		}
+
+		rf.Results = append(rf.Results, r)
+		ns := r.nsPerOp()
+		fmt.Fprintf(out, "%-32s %12s ± %4.1f%%  %10.0f allocs/op  %8.2f MB/s\n",
+			c.label(), formatNs(mean(ns)), 100*stddev(ns)/mean(ns), mean(r.field(func(s sample) float64 { return s.AllocsPerOp })), throughput(c.DataSize, mean(ns)))
+	}
+
+	return writeResults(*outPath, rf)
+}
+
+func benchmark(c benchCase, warmup int, reps int, minTime time.Duration) (result, error) {
+	r := result{benchCase: c}
+
+	data, err := random.GenerateBytes(c.DataSize)
+	if err != nil {
+		return r, err
+	}
+
+	key, err := random.GenerateBytes(keySize)
+	if err != nil {
+		return r, err
+	}
+
+	op, err := benchBackends[c.Backend].prepare(c, data, key)
+	if err != nil {
+		return r, err
+	}
+
+	for i := 0; i < warmup; i++ {
+		if err := op(); err != nil {
+			return r, err
+		}
+	}
+
+	for i := 0; i < reps; i++ {
+		s, err := measure(op, minTime)
+		if err != nil {
+			return r, err
+		}
+
+		r.Samples = append(r.Samples, s)
+	}
+
+	return r, nil
+}
+
+// grid returns every combination of the lists, once for the parameters each backend uses
+func grid(backendList string, modulusList string, chunksList string, blockList string, sizeList string) ([]benchCase, error) {
+	moduli, err := parseList(modulusList, "modulus")
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := parseList(chunksList, "chunks")
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := parseList(blockList, "block")
+	if err != nil {
+		return nil, err
+	}
+
+	sizes, err := parseList(sizeList, "size")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[benchCase]bool)
+	r := []benchCase{}
+
+	for _, name := range strings.Split(backendList, ",") {
+		b, ok := benchBackends[name]
+		if !ok {
+			return nil, errorutils.Newf("unknown backend %q", name)
+		}
+
+		for _, m := range moduli {
+			for _, c := range chunks {
+				for _, bs := range blocks {
+					for _, s := range sizes {
+						bc := benchCase{Backend: name, ModulusBits: uint(m), DataSize: int(s)}
+						if b.usesChunks {
+							bc.ChunkCount = uint(c)
+						}
+						if b.usesBlock {
+							bc.BlockSize = int(bs)
+						}
+
+						if !seen[bc] {
+							seen[bc] = true
+							r = append(r, bc)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return r, nil
+}
+
+func parseList(list string, name string) ([]uint64, error) {
+	r := []uint64{}
+	for _, s := range strings.Split(list, ",") {
+		v, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+		if err != nil {
+			return nil, errorutils.Newf("-%s: %q is not a number", name, s)
+		}
+
+		r = append(r, v)
+	}
+
+	return r, nil
+}
+
+func backendNames() []string {
+	names := make([]string, 0, len(benchBackends))
+	for name := range benchBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"math"
+)
+
+func mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+
+	return sum / float64(len(xs))
+}
+
+// variance is the unbiased sample variance
+func variance(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+
+	m := mean(xs)
+	sum := 0.0
+	for _, x := range xs {
+		sum += (x - m) * (x - m)
+	}
+
+	return sum / float64(len(xs)-1)
+}
+
+func stddev(xs []float64) float64 {
+	return math.Sqrt(variance(xs))
+}
+
+// welchTTest returns the two-tailed p-value of Welch's t-test for the means of
+// a and b. Without two samples in each, nothing is significant
+func welchTTest(a []float64, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 1
+	}
+
+	va, vb := variance(a)/float64(len(a)), variance(b)/float64(len(b))
+	if va+vb == 0 {
+		if mean(a) == mean(b) {
+			return 1
+		}
+		return 0
+	}
+
+	t := (mean(a) - mean(b)) / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) / (va*va/float64(len(a)-1) + vb*vb/float64(len(b)-1))
+
+	return incompleteBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// incompleteBeta is the regularized incomplete beta function I_x(a, b),
+// evaluated by its continued fraction as in Numerical Recipes
+func incompleteBeta(a float64, b float64, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+
+	// the continued fraction converges quickly for x < (a+1)/(a+b+2) only
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+
+	return 1 - front*betaFraction(b, a, 1-x)/b
+}
+
+func betaFraction(a float64, b float64, x float64) float64 {
+	const (
+		iterations = 200
+		epsilon    = 1e-14
+		tiny       = 1e-300
+	)
+
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	r := d
+
+	for m := 1; m <= iterations; m++ {
+		fm := float64(m)
+		for _, num := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			r *= d * c
+		}
+
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,64 @@
+// Command pdpr-vectors writes the known-answer test vectors of package
+// github.com/titosilva/pdpr-go/testvectors, or checks the embedded ones.
+//
+// Usage:
+//
+//	pdpr-vectors [-out testvectors/vectors]
+//	pdpr-vectors -check
+//
+// The vectors only change when an output of the implementation changes, so a
+// diff in the written files after a refactor is a compatibility break.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/titosilva/pdpr-go/testvectors"
+)
+
+func main() {
+	out := flag.String("out", "testvectors/vectors", "directory to write the vectors to")
+	check := flag.Bool("check", false, "compare the embedded vectors with the current implementation")
+	flag.Parse()
+
+	if *check {
+		if err := checkEmbedded(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := testvectors.Write(*out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func checkEmbedded() error {
+	for _, name := range testvectors.Names {
+		generated, err := testvectors.Generate(name)
+		if err != nil {
+			return err
+		}
+
+		embedded, err := testvectors.Embedded(name)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(generated, embedded) {
+			return fmt.Errorf("%s: the embedded vectors differ from the implementation", name)
+		}
+
+		fmt.Printf("%s: ok\n", name)
+	}
+
+	return nil
+}
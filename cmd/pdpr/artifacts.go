@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/keys"
+)
+
+// Every artifact written by pdpr is a JSON document starting with a header,
+// which records the backend and its parameters. Byte fields are base64 encoded.
+// Keys are not artifacts, but key files of package pdpr/keys
+
+const (
+	kindKey        = "key"
+	kindCiphertext = "ciphertext"
+	kindTag        = "tag"
+	kindState      = "state"
+	kindChallenge  = "challenge"
+	kindProof      = "proof"
+)
+
+type header struct {
+	Kind        string `json:"kind"`
+	Backend     string `json:"backend"`
+	ModulusBits uint   `json:"modulus_bits,omitempty"`
+	ChunkCount  uint   `json:"chunk_count,omitempty"`
+	// BitsPerBlock is the GCrypt encoding, which ciphertexts must be decrypted with
+	BitsPerBlock uint `json:"bits_per_block,omitempty"`
+}
+
+// ownerKey is the secret key of the data owner, with the header it stands for
+type ownerKey struct {
+	header
+	*keys.Key
+}
+
+// ciphertextFile is the encrypted file, stored by the server. KeyID names the
+// key that decrypts it in a keyring
+type ciphertextFile struct {
+	header
+	KeyID  string `json:"key_id,omitempty"`
+	Length int    `json:"length"`
+	Data   []byte `json:"data"`
+}
+
+// tagFile is kept by the owner to verify proofs. Used counts the tokens
+// already issued by the challenge command, which rewrites the tag
+type tagFile struct {
+	header
+	KeyID       string      `json:"key_id,omitempty"`
+	Length      int         `json:"length"`
+	KeyNonce    []byte      `json:"key_nonce,omitempty"`
+	SampleCount int         `json:"sample_count"`
+	Tokens      []tokenFile `json:"tokens"`
+	Used        int         `json:"used"`
+}
+
+// tokenFile is a challenge computed in advance, with what its proof must yield
+type tokenFile struct {
+	Nonce    []byte `json:"nonce"`
+	Expected []byte `json:"expected"`
+}
+
+// stateFile is given to the server along with the ciphertext, to compute proofs
+type stateFile struct {
+	header
+	NonceState []byte `json:"nonce_state,omitempty"`
+}
+
+// challengeFile is sent by the owner to the server, which proves possession
+// of the blocks sampled by the challenge before it expires
+type challengeFile struct {
+	header
+	freshness.Challenge
+}
+
+// proofFile is the answer of the server, bound to the challenge by its hash
+type proofFile struct {
+	header
+	freshness.Response
+}
+
+func writeArtifact(path string, artifact any) error {
+	bs, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(bs, '\n'), 0600)
+}
+
+// readArtifact reads the artifact at path into artifact, checking its kind
+func readArtifact(path string, kind string, artifact any) error {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var h header
+	if err := json.Unmarshal(bs, &h); err != nil {
+		return errorutils.NewfWithInner(err, "%s is not a pdpr artifact", path)
+	}
+
+	if h.Kind != kind {
+		return errorutils.Newf("%s is a %s, expected a %s", path, h.Kind, kind)
+	}
+
+	return json.Unmarshal(bs, artifact)
+}
+
+func sameParams(h1 header, h2 header) error {
+	if h1.Backend != h2.Backend || h1.ModulusBits != h2.ModulusBits || h1.ChunkCount != h2.ChunkCount || h1.BitsPerBlock != h2.BitsPerBlock {
+		return errorutils.Newf("%s and %s were created with different parameters", h1.Kind, h2.Kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/keys"
+)
+
+// backend implements the PDPr protocol over a given primitive.
+// The header holds the parameters of the backend
+type backend interface {
+	defaultParams() header
+	validate(params header) error
+	encrypt(params header, key []byte, data []byte) ([]byte, error)
+	decrypt(params header, key []byte, ciphertext []byte, length int) ([]byte, error)
+	// tag precomputes tokenCount challenges of sampleCount blocks
+	tag(params header, key []byte, ciphertext []byte, length int, tokenCount int, sampleCount int) (*tagFile, *stateFile, error)
+	// blockCount is the number of blocks challenges sample from
+	blockCount(params header, length int) uint64
+	prove(params header, ciphertext []byte, state *stateFile, challenge *pdpr.Challenge) ([]byte, error)
+	verify(params header, key []byte, tag *tagFile, challenge *pdpr.Challenge, proof []byte) bool
+}
+
+var backends = map[string]backend{
+	"ghash": ghashBackend{},
+	"dlhh":  dlhhBackend{},
+}
+
+// algorithms are the pdpr/keys algorithms of the keys of each backend
+var algorithms = map[string]keys.Algorithm{
+	"ghash": keys.AlgorithmPDPr,
+	"dlhh":  keys.AlgorithmDLHH,
+}
+
+func backendFor(params header) (backend, error) {
+	b, ok := backends[params.Backend]
+	if !ok {
+		return nil, errorutils.Newf("unknown backend %q", params.Backend)
+	}
+
+	return b, b.validate(params)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"slices"
+
+	"github.com/titosilva/pdpr-go/crypto/homomorphic_hiding/dlhh"
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/dl"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"golang.org/x/crypto/hkdf"
+)
+
+// dlhhBackend encrypts by adding a key modulo the order of the Oakley group 2,
+// as in dlhh_bench_test.go, and proves by hiding the sum of the challenged
+// ciphertext chunks, each weighted by a scalar derived from the challenge nonce.
+// Each token of the owner holds the hiding of that sum over the plaintext chunks
+type dlhhBackend struct{}
+
+// Oakley group 2 has a 1024 bits modulus, so chunks of 127 bytes are always smaller than its order
+const (
+	dlhhElementSize = 128
+	dlhhChunkSize   = dlhhElementSize - 1
+	dlhhNonceSize   = 32
+)
+
+func (dlhhBackend) defaultParams() header {
+	return header{Backend: "dlhh"}
+}
+
+func (dlhhBackend) validate(params header) error {
+	if params.ModulusBits != 0 || params.ChunkCount != 0 || params.BitsPerBlock != 0 {
+		return errorutils.New("the dlhh backend takes no parameters")
+	}
+
+	return nil
+}
+
+func (dlhhBackend) hider() *dlhh.DLHider {
+	return dlhh.New(dl.NewOakley2Group())
+}
+
+func (d dlhhBackend) encrypt(params header, key []byte, data []byte) ([]byte, error) {
+	dlh := d.hider()
+	r := make([]byte, 0)
+
+	for i := 0; i*dlhhChunkSize < len(data) || i == 0; i++ {
+		chunk := data[i*dlhhChunkSize : min((i+1)*dlhhChunkSize, len(data))]
+		r = append(r, pad(dlh.CombinePlain(chunk, chunkKey(key, i)))...)
+	}
+
+	return r, nil
+}
+
+func (d dlhhBackend) decrypt(params header, key []byte, ciphertext []byte, length int) ([]byte, error) {
+	if len(ciphertext)%dlhhElementSize != 0 || len(ciphertext)/dlhhElementSize < chunkCount(length) {
+		return nil, errorutils.New("ciphertext has the wrong size")
+	}
+
+	dlh := d.hider()
+	r := make([]byte, 0, length)
+
+	for i := 0; i < chunkCount(length); i++ {
+		plain := pad(dlh.SubtractPlain(ciphertext[i*dlhhElementSize:(i+1)*dlhhElementSize], chunkKey(key, i)))
+		chunkLength := min(dlhhChunkSize, length-i*dlhhChunkSize)
+		r = append(r, plain[len(plain)-chunkLength:]...)
+	}
+
+	return r, nil
+}
+
+func (d dlhhBackend) tag(params header, key []byte, ciphertext []byte, length int, tokenCount int, sampleCount int) (*tagFile, *stateFile, error) {
+	if tokenCount <= 0 || sampleCount <= 0 {
+		return nil, nil, errorutils.Newf("cannot precompute %d tokens of %d chunks", tokenCount, sampleCount)
+	}
+
+	data, err := d.decrypt(params, key, ciphertext, length)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params.Kind = kindTag
+	tag := &tagFile{header: params, Length: length, SampleCount: sampleCount}
+
+	dlh := d.hider()
+	for range tokenCount {
+		nonce, err := random.GenerateBytes(dlhhNonceSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sum := weightedSum(dlh, nonce, pdpr.SampleIndices(nonce, uint64(chunkCount(length)), sampleCount), func(i uint64) []byte {
+			return data[i*dlhhChunkSize : min((i+1)*dlhhChunkSize, uint64(length))]
+		})
+		tag.Tokens = append(tag.Tokens, tokenFile{Nonce: nonce, Expected: dlh.Hide(sum)})
+	}
+
+	params.Kind = kindState
+	return tag, &stateFile{header: params}, nil
+}
+
+func (dlhhBackend) blockCount(params header, length int) uint64 {
+	return uint64(chunkCount(length))
+}
+
+func (d dlhhBackend) prove(params header, ciphertext []byte, state *stateFile, challenge *pdpr.Challenge) ([]byte, error) {
+	if len(ciphertext)%dlhhElementSize != 0 {
+		return nil, errorutils.New("ciphertext has the wrong size")
+	}
+
+	for _, i := range challenge.Indices {
+		if i >= uint64(len(ciphertext)/dlhhElementSize) {
+			return nil, errorutils.Newf("challenged chunk %d of %d", i, len(ciphertext)/dlhhElementSize)
+		}
+	}
+
+	dlh := d.hider()
+	sum := weightedSum(dlh, challenge.Nonce, challenge.Indices, func(i uint64) []byte {
+		return ciphertext[i*dlhhElementSize : (i+1)*dlhhElementSize]
+	})
+
+	return dlh.Hide(sum), nil
+}
+
+func (d dlhhBackend) verify(params header, key []byte, tag *tagFile, challenge *pdpr.Challenge, proof []byte) bool {
+	var token *tokenFile
+	for i := range tag.Tokens {
+		if bytes.Equal(tag.Tokens[i].Nonce, challenge.Nonce) {
+			token = &tag.Tokens[i]
+		}
+	}
+
+	if token == nil || !slices.Equal(challenge.Indices, pdpr.SampleIndices(token.Nonce, uint64(chunkCount(tag.Length)), tag.SampleCount)) {
+		return false
+	}
+
+	dlh := d.hider()
+	keySum := weightedSum(dlh, challenge.Nonce, challenge.Indices, func(i uint64) []byte {
+		return chunkKey(key, int(i))
+	})
+
+	return dlh.VerifyHidden(proof, dlh.CombineHidden(token.Expected, dlh.Hide(keySum)))
+}
+
+// weightedSum is the sum of chunk(i) for the given indices, each multiplied by
+// a weight derived from the nonce, so that the sums of different challenges of
+// the same chunks differ. It is reduced modulo the order of the generator, as
+// CombinePlain would wrap around a modulus that is not a multiple of it
+func weightedSum(dlh *dlhh.DLHider, nonce []byte, indices []uint64, chunk func(uint64) []byte) []byte {
+	order := new(big.Int).SetBytes(dl.NewOakley2Group().OrderBytes)
+	sum := new(big.Int)
+	for _, i := range indices {
+		weight := sha256.Sum256(binary.BigEndian.AppendUint64(append([]byte("pdpr-go/cmd/pdpr/dlhh-weight"), nonce...), i))
+		sum.Add(sum, new(big.Int).SetBytes(dlh.ScalePlain(chunk(i), weight[:])))
+		sum.Mod(sum, order)
+	}
+
+	return sum.Bytes()
+}
+
+func chunkCount(length int) int {
+	return max(1, (length+dlhhChunkSize-1)/dlhhChunkSize)
+}
+
+// chunkKey derives an independent key for each chunk, as adding the same key
+// to every chunk would reveal their differences
+func chunkKey(key []byte, index int) []byte {
+	info := make([]byte, 8)
+	binary.BigEndian.PutUint64(info, uint64(index))
+
+	r := make([]byte, dlhhChunkSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, []byte("pdpr-go/cmd/pdpr/dlhh-chunk-key"), info), r); err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// pad left-pads a big-endian group element to its full size
+func pad(element []byte) []byte {
+	if len(element) >= dlhhElementSize {
+		return element[len(element)-dlhhElementSize:]
+	}
+
+	return append(make([]byte, dlhhElementSize-len(element)), element...)
+}
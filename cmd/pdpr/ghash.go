@@ -0,0 +1,86 @@
+package main
+
+import (
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+)
+
+// ghashBackend encrypts with GCrypt and proves with GHash, as implemented by package pdpr
+type ghashBackend struct{}
+
+func (ghashBackend) defaultParams() header {
+	return header{Backend: "ghash", ModulusBits: pdpr.DefaultParams.ModulusBits, ChunkCount: pdpr.DefaultParams.ChunkCount}
+}
+
+// validate rejects parameters below 128 bits of estimated security, as keygen
+// chooses them and artifacts may come from elsewhere
+func (ghashBackend) validate(params header) error {
+	return pdprParams(params).Validate()
+}
+
+// levels are the parameter presets selected by keygen -level
+var levels = map[int]pdpr.Params{
+	128: pdpr.Level128,
+	192: pdpr.Level192,
+	256: pdpr.Level256,
+}
+
+func levelParams(level int) (header, error) {
+	params, ok := levels[level]
+	if !ok {
+		return header{}, errorutils.Newf("unknown security level %d, use 128, 192 or 256", level)
+	}
+
+	return header{Backend: "ghash", ModulusBits: params.ModulusBits, ChunkCount: params.ChunkCount}, nil
+}
+
+func pdprParams(params header) pdpr.Params {
+	return pdpr.Params{ModulusBits: params.ModulusBits, ChunkCount: params.ChunkCount, BitsPerBlock: params.BitsPerBlock}
+}
+
+func (ghashBackend) encrypt(params header, key []byte, data []byte) ([]byte, error) {
+	return pdpr.Encrypt(pdprParams(params), key, data)
+}
+
+func (ghashBackend) decrypt(params header, key []byte, ciphertext []byte, length int) ([]byte, error) {
+	return pdpr.Decrypt(pdprParams(params), key, ciphertext, length)
+}
+
+func (ghashBackend) tag(params header, key []byte, ciphertext []byte, length int, tokenCount int, sampleCount int) (*tagFile, *stateFile, error) {
+	tag, state, err := pdpr.NewTagWithTokens(pdprParams(params), key, ciphertext, length, tokenCount, sampleCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params.Kind = kindTag
+	tf := &tagFile{header: params, Length: tag.Length, KeyNonce: tag.KeyNonce, SampleCount: tag.SampleCount}
+	for _, token := range tag.Tokens {
+		tf.Tokens = append(tf.Tokens, tokenFile{Nonce: token.Nonce, Expected: token.Expected})
+	}
+
+	params.Kind = kindState
+	return tf, &stateFile{header: params, NonceState: state.NonceState}, nil
+}
+
+func (ghashBackend) blockCount(params header, length int) uint64 {
+	crypt, err := gcrypt.NewWithEncoding(uint64(params.ModulusBits), pdprParams(params).Encoding())
+	if err != nil {
+		return 0
+	}
+
+	return uint64(crypt.BlockCount(length))
+}
+
+func (ghashBackend) prove(params header, ciphertext []byte, state *stateFile, challenge *pdpr.Challenge) ([]byte, error) {
+	return pdpr.Prove(pdprParams(params), ciphertext, &pdpr.State{NonceState: state.NonceState}, challenge)
+}
+
+func (ghashBackend) verify(params header, key []byte, tag *tagFile, challenge *pdpr.Challenge, proof []byte) bool {
+	t := &pdpr.Tag{Length: tag.Length, KeyNonce: tag.KeyNonce, SampleCount: tag.SampleCount}
+	for _, token := range tag.Tokens {
+		t.Tokens = append(t.Tokens, pdpr.Token{Nonce: token.Nonce, Expected: token.Expected})
+	}
+
+	return pdpr.Verify(pdprParams(params), key, t, challenge, proof)
+}
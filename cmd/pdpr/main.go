@@ -0,0 +1,581 @@
+// Command pdpr encrypts files, tags them, and computes and verifies proofs of
+// data possession over them, writing every artifact to disk.
+//
+// Usage:
+//
+//	pdpr keygen  [-backend ghash|dlhh] [-level 128|192|256] [-modulus bits] [-chunks count] [-bits count] [-format json|pem] -out key.json
+//	pdpr encrypt -key key.json -in file -out file.ct
+//	pdpr tag     -key key.json -in file.ct -out file.tag -state file.state [-tokens count] [-samples count]
+//	pdpr challenge -tag file.tag -out file.challenge [-id name] [-ttl duration]
+//	pdpr prove   -in file.ct -state file.state -challenge file.challenge -out file.proof
+//	pdpr verify  -key key.json|keyring -tag file.tag -challenge file.challenge -proof file.proof
+//	pdpr decrypt -key key.json|keyring -in file.ct -out file
+//	pdpr inspect artifact...
+//
+// The data owner runs keygen, encrypt and tag, and gives the ciphertext and
+// the state to the server. The owner keeps the key and the tag, which holds
+// -tokens challenges of -samples blocks computed in advance. To audit the
+// server, the owner issues the next one with challenge, which marks it as used
+// in the tag, the server answers it with prove before it expires, and the owner
+// runs verify on the proof. A proof only verifies for the challenge it answers,
+// whose hash it carries (see package pdpr/freshness).
+//
+// keygen -bits packs as many plaintext bits in each GCrypt block, which
+// shrinks ciphertexts by as much; like the other parameters, it is recorded in
+// the header of every artifact.
+//
+// Keys are key files of package pdpr/keys. If $PDPR_PASSPHRASE is set, keygen
+// wraps the key under it, and the other commands unwrap keys with it. verify
+// and decrypt also take a keyring, a directory of key files, in which they
+// look up the key that tagged or encrypted the file by its ID.
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/keys"
+)
+
+type command struct {
+	summary string
+	run     func(args []string, out io.Writer) error
+}
+
+var commands = map[string]command{
+	"keygen":    {"generate a key and choose the backend and its parameters", runKeygen},
+	"encrypt":   {"encrypt a file", runEncrypt},
+	"tag":       {"compute the owner's tag and the server's state for a ciphertext", runTag},
+	"challenge": {"issue the next challenge of a tag", runChallenge},
+	"prove":     {"answer a challenge with a proof of possession of a ciphertext", runProve},
+	"verify":    {"verify the proof of a challenge against a tag", runVerify},
+	"decrypt":   {"decrypt a ciphertext", runDecrypt},
+	"inspect":   {"describe artifacts", runInspect},
+}
+
+var errVerificationFailed = errorutils.New("proof verification FAILED")
+
+const passphraseEnv = "PDPR_PASSPHRASE"
+
+// textFields are the string fields of artifacts that are not base64 bytes
+var textFields = map[string]bool{
+	"kind": true, "backend": true, "file_id": true, "issued_at": true, "expires_at": true,
+	"key_id": true, "id": true, "algorithm": true,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "pdpr:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		usage(out)
+		return errorutils.New("no command given")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(out)
+		return errorutils.Newf("unknown command %q", args[0])
+	}
+
+	return cmd.run(args[1:], out)
+}
+
+func usage(out io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(out, "usage: pdpr <command> [flags]")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-9s %s\n", name, commands[name].summary)
+	}
+}
+
+// parseFlags parses args, and checks that every flag in required was set
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for _, name := range required {
+		if !set[name] {
+			return errorutils.Newf("%s: -%s is required", fs.Name(), name)
+		}
+	}
+
+	return nil
+}
+
+// passphrase is $PDPR_PASSPHRASE, or nil if it is not set
+func passphrase() []byte {
+	if p, ok := os.LookupEnv(passphraseEnv); ok {
+		return []byte(p)
+	}
+
+	return nil
+}
+
+// readKey reads the key file at path. If path is a keyring, it looks the key
+// up by keyID; otherwise, keyID, if any, must be the ID of the key
+func readKey(path string, keyID string) (*ownerKey, backend, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var k *keys.Key
+	if info.IsDir() {
+		if keyID == "" {
+			return nil, nil, errorutils.Newf("%s is a keyring, but no key ID is known", path)
+		}
+
+		keyring, err := keys.LoadKeyring(path, passphrase())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if k, err = keyring.Get(keys.ID(keyID)); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if k, err = keys.ReadFile(path, passphrase()); err != nil {
+			return nil, nil, err
+		}
+
+		if keyID != "" && k.ID != keys.ID(keyID) {
+			return nil, nil, errorutils.Newf("%s is key %s, but the file was processed with key %s", path, k.ID, keyID)
+		}
+	}
+
+	key := &ownerKey{Key: k}
+	for name, algorithm := range algorithms {
+		if algorithm == k.Algorithm {
+			key.header = header{Kind: kindKey, Backend: name, ModulusBits: k.Params.ModulusBits, ChunkCount: k.Params.ChunkCount, BitsPerBlock: k.Params.BitsPerBlock}
+		}
+	}
+
+	b, err := backendFor(key.header)
+	return key, b, err
+}
+
+func readCiphertext(path string, key *ownerKey) (*ciphertextFile, error) {
+	ct := new(ciphertextFile)
+	if err := readArtifact(path, kindCiphertext, ct); err != nil {
+		return nil, err
+	}
+
+	if key != nil {
+		if err := sameParams(key.header, ct.header); err != nil {
+			return nil, err
+		}
+	}
+
+	return ct, nil
+}
+
+func runKeygen(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	backendName := fs.String("backend", "ghash", "backend: ghash or dlhh")
+	modulus := fs.Uint("modulus", 0, "GCrypt and GHash modulus size in bits (ghash only)")
+	bitsPerBlock := fs.Uint("bits", 0, "plaintext bits per GCrypt block, a power of two up to 64 (ghash only)")
+	chunks := fs.Uint("chunks", 0, "number of LtHash chunks (ghash only)")
+	level := fs.Int("level", 0, "security level preset: 128, 192 or 256 (ghash only)")
+	size := fs.Int("size", keys.DefaultSize, "key size in bytes")
+	format := fs.String("format", "json", "key file format: json or pem")
+	outPath := fs.String("out", "", "output key file")
+	if err := parseFlags(fs, args, "out"); err != nil {
+		return err
+	}
+
+	b, ok := backends[*backendName]
+	if !ok {
+		return errorutils.Newf("unknown backend %q", *backendName)
+	}
+
+	params := b.defaultParams()
+	if *level != 0 {
+		if *backendName != "ghash" {
+			return errorutils.New("-level is only supported by the ghash backend")
+		}
+
+		var err error
+		if params, err = levelParams(*level); err != nil {
+			return err
+		}
+	}
+	if *modulus != 0 {
+		params.ModulusBits = *modulus
+	}
+	if *chunks != 0 {
+		params.ChunkCount = *chunks
+	}
+	if *bitsPerBlock != 0 {
+		params.BitsPerBlock = *bitsPerBlock
+	}
+	if err := b.validate(params); err != nil {
+		return err
+	}
+
+	formats := map[string]keys.Format{"json": keys.FormatJSON, "pem": keys.FormatPEM}
+	f, ok := formats[*format]
+	if !ok {
+		return errorutils.Newf("unknown key format %q", *format)
+	}
+
+	material, err := random.GenerateBytes(*size)
+	if err != nil {
+		return err
+	}
+
+	k, err := keys.NewWithAlgorithm(algorithms[*backendName], pdprParams(params), material)
+	if err != nil {
+		return err
+	}
+
+	if p := passphrase(); p != nil {
+		wrapped, err := keys.Wrap(k, p, keys.Argon2id)
+		if err != nil {
+			return err
+		}
+
+		return keys.WriteFile(*outPath, wrapped, f)
+	}
+
+	return keys.WriteFile(*outPath, k, f)
+}
+
+func runEncrypt(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "key file")
+	inPath := fs.String("in", "", "plaintext file")
+	outPath := fs.String("out", "", "output ciphertext file")
+	if err := parseFlags(fs, args, "key", "in", "out"); err != nil {
+		return err
+	}
+
+	key, b, err := readKey(*keyPath, "")
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*inPath)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := b.encrypt(key.header, key.Material, data)
+	if err != nil {
+		return err
+	}
+
+	params := key.header
+	params.Kind = kindCiphertext
+	ct := &ciphertextFile{header: params, KeyID: string(key.ID), Length: len(data), Data: encrypted}
+
+	return writeArtifact(*outPath, ct)
+}
+
+func runTag(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("tag", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "key file")
+	inPath := fs.String("in", "", "ciphertext file")
+	outPath := fs.String("out", "", "output tag file, kept by the owner")
+	statePath := fs.String("state", "", "output state file, given to the server")
+	tokenCount := fs.Int("tokens", pdpr.DefaultTokenCount, "number of challenges computed in advance")
+	sampleCount := fs.Int("samples", pdpr.DefaultSampleCount, "number of blocks sampled by each challenge")
+	if err := parseFlags(fs, args, "key", "in", "out", "state"); err != nil {
+		return err
+	}
+
+	key, b, err := readKey(*keyPath, "")
+	if err != nil {
+		return err
+	}
+
+	ct, err := readCiphertext(*inPath, key)
+	if err != nil {
+		return err
+	}
+
+	tag, state, err := b.tag(key.header, key.Material, ct.Data, ct.Length, *tokenCount, *sampleCount)
+	if err != nil {
+		return err
+	}
+	tag.KeyID = string(key.ID)
+
+	if err := writeArtifact(*outPath, tag); err != nil {
+		return err
+	}
+
+	return writeArtifact(*statePath, state)
+}
+
+func runChallenge(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("challenge", flag.ContinueOnError)
+	tagPath := fs.String("tag", "", "tag file, rewritten to mark the challenge as used")
+	outPath := fs.String("out", "", "output challenge file, sent to the server")
+	fileID := fs.String("id", "", "name of the file on the server")
+	ttl := fs.Duration("ttl", 24*time.Hour, "time the server has to answer the challenge")
+	if err := parseFlags(fs, args, "tag", "out"); err != nil {
+		return err
+	}
+
+	tag := new(tagFile)
+	if err := readArtifact(*tagPath, kindTag, tag); err != nil {
+		return err
+	}
+
+	b, err := backendFor(tag.header)
+	if err != nil {
+		return err
+	}
+
+	if tag.Used >= len(tag.Tokens) {
+		return pdpr.ErrNoTokens
+	}
+
+	blockCount := b.blockCount(tag.header, tag.Length)
+	sampleCount := int(min(uint64(tag.SampleCount), blockCount))
+	challenge, err := freshness.NewChallengeWithNonce(*fileID, tag.Tokens[tag.Used].Nonce, blockCount, sampleCount, 0, time.Now(), *ttl)
+	if err != nil {
+		return err
+	}
+
+	tag.Used++
+	// the tag is saved first, so that a token is never issued twice
+	if err := writeArtifact(*tagPath, tag); err != nil {
+		return err
+	}
+
+	params := tag.header
+	params.Kind = kindChallenge
+	if err := writeArtifact(*outPath, &challengeFile{header: params, Challenge: *challenge}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%d challenges left\n", len(tag.Tokens)-tag.Used)
+	return nil
+}
+
+func runProve(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("prove", flag.ContinueOnError)
+	inPath := fs.String("in", "", "ciphertext file")
+	statePath := fs.String("state", "", "state file")
+	challengePath := fs.String("challenge", "", "challenge file")
+	outPath := fs.String("out", "", "output proof file")
+	if err := parseFlags(fs, args, "in", "state", "challenge", "out"); err != nil {
+		return err
+	}
+
+	ct, err := readCiphertext(*inPath, nil)
+	if err != nil {
+		return err
+	}
+
+	state := new(stateFile)
+	if err := readArtifact(*statePath, kindState, state); err != nil {
+		return err
+	}
+
+	challenge := new(challengeFile)
+	if err := readArtifact(*challengePath, kindChallenge, challenge); err != nil {
+		return err
+	}
+
+	if err := sameParams(ct.header, state.header); err != nil {
+		return err
+	}
+
+	if err := sameParams(ct.header, challenge.header); err != nil {
+		return err
+	}
+
+	b, err := backendFor(ct.header)
+	if err != nil {
+		return err
+	}
+
+	if challenge.Expired(time.Now()) {
+		return freshness.ErrExpired
+	}
+
+	c, err := pdpr.ChallengeOf(&challenge.Challenge)
+	if err != nil {
+		return err
+	}
+
+	proof, err := b.prove(ct.header, ct.Data, state, c)
+	if err != nil {
+		return err
+	}
+
+	params := ct.header
+	params.Kind = kindProof
+	response := freshness.Response{ChallengeHash: challenge.Hash(), Proof: proof}
+	return writeArtifact(*outPath, &proofFile{header: params, Response: response})
+}
+
+func runVerify(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "key file, or keyring holding the key of the tag")
+	tagPath := fs.String("tag", "", "tag file")
+	challengePath := fs.String("challenge", "", "challenge file the proof answers")
+	proofPath := fs.String("proof", "", "proof file")
+	if err := parseFlags(fs, args, "key", "tag", "challenge", "proof"); err != nil {
+		return err
+	}
+
+	tag := new(tagFile)
+	if err := readArtifact(*tagPath, kindTag, tag); err != nil {
+		return err
+	}
+
+	key, b, err := readKey(*keyPath, tag.KeyID)
+	if err != nil {
+		return err
+	}
+
+	challenge := new(challengeFile)
+	if err := readArtifact(*challengePath, kindChallenge, challenge); err != nil {
+		return err
+	}
+
+	proof := new(proofFile)
+	if err := readArtifact(*proofPath, kindProof, proof); err != nil {
+		return err
+	}
+
+	if err := sameParams(key.header, tag.header); err != nil {
+		return err
+	}
+
+	if err := sameParams(key.header, proof.header); err != nil {
+		return err
+	}
+
+	if err := sameParams(key.header, challenge.header); err != nil {
+		return err
+	}
+
+	if challenge.Expired(time.Now()) {
+		return freshness.ErrExpired
+	}
+
+	c, err := pdpr.ChallengeOf(&challenge.Challenge)
+	if err != nil {
+		return err
+	}
+
+	// the proof must answer this challenge, and not an earlier one
+	if !bytes.Equal(proof.ChallengeHash, challenge.Hash()) || !b.verify(key.header, key.Material, tag, c, proof.Proof) {
+		fmt.Fprintln(out, "FAILED")
+		return errVerificationFailed
+	}
+
+	fmt.Fprintln(out, "OK")
+	return nil
+}
+
+func runDecrypt(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("decrypt", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "key file, or keyring holding the key of the ciphertext")
+	inPath := fs.String("in", "", "ciphertext file")
+	outPath := fs.String("out", "", "output plaintext file")
+	if err := parseFlags(fs, args, "key", "in", "out"); err != nil {
+		return err
+	}
+
+	ct, err := readCiphertext(*inPath, nil)
+	if err != nil {
+		return err
+	}
+
+	key, b, err := readKey(*keyPath, ct.KeyID)
+	if err != nil {
+		return err
+	}
+
+	if err := sameParams(key.header, ct.header); err != nil {
+		return err
+	}
+
+	data, err := b.decrypt(key.header, key.Material, ct.Data, ct.Length)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(*outPath, data, 0600)
+}
+
+func runInspect(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errorutils.New("inspect: no artifact given")
+	}
+
+	for _, path := range args {
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal(bs, &fields); err != nil {
+			return errorutils.NewfWithInner(err, "%s is not a pdpr artifact", path)
+		}
+
+		fmt.Fprintf(out, "%s:\n", path)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(out, "  %-12s %s\n", name, describe(name, fields[name]))
+		}
+	}
+
+	return nil
+}
+
+// describe prints scalar fields as they are, the size of byte fields, which
+// must not be printed as they may be secret, and the length of lists
+func describe(name string, value any) string {
+	if list, isList := value.([]any); isList {
+		return fmt.Sprintf("%d entries", len(list))
+	}
+
+	s, isString := value.(string)
+	if !isString || textFields[name] {
+		return fmt.Sprint(value)
+	}
+
+	bs, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "malformed"
+	}
+
+	return fmt.Sprintf("%d bytes", len(bs))
+}
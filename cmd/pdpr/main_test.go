@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/keys"
+)
+
+func runPdpr(ez *ez.EzTest, args ...string) string {
+	out := new(bytes.Buffer)
+	ez.AssertNoError(run(args, out))
+
+	return out.String()
+}
+
+func Test__Pdpr__FullFlow__ShouldVerifyAndDecrypt(t *testing.T) {
+	for _, backend := range []string{"ghash", "dlhh"} {
+		ez := ez.New(t)
+		dir := t.TempDir()
+		path := func(name string) string { return filepath.Join(dir, name) }
+
+		data := []byte(strings.Repeat("Hello, World! ", 20))
+		ez.AssertNoError(os.WriteFile(path("file"), data, 0600))
+
+		keygen := []string{"keygen", "-backend", backend, "-out", path("key")}
+		if backend == "ghash" {
+			keygen = append(keygen, "-modulus", "64", "-chunks", "1673")
+		}
+
+		runPdpr(ez, keygen...)
+		runPdpr(ez, "encrypt", "-key", path("key"), "-in", path("file"), "-out", path("ct"))
+		runPdpr(ez, "tag", "-key", path("key"), "-in", path("ct"), "-out", path("tag"), "-state", path("state"))
+		ez.AssertAreEqual(runPdpr(ez, "challenge", "-tag", path("tag"), "-out", path("challenge")), "15 challenges left\n")
+		runPdpr(ez, "prove", "-in", path("ct"), "-state", path("state"), "-challenge", path("challenge"), "-out", path("proof"))
+		ez.AssertAreEqual(runPdpr(ez, "verify", "-key", path("key"), "-tag", path("tag"), "-challenge", path("challenge"), "-proof", path("proof")), "OK\n")
+
+		// the proof of the first challenge does not answer the next one
+		runPdpr(ez, "challenge", "-tag", path("tag"), "-out", path("next"))
+		err := run([]string{"verify", "-key", path("key"), "-tag", path("tag"), "-challenge", path("next"), "-proof", path("proof")}, new(bytes.Buffer))
+		ez.Assert(err == errVerificationFailed)
+		runPdpr(ez, "decrypt", "-key", path("key"), "-in", path("ct"), "-out", path("decrypted"))
+
+		decrypted, err := os.ReadFile(path("decrypted"))
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(decrypted, data)
+
+		inspected := runPdpr(ez, "inspect", path("key"), path("proof"), path("tag"))
+		ez.Assert(strings.Contains(inspected, backend))
+		ez.Assert(strings.Contains(inspected, "32 bytes"))
+		ez.Assert(strings.Contains(inspected, "16 entries"))
+	}
+}
+
+func Test__Pdpr__FullFlow__ShouldVerifyAndDecrypt__WithCompactEncoding(t *testing.T) {
+	ez := ez.New(t)
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	data := []byte(strings.Repeat("Hello, World! ", 20))
+	ez.AssertNoError(os.WriteFile(path("file"), data, 0600))
+
+	runPdpr(ez, "keygen", "-modulus", "64", "-chunks", "1673", "-bits", "16", "-out", path("key"))
+	runPdpr(ez, "encrypt", "-key", path("key"), "-in", path("file"), "-out", path("ct"))
+	runPdpr(ez, "tag", "-key", path("key"), "-in", path("ct"), "-out", path("tag"), "-state", path("state"))
+	runPdpr(ez, "challenge", "-tag", path("tag"), "-out", path("challenge"))
+	runPdpr(ez, "prove", "-in", path("ct"), "-state", path("state"), "-challenge", path("challenge"), "-out", path("proof"))
+	ez.AssertAreEqual(runPdpr(ez, "verify", "-key", path("key"), "-tag", path("tag"), "-challenge", path("challenge"), "-proof", path("proof")), "OK\n")
+	runPdpr(ez, "decrypt", "-key", path("key"), "-in", path("ct"), "-out", path("decrypted"))
+
+	ct, err := readCiphertext(path("ct"), nil)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(ct.BitsPerBlock, uint(16))
+	// a block of 8 bytes per 16 bits of plaintext
+	ez.AssertAreEqual(len(ct.Data), len(data)/2*8)
+
+	decrypted, err := os.ReadFile(path("decrypted"))
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(decrypted, data)
+
+	ez.Assert(run([]string{"keygen", "-bits", "3", "-out", path("bad")}, new(bytes.Buffer)) != nil)
+	ez.Assert(run([]string{"keygen", "-backend", "dlhh", "-bits", "16", "-out", path("bad")}, new(bytes.Buffer)) != nil)
+}
+
+func Test__Pdpr__Verify__ShouldFail__WhenCiphertextIsCorrupted(t *testing.T) {
+	for _, backend := range []string{"ghash", "dlhh"} {
+		ez := ez.New(t)
+		dir := t.TempDir()
+		path := func(name string) string { return filepath.Join(dir, name) }
+		ez.AssertNoError(os.WriteFile(path("file"), []byte("Hello, World!"), 0600))
+
+		runPdpr(ez, "keygen", "-backend", backend, "-out", path("key"))
+		runPdpr(ez, "encrypt", "-key", path("key"), "-in", path("file"), "-out", path("ct"))
+		runPdpr(ez, "tag", "-key", path("key"), "-in", path("ct"), "-out", path("tag"), "-state", path("state"))
+
+		ct, err := readCiphertext(path("ct"), nil)
+		ez.AssertNoError(err)
+		ct.Data[len(ct.Data)-1] ^= 1
+		ez.AssertNoError(writeArtifact(path("ct"), ct))
+
+		runPdpr(ez, "challenge", "-tag", path("tag"), "-out", path("challenge"))
+		runPdpr(ez, "prove", "-in", path("ct"), "-state", path("state"), "-challenge", path("challenge"), "-out", path("proof"))
+		err = run([]string{"verify", "-key", path("key"), "-tag", path("tag"), "-challenge", path("challenge"), "-proof", path("proof")}, new(bytes.Buffer))
+		ez.Assert(err == errVerificationFailed)
+	}
+}
+
+func Test__Pdpr__Challenge__ShouldFail__WhenEveryTokenWasIssued(t *testing.T) {
+	ez := ez.New(t)
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	ez.AssertNoError(os.WriteFile(path("file"), []byte("Hello, World!"), 0600))
+
+	runPdpr(ez, "keygen", "-modulus", "64", "-chunks", "1673", "-out", path("key"))
+	runPdpr(ez, "encrypt", "-key", path("key"), "-in", path("file"), "-out", path("ct"))
+	runPdpr(ez, "tag", "-key", path("key"), "-in", path("ct"), "-out", path("tag"), "-state", path("state"), "-tokens", "1", "-samples", "8")
+
+	runPdpr(ez, "challenge", "-tag", path("tag"), "-out", path("challenge"))
+	challenge := new(challengeFile)
+	ez.AssertNoError(readArtifact(path("challenge"), kindChallenge, challenge))
+	ez.AssertAreEqual(challenge.SampleCount, 8)
+
+	err := run([]string{"challenge", "-tag", path("tag"), "-out", path("again")}, new(bytes.Buffer))
+	ez.AssertErrorIs(err, pdpr.ErrNoTokens)
+}
+
+func Test__Pdpr__Prove__ShouldFail__WhenChallengeExpired(t *testing.T) {
+	ez := ez.New(t)
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	ez.AssertNoError(os.WriteFile(path("file"), []byte("Hello, World!"), 0600))
+
+	runPdpr(ez, "keygen", "-modulus", "64", "-chunks", "1673", "-out", path("key"))
+	runPdpr(ez, "encrypt", "-key", path("key"), "-in", path("file"), "-out", path("ct"))
+	runPdpr(ez, "tag", "-key", path("key"), "-in", path("ct"), "-out", path("tag"), "-state", path("state"))
+	runPdpr(ez, "challenge", "-tag", path("tag"), "-out", path("challenge"), "-id", "file", "-ttl", "1ns")
+
+	err := run([]string{"prove", "-in", path("ct"), "-state", path("state"), "-challenge", path("challenge"), "-out", path("proof")}, new(bytes.Buffer))
+	ez.AssertErrorIs(err, freshness.ErrExpired)
+
+	inspected := runPdpr(ez, "inspect", path("challenge"))
+	ez.Assert(strings.Contains(inspected, "file_id      file"))
+	ez.AssertFalse(strings.Contains(inspected, "malformed"))
+}
+
+func Test__Pdpr__ShouldFail__WhenArgumentsAreWrong(t *testing.T) {
+	ez := ez.New(t)
+	dir := t.TempDir()
+
+	ez.Assert(run([]string{}, new(bytes.Buffer)) != nil)
+	ez.Assert(run([]string{"unknown"}, new(bytes.Buffer)) != nil)
+	ez.Assert(run([]string{"encrypt", "-in", "file"}, new(bytes.Buffer)) != nil)
+	ez.Assert(run([]string{"keygen", "-backend", "ghash", "-modulus", "100", "-out", filepath.Join(dir, "key")}, new(bytes.Buffer)) != nil)
+
+	runPdpr(ez, "keygen", "-out", filepath.Join(dir, "key"))
+	err := run([]string{"prove", "-in", filepath.Join(dir, "key"), "-state", filepath.Join(dir, "key"), "-challenge", filepath.Join(dir, "key"), "-out", filepath.Join(dir, "proof")}, new(bytes.Buffer))
+	ez.Assert(err != nil)
+	ez.Assert(run([]string{"prove", "-in", filepath.Join(dir, "key"), "-state", filepath.Join(dir, "key"), "-out", filepath.Join(dir, "proof")}, new(bytes.Buffer)) != nil)
+}
+
+func Test__Pdpr__Keygen__ShouldUseLevelPresets__AndRejectWeakParams(t *testing.T) {
+	ez := ez.New(t)
+	dir := t.TempDir()
+
+	ez.AssertAreEqual(runPdpr(ez, "keygen", "-level", "192", "-out", filepath.Join(dir, "key")), "")
+	key, _, err := readKey(filepath.Join(dir, "key"), "")
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(key.ModulusBits, uint(192))
+	ez.AssertAreEqual(key.ChunkCount, uint(768))
+
+	err = run([]string{"keygen", "-modulus", "64", "-chunks", "8", "-out", filepath.Join(dir, "weak")}, new(bytes.Buffer))
+	ez.AssertErrorIs(err, lthash.ErrInsecureParams)
+	ez.Assert(run([]string{"keygen", "-level", "100", "-out", filepath.Join(dir, "key")}, new(bytes.Buffer)) != nil)
+}
+
+func Test__Pdpr__Verify__ShouldLookUpWrappedKeys__InKeyring(t *testing.T) {
+	ez := ez.New(t)
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	ez.AssertNoError(os.WriteFile(path("file"), []byte("Hello, World!"), 0600))
+	ez.AssertNoError(os.Mkdir(path("keyring"), 0700))
+	t.Setenv(passphraseEnv, "correct horse battery staple")
+
+	runPdpr(ez, "keygen", "-modulus", "64", "-chunks", "1673", "-format", "pem", "-out", path("keyring/first"))
+	runPdpr(ez, "keygen", "-backend", "dlhh", "-out", path("keyring/second"))
+	runPdpr(ez, "encrypt", "-key", path("keyring/second"), "-in", path("file"), "-out", path("ct"))
+	runPdpr(ez, "tag", "-key", path("keyring/second"), "-in", path("ct"), "-out", path("tag"), "-state", path("state"))
+	runPdpr(ez, "challenge", "-tag", path("tag"), "-out", path("challenge"))
+	runPdpr(ez, "prove", "-in", path("ct"), "-state", path("state"), "-challenge", path("challenge"), "-out", path("proof"))
+
+	ez.AssertAreEqual(runPdpr(ez, "verify", "-key", path("keyring"), "-tag", path("tag"), "-challenge", path("challenge"), "-proof", path("proof")), "OK\n")
+	runPdpr(ez, "decrypt", "-key", path("keyring"), "-in", path("ct"), "-out", path("decrypted"))
+	decrypted, err := os.ReadFile(path("decrypted"))
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(decrypted, []byte("Hello, World!"))
+
+	// the key of the tag is known, and another one does not verify its proofs
+	err = run([]string{"verify", "-key", path("keyring/first"), "-tag", path("tag"), "-challenge", path("challenge"), "-proof", path("proof")}, new(bytes.Buffer))
+	ez.Assert(err != nil)
+
+	t.Setenv(passphraseEnv, "wrong")
+	err = run([]string{"decrypt", "-key", path("keyring"), "-in", path("ct"), "-out", path("decrypted")}, new(bytes.Buffer))
+	ez.AssertErrorIs(err, keys.ErrPassphrase)
+}
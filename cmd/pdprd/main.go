@@ -0,0 +1,86 @@
+// Command pdprd serves the PDPr storage protocol documented in package
+// github.com/titosilva/pdpr-go/pdpr/server over HTTP.
+//
+// Usage:
+//
+//	pdprd [-addr 127.0.0.1:7878] [-store memory|dir|log|s3] [-path path]
+//
+// Files are kept in memory by default. The dir store keeps one file per object
+// under -path, and the log store keeps every object in the append-only log at
+// -path. The s3 store is configured by the environment variables
+// PDPR_S3_ENDPOINT, PDPR_S3_REGION, PDPR_S3_BUCKET, PDPR_S3_ACCESS_KEY and
+// PDPR_S3_SECRET_KEY.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/titosilva/pdpr-go/pdpr/server"
+	"github.com/titosilva/pdpr-go/pdpr/store"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:7878", "address to listen on")
+	kind := flag.String("store", "memory", "storage backend: memory, dir, log or s3")
+	path := flag.String("path", "", "directory of the dir store, or file of the log store")
+	flag.Parse()
+
+	s, err := openStore(*kind, *path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(s),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("pdprd listening on %s", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+func openStore(kind string, path string) (store.Store, error) {
+	if (kind == "dir" || kind == "log") && path == "" {
+		return nil, errors.New("the " + kind + " store requires -path")
+	}
+
+	switch kind {
+	case "memory":
+		return store.NewMemory(), nil
+	case "dir":
+		return store.NewDir(path)
+	case "log":
+		return store.OpenLog(path)
+	case "s3":
+		return store.NewS3(store.S3Config{
+			Endpoint:  os.Getenv("PDPR_S3_ENDPOINT"),
+			Region:    os.Getenv("PDPR_S3_REGION"),
+			Bucket:    os.Getenv("PDPR_S3_BUCKET"),
+			AccessKey: os.Getenv("PDPR_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("PDPR_S3_SECRET_KEY"),
+		})
+	default:
+		return nil, errors.New("unknown store " + kind)
+	}
+}
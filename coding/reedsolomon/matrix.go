@@ -0,0 +1,118 @@
+package reedsolomon
+
+import (
+	"errors"
+
+	"github.com/titosilva/pdpr-go/math/gf"
+)
+
+type matrix [][]uint16
+
+var errSingularMatrix = errors.New("matrix is singular")
+
+func newMatrix(rows, cols int) matrix {
+	r := make(matrix, rows)
+
+	for i := range r {
+		r[i] = make([]uint16, cols)
+	}
+
+	return r
+}
+
+func identity(size int) matrix {
+	r := newMatrix(size, size)
+
+	for i := range r {
+		r[i][i] = 1
+	}
+
+	return r
+}
+
+// vandermonde builds the matrix whose element (r, c) is r^c.
+// Any square submatrix made of distinct rows is invertible
+func vandermonde(f *gf.Field, rows, cols int) matrix {
+	r := newMatrix(rows, cols)
+
+	for i := range r {
+		for j := range r[i] {
+			r[i][j] = f.Exp(uint16(i), j)
+		}
+	}
+
+	return r
+}
+
+func (m matrix) mul(f *gf.Field, o matrix) matrix {
+	r := newMatrix(len(m), len(o[0]))
+
+	for i := range r {
+		for j := range r[i] {
+			var v uint16
+			for k := range o {
+				v ^= f.Mul(m[i][k], o[k][j])
+			}
+
+			r[i][j] = v
+		}
+	}
+
+	return r
+}
+
+func (m matrix) subRows(rows []int) matrix {
+	r := make(matrix, len(rows))
+
+	for i, row := range rows {
+		r[i] = append([]uint16{}, m[row]...)
+	}
+
+	return r
+}
+
+// invert uses Gauss-Jordan elimination over the field
+func (m matrix) invert(f *gf.Field) (matrix, error) {
+	size := len(m)
+	id := identity(size)
+	work := make(matrix, size)
+	for i := range m {
+		work[i] = append(append([]uint16{}, m[i]...), id[i]...)
+	}
+
+	for col := 0; col < size; col++ {
+		pivot := col
+		for pivot < size && work[pivot][col] == 0 {
+			pivot++
+		}
+
+		if pivot == size {
+			return nil, errSingularMatrix
+		}
+
+		work[col], work[pivot] = work[pivot], work[col]
+
+		inv := f.Inv(work[col][col])
+		for j := range work[col] {
+			work[col][j] = f.Mul(work[col][j], inv)
+		}
+
+		for i := 0; i < size; i++ {
+			if i == col || work[i][col] == 0 {
+				continue
+			}
+
+			factor := work[i][col]
+			for j := range work[i] {
+				work[i][j] ^= f.Mul(factor, work[col][j])
+			}
+		}
+	}
+
+	r := newMatrix(size, size)
+	for i := range r {
+		copy(r[i], work[i][size:])
+	}
+
+	return r, nil
+}
@@ -0,0 +1,239 @@
+package reedsolomon
+
+import (
+	"errors"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/gf"
+)
+
+// Encoder is a systematic Reed-Solomon code: the first DataShards shards hold
+// the data itself and the last ParityShards hold the redundancy, so that the
+// data can be recovered from any DataShards of the shards
+type Encoder struct {
+	DataShards   int
+	ParityShards int
+	field        *gf.Field
+	matrix       matrix
+}
+
+var ErrTooFewShards = errors.New("too few shards are available to reconstruct the data")
+var ErrShardSize = errors.New("shards have different or invalid sizes")
+var ErrShardCount = errors.New("wrong number of shards")
+
+func New(field *gf.Field, dataShards int, parityShards int) (*Encoder, error) {
+	if dataShards <= 0 || parityShards < 0 {
+		return nil, errorutils.Newf("invalid shard counts: %d data and %d parity", dataShards, parityShards)
+	}
+
+	if dataShards+parityShards > field.Order() {
+		return nil, errorutils.Newf("GF(2^%d) supports at most %d shards", field.Bits, field.Order())
+	}
+
+	// Multiplying by the inverse of the top square makes the code systematic,
+	// while keeping every square submatrix invertible
+	v := vandermonde(field, dataShards+parityShards, dataShards)
+	top, err := v.subRows(rangeOf(dataShards)).invert(field)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Encoder)
+	r.DataShards = dataShards
+	r.ParityShards = parityShards
+	r.field = field
+	r.matrix = v.mul(field, top)
+
+	return r, nil
+}
+
+func (e *Encoder) TotalShards() int {
+	return e.DataShards + e.ParityShards
+}
+
+// Split pads data and divides it into DataShards shards of equal size, followed
+// by empty parity shards. The original size is needed by Join to remove the padding
+func (e *Encoder) Split(data []byte) [][]byte {
+	symbolBytes := e.field.SymbolBytes()
+	symbols := (len(data) + e.DataShards*symbolBytes - 1) / (e.DataShards * symbolBytes)
+	if symbols == 0 {
+		symbols = 1
+	}
+
+	shardSize := symbols * symbolBytes
+	padded := make([]byte, shardSize*e.DataShards)
+	copy(padded, data)
+
+	r := make([][]byte, e.TotalShards())
+	for i := range r {
+		if i < e.DataShards {
+			r[i] = padded[i*shardSize : (i+1)*shardSize]
+		} else {
+			r[i] = make([]byte, shardSize)
+		}
+	}
+
+	return r
+}
+
+// Encode computes the parity shards from the data shards
+func (e *Encoder) Encode(shards [][]byte) error {
+	shardSize, err := e.checkShards(shards, false)
+	if err != nil {
+		return err
+	}
+
+	e.apply(e.matrix[e.DataShards:], shards[:e.DataShards], shards[e.DataShards:], shardSize)
+
+	return nil
+}
+
+// Verify checks whether the parity shards match the data shards
+func (e *Encoder) Verify(shards [][]byte) (bool, error) {
+	shardSize, err := e.checkShards(shards, false)
+	if err != nil {
+		return false, err
+	}
+
+	parity := make([][]byte, e.ParityShards)
+	for i := range parity {
+		parity[i] = make([]byte, shardSize)
+	}
+
+	e.apply(e.matrix[e.DataShards:], shards[:e.DataShards], parity, shardSize)
+
+	for i := range parity {
+		if string(parity[i]) != string(shards[e.DataShards+i]) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Reconstruct recomputes every missing (nil) shard, as long as at least
+// DataShards shards are present
+func (e *Encoder) Reconstruct(shards [][]byte) error {
+	shardSize, err := e.checkShards(shards, true)
+	if err != nil {
+		return err
+	}
+
+	present := make([]int, 0, e.DataShards)
+	for i := range shards {
+		if shards[i] != nil && len(present) < e.DataShards {
+			present = append(present, i)
+		}
+	}
+
+	if len(present) < e.DataShards {
+		return ErrTooFewShards
+	}
+
+	decode, err := e.matrix.subRows(present).invert(e.field)
+	if err != nil {
+		return err
+	}
+
+	inputs := make([][]byte, len(present))
+	for i, idx := range present {
+		inputs[i] = shards[idx]
+	}
+
+	data := make([][]byte, e.DataShards)
+	for i := range data {
+		data[i] = make([]byte, shardSize)
+	}
+
+	e.apply(decode, inputs, data, shardSize)
+
+	for i := 0; i < e.DataShards; i++ {
+		if shards[i] == nil {
+			shards[i] = data[i]
+		}
+	}
+
+	for i := e.DataShards; i < e.TotalShards(); i++ {
+		if shards[i] == nil {
+			shards[i] = make([]byte, shardSize)
+			e.apply(e.matrix[i:i+1], shards[:e.DataShards], shards[i:i+1], shardSize)
+		}
+	}
+
+	return nil
+}
+
+// Join concatenates the data shards and removes the padding added by Split
+func (e *Encoder) Join(shards [][]byte, size int) ([]byte, error) {
+	if len(shards) < e.DataShards {
+		return nil, ErrShardCount
+	}
+
+	r := make([]byte, 0, size)
+	for i := 0; i < e.DataShards; i++ {
+		if shards[i] == nil {
+			return nil, ErrTooFewShards
+		}
+
+		r = append(r, shards[i]...)
+	}
+
+	if len(r) < size {
+		return nil, ErrShardSize
+	}
+
+	return r[:size], nil
+}
+
+func (e *Encoder) checkShards(shards [][]byte, allowMissing bool) (int, error) {
+	if len(shards) != e.TotalShards() {
+		return 0, ErrShardCount
+	}
+
+	shardSize := -1
+	for i := range shards {
+		if shards[i] == nil && allowMissing {
+			continue
+		}
+
+		if shardSize == -1 {
+			shardSize = len(shards[i])
+		}
+
+		if len(shards[i]) != shardSize {
+			return 0, ErrShardSize
+		}
+	}
+
+	if shardSize <= 0 || shardSize%e.field.SymbolBytes() != 0 {
+		return 0, ErrShardSize
+	}
+
+	return shardSize, nil
+}
+
+// apply writes m * inputs into outputs, symbol by symbol
+func (e *Encoder) apply(m matrix, inputs [][]byte, outputs [][]byte, shardSize int) {
+	f := e.field
+
+	for s := 0; s < shardSize/f.SymbolBytes(); s++ {
+		for i := range outputs {
+			var v uint16
+			for j := range inputs {
+				v ^= f.Mul(m[i][j], f.Symbol(inputs[j], s))
+			}
+
+			f.SetSymbol(outputs[i], s, v)
+		}
+	}
+}
+
+func rangeOf(n int) []int {
+	r := make([]int, n)
+
+	for i := range r {
+		r[i] = i
+	}
+
+	return r
+}
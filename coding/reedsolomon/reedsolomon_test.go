@@ -0,0 +1,88 @@
+package reedsolomon_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/coding/reedsolomon"
+	"github.com/titosilva/pdpr-go/crypto/random"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/math/gf"
+)
+
+var testCases = []struct {
+	field        *gf.Field
+	dataShards   int
+	parityShards int
+	size         int
+}{
+	{gf.GF8(), 4, 2, 1000},
+	{gf.GF8(), 10, 4, 1},
+	{gf.GF8(), 1, 1, 33},
+	{gf.GF16(), 4, 3, 999},
+	{gf.GF16(), 300, 20, 4096},
+}
+
+func Test__ReedSolomon__Reconstruct__ShouldRecover__AnyKShards(t *testing.T) {
+	for _, tc := range testCases {
+		ez := ez.New(t)
+		data, _ := random.GenerateBytes(tc.size)
+
+		enc, err := reedsolomon.New(tc.field, tc.dataShards, tc.parityShards)
+		ez.AssertNoError(err)
+
+		shards := enc.Split(data)
+		ez.AssertNoError(enc.Encode(shards))
+
+		ok, err := enc.Verify(shards)
+		ez.AssertNoError(err)
+		ez.Assert(ok)
+
+		// drop as many shards as there are parity shards, mixing data and parity
+		for i := 0; i < tc.parityShards; i++ {
+			shards[(i*7)%enc.TotalShards()] = nil
+		}
+
+		ez.AssertNoError(enc.Reconstruct(shards))
+
+		joined, err := enc.Join(shards, len(data))
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(joined, data)
+
+		ok, _ = enc.Verify(shards)
+		ez.Assert(ok)
+	}
+}
+
+func Test__ReedSolomon__Reconstruct__ShouldFail__WhenTooManyShardsAreMissing(t *testing.T) {
+	ez := ez.New(t)
+	enc, _ := reedsolomon.New(gf.GF8(), 3, 2)
+
+	shards := enc.Split([]byte("Hello, World!"))
+	ez.AssertNoError(enc.Encode(shards))
+
+	shards[0], shards[2], shards[4] = nil, nil, nil
+	err := enc.Reconstruct(shards)
+
+	ez.Assert(errors.Is(err, reedsolomon.ErrTooFewShards))
+}
+
+func Test__ReedSolomon__Verify__ShouldDetect__CorruptedShard(t *testing.T) {
+	ez := ez.New(t)
+	enc, _ := reedsolomon.New(gf.GF16(), 3, 2)
+
+	shards := enc.Split([]byte("Hello, World!"))
+	ez.AssertNoError(enc.Encode(shards))
+	shards[1][0] ^= 1
+
+	ok, err := enc.Verify(shards)
+	ez.AssertNoError(err)
+	ez.AssertFalse(ok)
+}
+
+func Test__ReedSolomon__New__ShouldFail__WhenFieldIsTooSmall(t *testing.T) {
+	ez := ez.New(t)
+
+	_, err := reedsolomon.New(gf.GF8(), 200, 57)
+	ez.Assert(err != nil)
+}
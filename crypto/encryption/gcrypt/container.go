@@ -0,0 +1,288 @@
+package gcrypt
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+)
+
+// Container is the output of Encrypt with everything needed to decrypt it:
+//
+//	magic "GCRY" (4 bytes)
+//	version (1 byte)
+//	flags (1 byte): 1 if the container is tagged
+//	key expansion, bits per block and headroom (1 byte each)
+//	modulus size in bits (2 bytes)
+//	plaintext length (8 bytes)
+//	key ID length (1 byte) and key ID
+//	nonce (NonceSize bytes)
+//	body: the blocks of the encrypted plaintext
+//	tag (TagSize bytes, if tagged): HMAC-SHA256 of everything before it
+//
+// Integers are big-endian. The body is encrypted with a key derived from the
+// key and the nonce, so a key can seal many containers. Tagged containers
+// detect any change, but cannot be operated on homomorphically
+type Container struct {
+	Version         uint8
+	ModulusBits     uint64
+	Encoding        Encoding
+	KeyExpansion    KeyExpansion
+	PlaintextLength uint64
+	// KeyID names the key, for the caller to find it; it is not checked
+	KeyID []byte
+	Nonce []byte
+	Body  []byte
+	// Tag is empty if the container is not tagged
+	Tag []byte
+}
+
+const ContainerVersion = 1
+
+const (
+	NonceSize    = 16
+	TagSize      = sha256.Size
+	MaxKeyIDSize = 255
+)
+
+const containerMagic = "GCRY"
+
+// containerHeaderSize is the size of the fixed fields, before the key ID
+const containerHeaderSize = 4 + 1 + 1 + 3 + 2 + 8 + 1
+
+const flagTagged byte = 1
+
+// labels of the keys derived from the key and the nonce
+const (
+	containerKeyLabel = "pdpr-go/gcrypt/container/key"
+	containerTagLabel = "pdpr-go/gcrypt/container/tag"
+)
+
+var ErrMalformedContainer = errors.New("malformed container")
+var ErrUnsupportedVersion = errors.New("unsupported container version")
+var ErrTagMismatch = errors.New("container tag does not match")
+var ErrInvalidPadding = errors.New("container padding is not zero")
+
+// Seal encrypts plaintext into a container with a fresh nonce, tagged if tagged
+func (g *GCrypt) Seal(plaintext []byte, key []byte, keyID []byte, tagged bool) (*Container, error) {
+	if len(keyID) > MaxKeyIDSize {
+		return nil, errorutils.NewfWithInner(ErrMalformedContainer, "key ID of %d bytes, above %d", len(keyID), MaxKeyIDSize)
+	}
+
+	nonce, err := random.GenerateBytes(NonceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := g.Encrypt(plaintext, deriveContainerKey(containerKeyLabel, key, nonce))
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Container)
+	r.Version = ContainerVersion
+	r.ModulusBits = g.modulusBitsize
+	r.Encoding = g.encoding
+	r.KeyExpansion = g.expansion
+	r.PlaintextLength = uint64(len(plaintext))
+	r.KeyID = bytes.Clone(keyID)
+	r.Nonce = nonce
+	r.Body = body
+
+	if tagged {
+		r.Tag = r.computeTag(key)
+	}
+
+	return r, nil
+}
+
+// ParseContainer reads the output of MarshalBinary, checking every field
+func ParseContainer(data []byte) (*Container, error) {
+	if len(data) < containerHeaderSize {
+		return nil, errorutils.NewfWithInner(ErrMalformedContainer, "%d bytes, shorter than a header", len(data))
+	}
+
+	if string(data[:4]) != containerMagic {
+		return nil, errorutils.NewWithInner(ErrMalformedContainer, "not a GCrypt container")
+	}
+
+	r := new(Container)
+	r.Version = data[4]
+	if r.Version != ContainerVersion {
+		return nil, errorutils.NewfWithInner(ErrUnsupportedVersion, "version %d", r.Version)
+	}
+
+	flags := data[5]
+	if flags&^flagTagged != 0 {
+		return nil, errorutils.NewfWithInner(ErrMalformedContainer, "unknown flags %#x", flags)
+	}
+
+	r.KeyExpansion = KeyExpansion(data[6])
+	r.Encoding = Encoding{BitsPerBlock: uint64(data[7]), Headroom: uint64(data[8])}
+	r.ModulusBits = uint64(binary.BigEndian.Uint16(data[9:]))
+	r.PlaintextLength = binary.BigEndian.Uint64(data[11:])
+	keyIDSize := int(data[19])
+
+	rest := data[containerHeaderSize:]
+	if len(rest) < keyIDSize+NonceSize {
+		return nil, errorutils.NewWithInner(ErrMalformedContainer, "truncated key ID or nonce")
+	}
+	r.KeyID = bytes.Clone(rest[:keyIDSize])
+	r.Nonce = bytes.Clone(rest[keyIDSize : keyIDSize+NonceSize])
+	rest = rest[keyIDSize+NonceSize:]
+
+	if flags&flagTagged != 0 {
+		if len(rest) < TagSize {
+			return nil, errorutils.NewWithInner(ErrMalformedContainer, "truncated tag")
+		}
+		r.Tag = bytes.Clone(rest[len(rest)-TagSize:])
+		rest = rest[:len(rest)-TagSize]
+	}
+	r.Body = bytes.Clone(rest)
+
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalBinary encodes the container in the format of Container
+func (c *Container) MarshalBinary() ([]byte, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	r := append(c.header(), c.Body...)
+	return append(r, c.Tag...), nil
+}
+
+// Open checks the tag, if any, and decrypts the plaintext
+func (c *Container) Open(key []byte) ([]byte, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	if len(c.Tag) != 0 && !hmac.Equal(c.Tag, c.computeTag(key)) {
+		return nil, ErrTagMismatch
+	}
+
+	// the parameters were checked above
+	g := c.crypt()
+	decrypted := g.Decrypt(c.Body, deriveContainerKey(containerKeyLabel, key, c.Nonce))
+
+	// Encrypt pads compact encodings with zeros to whole blocks, which a wrong
+	// key is likely to decrypt to nonzero bytes
+	for _, b := range decrypted[c.PlaintextLength:] {
+		if b != 0 {
+			return nil, ErrInvalidPadding
+		}
+	}
+
+	return decrypted[:c.PlaintextLength], nil
+}
+
+// Tagged reports whether the container has a tag
+func (c *Container) Tagged() bool {
+	return len(c.Tag) != 0
+}
+
+// check validates every field, and the sizes of the body and the tag against them
+func (c *Container) check() error {
+	if c.Version != ContainerVersion {
+		return errorutils.NewfWithInner(ErrUnsupportedVersion, "version %d", c.Version)
+	}
+
+	if c.ModulusBits > 0xffff {
+		return errorutils.NewfWithInner(ErrMalformedContainer, "%d bits modulus does not fit in the header", c.ModulusBits)
+	}
+
+	if err := uintp.CheckModulus(c.ModulusBits); err != nil {
+		return errors.Join(ErrMalformedContainer, err)
+	}
+
+	if err := c.Encoding.Check(); err != nil {
+		return errors.Join(ErrMalformedContainer, err)
+	}
+
+	if err := c.KeyExpansion.Check(); err != nil {
+		return errors.Join(ErrMalformedContainer, err)
+	}
+
+	if len(c.KeyID) > MaxKeyIDSize {
+		return errorutils.NewfWithInner(ErrMalformedContainer, "key ID of %d bytes, above %d", len(c.KeyID), MaxKeyIDSize)
+	}
+
+	if len(c.Nonce) != NonceSize {
+		return errorutils.NewfWithInner(ErrMalformedContainer, "nonce of %d bytes instead of %d", len(c.Nonce), NonceSize)
+	}
+
+	if len(c.Tag) != 0 && len(c.Tag) != TagSize {
+		return errorutils.NewfWithInner(ErrMalformedContainer, "tag of %d bytes instead of %d", len(c.Tag), TagSize)
+	}
+
+	// every block holds at most a block of plaintext, so a plaintext longer
+	// than the body is malformed, and its block count fits in an int
+	if c.PlaintextLength > uint64(len(c.Body)) {
+		return errorutils.NewfWithInner(ErrMalformedContainer, "%d bytes of plaintext in a body of %d", c.PlaintextLength, len(c.Body))
+	}
+
+	g := c.crypt()
+	if expected := g.BlockCount(int(c.PlaintextLength)) * g.blockSize(); len(c.Body) != expected {
+		return errorutils.NewfWithInner(ErrMalformedContainer, "body of %d bytes instead of %d", len(c.Body), expected)
+	}
+
+	return nil
+}
+
+// crypt returns the GCrypt of the container, whose parameters must have been checked
+func (c *Container) crypt() *GCrypt {
+	r := MustNewWithEncoding(c.ModulusBits, c.Encoding)
+	r.expansion = c.KeyExpansion
+
+	return r
+}
+
+func (c *Container) header() []byte {
+	flags := byte(0)
+	if len(c.Tag) != 0 {
+		flags |= flagTagged
+	}
+
+	r := []byte(containerMagic)
+	r = append(r, c.Version, flags, byte(c.KeyExpansion), byte(c.Encoding.BitsPerBlock), byte(c.Encoding.Headroom))
+	r = binary.BigEndian.AppendUint16(r, uint16(c.ModulusBits))
+	r = binary.BigEndian.AppendUint64(r, c.PlaintextLength)
+	r = append(r, byte(len(c.KeyID)))
+	r = append(r, c.KeyID...)
+
+	return append(r, c.Nonce...)
+}
+
+// computeTag is the HMAC of the header, with the tagged flag set, and the body
+func (c *Container) computeTag(key []byte) []byte {
+	header := c.header()
+	header[5] |= flagTagged
+
+	mac := hmac.New(sha256.New, deriveContainerKey(containerTagLabel, key, c.Nonce))
+	mac.Write(header)
+	mac.Write(c.Body)
+
+	return mac.Sum(nil)
+}
+
+// deriveContainerKey is SHA-256(label, key, nonce), with the key length-prefixed
+func deriveContainerKey(label string, key []byte, nonce []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(label))
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(key))))
+	h.Write(key)
+	h.Write(nonce)
+
+	return h.Sum(nil)
+}
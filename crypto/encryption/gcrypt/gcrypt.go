@@ -2,28 +2,131 @@ package gcrypt
 
 import (
 	"crypto/sha256"
+	"errors"
+	"math/big"
 
 	"github.com/titosilva/pdpr-go/crypto/random/drbg/sha256drbg"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
 	"github.com/titosilva/pdpr-go/math/uintp"
 )
 
 type GCrypt struct {
 	modulusBitsize uint64
+	encoding       Encoding
+	expansion      KeyExpansion
 }
 
-func New(modulusBitsize uint64) *GCrypt {
+// Encoding packs BitsPerBlock plaintext bits in the low bits of every block,
+// followed by Headroom zero bits and random noise. Sums of up to 2^Headroom
+// values then fit below the noise, for homomorphic additions.
+// BitsPerBlock is a power of two, and the two fit in 64 bits
+type Encoding struct {
+	BitsPerBlock uint64 `json:"bits_per_block"`
+	Headroom     uint64 `json:"headroom"`
+}
+
+// BitEncoding is the encoding of New, with each bit as the parity of a block
+var BitEncoding = Encoding{BitsPerBlock: 1, Headroom: 0}
+
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+func (e Encoding) Check() error {
+	if e.BitsPerBlock == 0 || e.BitsPerBlock > 64 || e.BitsPerBlock&(e.BitsPerBlock-1) != 0 {
+		return errorutils.NewfWithInner(ErrInvalidEncoding, "%d bits per block is not a power of two up to 64", e.BitsPerBlock)
+	}
+
+	if e.BitsPerBlock+e.Headroom > 64 {
+		return errorutils.NewfWithInner(ErrInvalidEncoding, "%d bits per block and %d bits of headroom do not fit in 64 bits", e.BitsPerBlock, e.Headroom)
+	}
+
+	return nil
+}
+
+// width is the number of low bits taken by the value and its headroom
+func (e Encoding) width() uint64 {
+	return e.BitsPerBlock + e.Headroom
+}
+
+// maxValue is the largest value of a block, 2^BitsPerBlock - 1
+func (e Encoding) maxValue() *big.Int {
+	r := new(big.Int).Lsh(big.NewInt(1), uint(e.BitsPerBlock))
+	return r.Sub(r, big.NewInt(1))
+}
+
+// New returns a GCrypt over integers modulo 2^modulusBitsize, which must be a
+// positive multiple of 64, with the bit encoding. As it is checked here, the
+// conversions of the other methods cannot fail
+func New(modulusBitsize uint64) (*GCrypt, error) {
+	return NewWithEncoding(modulusBitsize, BitEncoding)
+}
+
+// NewWithEncoding is New with another encoding
+func NewWithEncoding(modulusBitsize uint64, encoding Encoding) (*GCrypt, error) {
+	if err := uintp.CheckModulus(modulusBitsize); err != nil {
+		return nil, err
+	}
+
+	if err := encoding.Check(); err != nil {
+		return nil, err
+	}
+
 	r := new(GCrypt)
 
 	r.modulusBitsize = modulusBitsize
+	r.encoding = encoding
+
+	return r, nil
+}
+
+// MustNewWithEncoding is like NewWithEncoding, but panics if the modulus or the encoding is invalid
+func MustNewWithEncoding(modulusBitsize uint64, encoding Encoding) *GCrypt {
+	r, err := NewWithEncoding(modulusBitsize, encoding)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+func (g *GCrypt) Encoding() Encoding {
+	return g.encoding
+}
+
+// BlockCount is the number of blocks of the encoding of length bytes
+func (g *GCrypt) BlockCount(length int) int {
+	bits := uint64(length) * 8
+	return int((bits + g.encoding.BitsPerBlock - 1) / g.encoding.BitsPerBlock)
+}
+
+// MustNew is like New, but panics if the modulus is invalid
+func MustNew(modulusBitsize uint64) *GCrypt {
+	r, err := New(modulusBitsize)
+	if err != nil {
+		panic(err)
+	}
 
 	return r
 }
 
-func (g *GCrypt) Encrypt(data []byte, key []byte) []byte {
-	encoded := g.Encode(data)
+func (g *GCrypt) Encrypt(data []byte, key []byte) ([]byte, error) {
+	encoded, err := g.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+
 	encrypted := g.EncryptEncoded(encoded, key)
 
-	return g.ToBytes(encrypted)
+	return g.ToBytes(encrypted), nil
+}
+
+// MustEncrypt is like Encrypt, but panics on error
+func (g *GCrypt) MustEncrypt(data []byte, key []byte) []byte {
+	r, err := g.Encrypt(data, key)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
 }
 
 func (g *GCrypt) Decrypt(data []byte, key []byte) []byte {
@@ -33,66 +136,135 @@ func (g *GCrypt) Decrypt(data []byte, key []byte) []byte {
 	return decoded
 }
 
-func (g *GCrypt) EncodeToBytes(data []byte) []byte {
-	encoded := g.Encode(data)
+func (g *GCrypt) EncodeToBytes(data []byte) ([]byte, error) {
+	encoded, err := g.Encode(data)
+	if err != nil {
+		return nil, err
+	}
 
-	return g.ToBytes(encoded)
+	return g.ToBytes(encoded), nil
 }
 
-func (g *GCrypt) Encode(data []byte) []*uintp.UintP {
-	// Encodes each bit in data to a randomly generated number,
-	// being even or odd depending on the bit value
-	r := make([]*uintp.UintP, len(data)*8)
+func (g *GCrypt) Encode(data []byte) ([]*uintp.UintP, error) {
+	// Encodes each group of bits in data to a randomly generated number,
+	// whose low bits are replaced by the bits and the headroom.
+	// With the bit encoding, the number is even or odd depending on the bit value
+	r := make([]*uintp.UintP, g.BlockCount(len(data)))
 	drbg := sha256drbg.New()
 	seed := sha256.Sum256(data)
 	drbg.Seed(seed[:])
 
-	for i := 0; i < len(data)*8; i++ {
+	for i := range r {
 		bs, err := drbg.Generate(int(g.modulusBitsize) / 8)
 		if err != nil {
-			panic(err)
+			return nil, errorutils.NewWithInner(err, "could not encode data")
 		}
 
-		u := uintp.FromBytes(g.modulusBitsize, bs)
-		u.SetBit(0, data[i/8]&(1<<(i%8)) != 0)
-		r[i] = u
+		r[i] = g.encodeBlock(bs, packedValue(data, i, g.encoding.BitsPerBlock))
+	}
+
+	return r, nil
+}
+
+// encodeBlock replaces the low bits of the block of noise with value and the
+// headroom. noise has the bytes of a block, so the conversion cannot fail
+func (g *GCrypt) encodeBlock(noise []byte, value uint64) *uintp.UintP {
+	setLowBits(noise, g.encoding.width(), value)
+	return uintp.MustFromBytes(g.modulusBitsize, noise)
+}
+
+// packedValue returns the index-th group of bits bits of data, in little-endian
+// bit order. As bits is a power of two, groups never straddle bytes below 8 bits
+func packedValue(data []byte, index int, bits uint64) uint64 {
+	start := uint64(index) * bits
+	if bits < 8 {
+		return uint64(data[start/8]>>(start%8)) & (1<<bits - 1)
+	}
+
+	r := uint64(0)
+	for j := uint64(0); j < bits/8 && start/8+j < uint64(len(data)); j++ {
+		r |= uint64(data[start/8+j]) << (8 * j)
 	}
 
 	return r
 }
 
-func (g *GCrypt) ExpandKey(key []byte, lengthBlocks int) []*uintp.UintP {
-	// Expands the key to the desired length using a DRBG
-	drbg := sha256drbg.New()
-	drbg.Seed(key)
+// setLowBits replaces the low width bits of the little-endian bs with value
+func setLowBits(bs []byte, width uint64, value uint64) {
+	for j := uint64(0); j < width/8; j++ {
+		bs[j] = byte(value >> (8 * j))
+	}
 
-	r := make([]*uintp.UintP, lengthBlocks)
+	if rest := width % 8; rest != 0 {
+		mask := byte(1<<rest - 1)
+		bs[width/8] = bs[width/8]&^mask | byte(value>>(width/8*8))&mask
+	}
+}
 
-	for i := 0; i < lengthBlocks; i++ {
-		generated, _ := drbg.Generate(int(g.modulusBitsize / 8))
-		r[i] = uintp.FromBytes(g.modulusBitsize, generated)
+// MustEncode is like Encode, but panics on error
+func (g *GCrypt) MustEncode(data []byte) []*uintp.UintP {
+	r, err := g.Encode(data)
+	if err != nil {
+		panic(err)
 	}
 
 	return r
 }
 
+func (g *GCrypt) ExpandKey(key []byte, lengthBlocks int) []*uintp.UintP {
+	// Expands the key to the desired length with the key expansion of g
+	return g.ExpandKeyRange(key, 0, lengthBlocks)
+}
+
 func (g *GCrypt) ExpandKeyToBytes(key []byte, lengthBlocks int) []byte {
 	expandedKey := g.ExpandKey(key, lengthBlocks)
 
 	return g.ToBytes(expandedKey)
 }
 
+// Decode returns the plaintext padded with zeros to whole blocks, and to whole
+// bytes with the bit encoding
 func (g *GCrypt) Decode(encodedData []*uintp.UintP) []byte {
-	// Decodes each number in encodedData to a bit
-	r := make([]byte, (len(encodedData)+7)/8)
+	// Decodes each number in encodedData to its low bits
+	bits := g.encoding.BitsPerBlock
+	r := make([]byte, (uint64(len(encodedData))*bits+7)/8)
 
 	for i := range encodedData {
-		r[i/8] |= byte(encodedData[i].Bit(0)) << uint(i%8)
+		value := encodedData[i].Uint64() & lowMask(bits)
+		start := uint64(i) * bits
+
+		if bits < 8 {
+			r[start/8] |= byte(value << (start % 8))
+			continue
+		}
+
+		for j := uint64(0); j < bits/8; j++ {
+			r[start/8+j] = byte(value >> (8 * j))
+		}
 	}
 
 	return r
 }
 
+// DecodeValues returns the low bits of each block, up to the headroom, which
+// hold the sums of homomorphic additions
+func (g *GCrypt) DecodeValues(encodedData []*uintp.UintP) []uint64 {
+	r := make([]uint64, len(encodedData))
+	for i := range encodedData {
+		r[i] = encodedData[i].Uint64() & lowMask(g.encoding.width())
+	}
+
+	return r
+}
+
+func lowMask(bits uint64) uint64 {
+	if bits >= 64 {
+		return ^uint64(0)
+	}
+
+	return 1<<bits - 1
+}
+
 func (g *GCrypt) EncryptEncoded(encodedData []*uintp.UintP, key []byte) []*uintp.UintP {
 	r := make([]*uintp.UintP, len(encodedData))
 	expandedKey := g.ExpandKey(key, len(encodedData))
@@ -134,7 +306,7 @@ func (g GCrypt) FromBytes(data []byte) []*uintp.UintP {
 	r := make([]*uintp.UintP, (len(data)*8+int(g.modulusBitsize)-1)/int(g.modulusBitsize))
 
 	for i := 0; i < len(r); i++ {
-		r[i] = uintp.FromBytes(g.modulusBitsize, data[i*int(g.modulusBitsize/8):])
+		r[i] = uintp.MustFromBytes(g.modulusBitsize, data[i*int(g.modulusBitsize/8):])
 	}
 
 	return r
@@ -1,6 +1,7 @@
 package gcrypt_test
 
 import (
+	"bytes"
 	"crypto/rand"
 
 	"testing"
@@ -21,23 +22,23 @@ func generateRandomBytes(size int) ([]byte, error) {
 }
 
 func runEncryptBenchmark(b *testing.B, messageBitsize int, chunkSize uint) {
-	g := gcrypt.New(uint64(chunkSize))
+	g := gcrypt.MustNew(uint64(chunkSize))
 	bs, _ := generateRandomBytes(messageBitsize / 8)
 	key, _ := generateRandomBytes(32)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		g.Encrypt(bs, key)
+		g.MustEncrypt(bs, key)
 	}
 
 	b.ReportMetric(float64(b.Elapsed().Milliseconds())/float64(b.N), "ms/encryption")
 }
 
 func runDecryptBenchmark(b *testing.B, messageBitSize int, chunkSize uint) {
-	g := gcrypt.New(uint64(chunkSize))
+	g := gcrypt.MustNew(uint64(chunkSize))
 	bs, _ := generateRandomBytes(messageBitSize / 8)
 	key, _ := generateRandomBytes(32)
-	encrypted := g.Encrypt(bs, key)
+	encrypted := g.MustEncrypt(bs, key)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -70,3 +71,62 @@ func Benchmark__GCrypt__Decrypt__192__128(b *testing.B) {
 func Benchmark__GCrypt__Decrypt__256__128(b *testing.B) {
 	runDecryptBenchmark(b, 256, 128)
 }
+
+// runEncodingBenchmark encrypts and decrypts 1 KiB, reporting the ciphertext
+// size per plaintext byte, to compare the compact encodings with the bit encoding
+func runEncodingBenchmark(b *testing.B, bitsPerBlock uint64) {
+	g := gcrypt.MustNewWithEncoding(128, gcrypt.Encoding{BitsPerBlock: bitsPerBlock})
+	bs, _ := generateRandomBytes(1024)
+	key, _ := generateRandomBytes(32)
+	b.ResetTimer()
+
+	size := 0
+	for i := 0; i < b.N; i++ {
+		encrypted := g.MustEncrypt(bs, key)
+		g.Decrypt(encrypted, key)
+		size = len(encrypted)
+	}
+
+	b.ReportMetric(float64(b.Elapsed().Milliseconds())/float64(b.N), "ms/roundtrip")
+	b.ReportMetric(float64(size)/float64(len(bs)), "expansion")
+}
+
+func Benchmark__GCrypt__Encoding__1KiB__128__1bit(b *testing.B) {
+	runEncodingBenchmark(b, 1)
+}
+
+func Benchmark__GCrypt__Encoding__1KiB__128__8bits(b *testing.B) {
+	runEncodingBenchmark(b, 8)
+}
+
+func Benchmark__GCrypt__Encoding__1KiB__128__32bits(b *testing.B) {
+	runEncodingBenchmark(b, 32)
+}
+
+func Benchmark__GCrypt__Encoding__1KiB__128__64bits(b *testing.B) {
+	runEncodingBenchmark(b, 64)
+}
+
+// runReadAtBenchmark decrypts the last 16 bytes of 16 KiB, which only depends
+// on the size of the file with the chain key expansion
+func runReadAtBenchmark(b *testing.B, expansion gcrypt.KeyExpansion) {
+	g, _ := gcrypt.MustNewWithEncoding(128, gcrypt.Encoding{BitsPerBlock: 8}).WithKeyExpansion(expansion)
+	bs, _ := generateRandomBytes(16 * 1024)
+	key, _ := generateRandomBytes(32)
+	encrypted := g.MustEncrypt(bs, key)
+	ra, _ := g.NewReaderAt(bytes.NewReader(encrypted), int64(len(encrypted)), key)
+	buf := make([]byte, 16)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ra.ReadAt(buf, int64(len(bs)-len(buf)))
+	}
+}
+
+func Benchmark__GCrypt__ReadAt__16KiB__Chain(b *testing.B) {
+	runReadAtBenchmark(b, gcrypt.ChainExpansion)
+}
+
+func Benchmark__GCrypt__ReadAt__16KiB__Counter(b *testing.B) {
+	runReadAtBenchmark(b, gcrypt.CounterExpansion)
+}
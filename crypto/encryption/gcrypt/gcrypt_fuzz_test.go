@@ -0,0 +1,80 @@
+package gcrypt_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+)
+
+// maxFuzzSize bounds the data, as every bit is encoded in a block
+const maxFuzzSize = 256
+
+func fuzzCrypt(size byte) *gcrypt.GCrypt {
+	return gcrypt.MustNew(64 * (1 + uint64(size%4)))
+}
+
+func FuzzGCrypt__EncryptThenDecrypt__ShouldReturnOriginalData(f *testing.F) {
+	f.Add(byte(0), []byte("Hello, World!"), []byte("This is a key"))
+	f.Add(byte(1), []byte{}, []byte{})
+	f.Add(byte(3), []byte{0x00, 0xff}, []byte{0xff})
+
+	f.Fuzz(func(t *testing.T, size byte, data []byte, key []byte) {
+		data = data[:min(len(data), maxFuzzSize)]
+		crypt := fuzzCrypt(size)
+
+		ct := crypt.MustEncrypt(data, key)
+		if len(ct) != len(data)*8*len(crypt.ExpandKeyToBytes(key, 1)) {
+			t.Fatalf("ciphertext of %d bytes has %d bytes", len(data), len(ct))
+		}
+
+		if decrypted := crypt.Decrypt(ct, key); !bytes.Equal(decrypted, data) {
+			t.Fatalf("decrypted %x, want %x", decrypted, data)
+		}
+
+		if decoded := crypt.Decode(crypt.FromBytes(crypt.ToBytes(crypt.MustEncode(data)))); !bytes.Equal(decoded, data) {
+			t.Fatalf("decoded %x, want %x", decoded, data)
+		}
+	})
+}
+
+func FuzzGCrypt__Decrypt__ShouldAcceptAnyCiphertext(f *testing.F) {
+	f.Add(byte(0), []byte{}, []byte("key"))
+	f.Add(byte(1), []byte{1, 2, 3}, []byte("key"))
+	f.Add(byte(2), bytes.Repeat([]byte{0xff}, 25), []byte{})
+
+	f.Fuzz(func(t *testing.T, size byte, ct []byte, key []byte) {
+		ct = ct[:min(len(ct), maxFuzzSize)]
+		crypt := fuzzCrypt(size)
+		blockSize := len(crypt.ExpandKeyToBytes(key, 1))
+
+		decrypted := crypt.Decrypt(ct, key)
+		blocks := (len(ct) + blockSize - 1) / blockSize
+		if len(decrypted) != (blocks+7)/8 {
+			t.Fatalf("%d bytes of ciphertext decrypted to %d bytes", len(ct), len(decrypted))
+		}
+	})
+}
+
+func FuzzParseContainer__ShouldAccept__OnlyWhatItMarshals(f *testing.F) {
+	for _, tagged := range []bool{false, true} {
+		c, _ := gcrypt.MustNew(64).Seal([]byte("Hello"), []byte("key"), []byte("id"), tagged)
+		bs, _ := c.MarshalBinary()
+		f.Add(bs)
+	}
+	f.Add([]byte("GCRY"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		c, err := gcrypt.ParseContainer(data)
+		if err != nil {
+			return
+		}
+
+		bs, err := c.MarshalBinary()
+		if err != nil || !bytes.Equal(bs, data) {
+			t.Fatalf("parsed %x, marshaled to %x (%v)", data, bs, err)
+		}
+
+		c.Open([]byte("key"))
+	})
+}
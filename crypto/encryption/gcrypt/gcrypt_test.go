@@ -1,19 +1,24 @@
 package gcrypt_test
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
 	"github.com/titosilva/pdpr-go/crypto/random"
 	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/math/uintp"
 )
 
 func Test__GCrypto__EncryptThenDecrypt__Should__ReturnOriginalValue(t *testing.T) {
 	data := []byte("Hello, World!")
 	key := []byte("This is a key")
 
-	g := gcrypt.New(128)
-	encrypted := g.Encrypt(data, key)
+	g := gcrypt.MustNew(128)
+	encrypted := g.MustEncrypt(data, key)
 	decrypted := g.Decrypt(encrypted, key)
 
 	if string(decrypted) != string(data) {
@@ -24,8 +29,8 @@ func Test__GCrypto__EncryptThenDecrypt__Should__ReturnOriginalValue(t *testing.T
 func Test__GCrypto__ToBytesThenFromBytes__Should__ReturnOriginalValue(t *testing.T) {
 	data := []byte("Hello, World!")
 
-	g := gcrypt.New(128)
-	encoded := g.EncodeToBytes(data)
+	g := gcrypt.MustNew(128)
+	encoded := g.ToBytes(g.MustEncode(data))
 	decoded := g.Decode(g.FromBytes(encoded))
 
 	if string(decoded) != string(data) {
@@ -35,12 +40,419 @@ func Test__GCrypto__ToBytesThenFromBytes__Should__ReturnOriginalValue(t *testing
 
 func Test__GCrypto__ToBytesThenFromBytes__Should__ReturnOriginalValue2(t *testing.T) {
 	ez := ez.New(t)
-	crypt := gcrypt.New(64)
+	crypt := gcrypt.MustNew(64)
 
 	rnd, _ := random.GenerateBytes(1)
-	data := crypt.EncodeToBytes(rnd)
-	encData := crypt.Encode(rnd)
+	data := crypt.ToBytes(crypt.MustEncode(rnd))
+	encData := crypt.MustEncode(rnd)
 	expData := crypt.FromBytes(data)
 
 	ez.AssertAreEqual(expData, encData)
 }
+
+func Test__GCrypto__New__ShouldReturnError__WhenModulusIsInvalid(t *testing.T) {
+	ez := ez.New(t)
+
+	for _, bits := range []uint64{0, 100} {
+		_, err := gcrypt.New(bits)
+		ez.Assert(errors.Is(err, uintp.ErrInvalidModulus))
+	}
+}
+
+func Test__GCrypto__Decrypt__ShouldInvert__Encrypt__ForAllMessagesAndKeys(t *testing.T) {
+	ez.ForAll(ez.New(t), ez.PairOf(ez.Bytes(0, 64), ez.Bytes(1, 32)), func(p ez.Pair[[]byte, []byte]) bool {
+		g := gcrypt.MustNew(128)
+		return bytes.Equal(g.Decrypt(g.MustEncrypt(p.First, p.Second), p.Second), p.First)
+	})
+}
+
+func xor(a []byte, b []byte) []byte {
+	r := make([]byte, len(a))
+	for i := range a {
+		r[i] = a[i] ^ b[i]
+	}
+
+	return r
+}
+
+func Test__Ciphertext__Add__ShouldXorBits__AndDecryptWithSumOfKeys(t *testing.T) {
+	g := gcrypt.MustNew(64)
+
+	ez.ForAll(ez.New(t), ez.PairOf(ez.Bytes(8, 8), ez.Bytes(8, 8)), func(p ez.Pair[[]byte, []byte]) bool {
+		c1, _ := g.EncryptCiphertext(p.First, []byte("key 1"))
+		c2, _ := g.EncryptCiphertext(p.Second, []byte("key 2"))
+		sum, err := c1.Add(c2)
+		if err != nil {
+			return false
+		}
+
+		key, _ := g.NewExpandedKey([]byte("key 1"), 64).Add(g.NewExpandedKey([]byte("key 2"), 64))
+		decrypted, err := sum.Decrypt(key)
+		return err == nil && bytes.Equal(decrypted, xor(p.First, p.Second))
+	})
+}
+
+func Test__Ciphertext__AddPlainAndMul__ShouldMatch__Bits(t *testing.T) {
+	ez := ez.New(t)
+	g := gcrypt.MustNew(128)
+	data, mask := []byte("Hello, World!"), []byte("This is mask!")
+	key := g.NewExpandedKey([]byte("key"), len(data)*8)
+
+	c, err := g.ParseCiphertext(g.MustEncrypt(data, []byte("key")))
+	ez.AssertNoError(err)
+
+	masked, err := c.AddPlain(mask)
+	ez.AssertNoError(err)
+	decrypted, err := masked.Decrypt(key)
+	ez.AssertNoError(err)
+	ez.AssertBytesEqual(decrypted, xor(data, mask))
+
+	odd := c.MulUint(12345)
+	ez.Assert(odd.HasParity())
+	decrypted, err = odd.Decrypt(key.MulUint(12345))
+	ez.AssertNoError(err)
+	ez.AssertBytesEqual(decrypted, data)
+
+	even := c.MulUint(12)
+	ez.AssertAreEqual(even.ZeroBits(), uint64(2))
+	ez.AssertFalse(even.HasParity())
+	decrypted, err = even.Decrypt(key.MulUint(12))
+	ez.AssertNoError(err)
+	ez.AssertBytesEqual(decrypted, make([]byte, len(data)))
+
+	// a fresh term brings the parity back
+	sum, err := even.Add(c)
+	ez.AssertNoError(err)
+	ez.Assert(sum.HasParity())
+	sumKey, _ := key.MulUint(12).Add(key)
+	decrypted, err = sum.Decrypt(sumKey)
+	ez.AssertNoError(err)
+	ez.AssertBytesEqual(decrypted, data)
+
+	ez.AssertBytesEqual(c.Bytes(), g.MustEncrypt(data, []byte("key")))
+}
+
+func Test__Ciphertext__ShouldReject__MismatchedOperands(t *testing.T) {
+	ez := ez.New(t)
+	c64, _ := gcrypt.MustNew(64).EncryptCiphertext([]byte{1}, []byte("key"))
+	c128, _ := gcrypt.MustNew(128).EncryptCiphertext([]byte{1}, []byte("key"))
+	long, _ := gcrypt.MustNew(64).EncryptCiphertext([]byte{1, 2}, []byte("key"))
+
+	_, err := c64.Add(c128)
+	ez.AssertErrorIs(err, gcrypt.ErrDifferentModulus)
+	_, err = c64.Add(long)
+	ez.AssertErrorIs(err, gcrypt.ErrDifferentLength)
+	_, err = c64.AddPlain([]byte{1, 2})
+	ez.AssertErrorIs(err, gcrypt.ErrDifferentLength)
+	_, err = c64.MulScalar(uintp.MustFromUint(128, 3))
+	ez.AssertErrorIs(err, gcrypt.ErrDifferentModulus)
+	_, err = c64.Decrypt(gcrypt.MustNew(64).NewExpandedKey([]byte("key"), 16))
+	ez.AssertErrorIs(err, gcrypt.ErrDifferentLength)
+	_, err = gcrypt.MustNew(64).ParseCiphertext(make([]byte, 12))
+	ez.AssertErrorIs(err, gcrypt.ErrMalformedCiphertext)
+}
+
+var compactEncodings = []gcrypt.Encoding{
+	{BitsPerBlock: 2, Headroom: 0},
+	{BitsPerBlock: 8, Headroom: 3},
+	{BitsPerBlock: 16, Headroom: 0},
+	{BitsPerBlock: 64, Headroom: 0},
+}
+
+func Test__GCrypto__CompactEncodings__ShouldRoundTrip__AndShrinkCiphertexts(t *testing.T) {
+	for _, encoding := range compactEncodings {
+		g := gcrypt.MustNewWithEncoding(128, encoding)
+
+		ez.ForAll(ez.New(t), ez.Bytes(0, 64), func(data []byte) bool {
+			encrypted := g.MustEncrypt(data, []byte("key"))
+			decrypted := g.Decrypt(encrypted, []byte("key"))
+
+			return len(encrypted) == g.BlockCount(len(data))*16 &&
+				bytes.Equal(decrypted[:len(data)], data) &&
+				bytes.Equal(decrypted[len(data):], make([]byte, len(decrypted)-len(data)))
+		})
+	}
+
+	ez := ez.New(t)
+	ez.AssertAreEqual(gcrypt.MustNewWithEncoding(128, gcrypt.Encoding{BitsPerBlock: 16}).BlockCount(13), 7)
+	ez.AssertAreEqual(gcrypt.MustNew(128).BlockCount(13), 104)
+}
+
+func Test__GCrypto__BitEncoding__ShouldMatch__New(t *testing.T) {
+	ez := ez.New(t)
+	data, key := []byte("Hello, World!"), []byte("key")
+
+	ez.AssertBytesEqual(gcrypt.MustNewWithEncoding(64, gcrypt.BitEncoding).MustEncrypt(data, key), gcrypt.MustNew(64).MustEncrypt(data, key))
+	ez.AssertAreEqual(gcrypt.MustNew(64).Encoding(), gcrypt.BitEncoding)
+}
+
+func Test__GCrypto__NewWithEncoding__ShouldReject__InvalidEncodings(t *testing.T) {
+	ez := ez.New(t)
+
+	for _, encoding := range []gcrypt.Encoding{{BitsPerBlock: 0}, {BitsPerBlock: 3}, {BitsPerBlock: 128}, {BitsPerBlock: 32, Headroom: 33}} {
+		_, err := gcrypt.NewWithEncoding(128, encoding)
+		ez.AssertErrorIs(err, gcrypt.ErrInvalidEncoding)
+	}
+}
+
+func Test__Ciphertext__Add__ShouldSumValues__BelowTheHeadroom(t *testing.T) {
+	ez := ez.New(t)
+	g := gcrypt.MustNewWithEncoding(128, gcrypt.Encoding{BitsPerBlock: 8, Headroom: 4})
+	values := [][]byte{{200, 1}, {100, 2}, {255, 3}}
+
+	var sum *gcrypt.Ciphertext
+	var key *gcrypt.ExpandedKey
+	for i, v := range values {
+		k := []byte{byte(i)}
+		c, err := g.EncryptCiphertext(v, k)
+		ez.AssertNoError(err)
+
+		if sum == nil {
+			sum, key = c, g.NewExpandedKey(k, c.Len())
+			continue
+		}
+
+		sum, err = sum.Add(c)
+		ez.AssertNoError(err)
+		key, err = key.Add(g.NewExpandedKey(k, c.Len()))
+		ez.AssertNoError(err)
+	}
+
+	sums, err := sum.DecryptValues(key)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(sums, []uint64{555, 6})
+
+	// Decrypt keeps the low bits per block only
+	decrypted, err := sum.Decrypt(key)
+	ez.AssertNoError(err)
+	ez.AssertBytesEqual(decrypted, []byte{555 % 256, 6})
+
+	other, _ := gcrypt.MustNewWithEncoding(128, gcrypt.Encoding{BitsPerBlock: 8}).EncryptCiphertext([]byte{1, 2}, []byte("key"))
+	_, err = sum.Add(other)
+	ez.AssertErrorIs(err, gcrypt.ErrDifferentEncoding)
+}
+
+func Test__Ciphertext__DecryptValues__ShouldFail__AboveTheHeadroom(t *testing.T) {
+	ez := ez.New(t)
+	g := gcrypt.MustNewWithEncoding(128, gcrypt.Encoding{BitsPerBlock: 8, Headroom: 4})
+	c, err := g.EncryptCiphertext([]byte{255, 1}, []byte("key"))
+	ez.AssertNoError(err)
+	ez.AssertFalse(c.HeadroomExceeded())
+
+	// 16 * 255 still fits in 12 bits, 17 * 255 may not
+	ez.AssertFalse(c.MulUint(16).HeadroomExceeded())
+	ez.Assert(c.MulUint(17).HeadroomExceeded())
+
+	sum := c
+	for range 15 {
+		sum, err = sum.AddPlain([]byte{0, 0})
+		ez.AssertNoError(err)
+	}
+	ez.AssertFalse(sum.HeadroomExceeded())
+
+	sum, err = sum.Add(c)
+	ez.AssertNoError(err)
+	ez.Assert(sum.HeadroomExceeded())
+
+	key := g.NewExpandedKey([]byte("key"), c.Len())
+	_, err = c.MulUint(17).DecryptValues(key.MulUint(17))
+	ez.AssertErrorIs(err, gcrypt.ErrHeadroomExceeded)
+
+	// the low bits are still right
+	decrypted, err := c.MulUint(17).Decrypt(key.MulUint(17))
+	ez.AssertNoError(err)
+	ez.AssertBytesEqual(decrypted, []byte{255 * 17 % 256, 17})
+}
+
+func Test__GCrypto__ExpandKeyRange__ShouldMatch__ExpandKey(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("key")
+
+	for _, expansion := range []gcrypt.KeyExpansion{gcrypt.ChainExpansion, gcrypt.CounterExpansion} {
+		g, err := gcrypt.MustNew(192).WithKeyExpansion(expansion)
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(g.KeyExpansion(), expansion)
+
+		ez.AssertAreEqual(g.ExpandKeyRange(key, 5, 3), g.ExpandKey(key, 8)[5:])
+		ez.AssertBytesEqual(g.Decrypt(g.MustEncrypt([]byte("Hello, World!"), key), key), []byte("Hello, World!"))
+	}
+
+	counter, _ := gcrypt.MustNew(192).WithKeyExpansion(gcrypt.CounterExpansion)
+	ez.AssertFalse(bytes.Equal(counter.ExpandKeyToBytes(key, 4), gcrypt.MustNew(192).ExpandKeyToBytes(key, 4)))
+
+	_, err := gcrypt.MustNew(192).WithKeyExpansion(gcrypt.KeyExpansion(7))
+	ez.AssertErrorIs(err, gcrypt.ErrInvalidKeyExpansion)
+}
+
+// streamCrypts covers the bit and compact encodings with both key expansions
+func streamCrypts() []*gcrypt.GCrypt {
+	r := []*gcrypt.GCrypt{}
+	for _, encoding := range []gcrypt.Encoding{gcrypt.BitEncoding, {BitsPerBlock: 8}, {BitsPerBlock: 64, Headroom: 0}} {
+		for _, expansion := range []gcrypt.KeyExpansion{gcrypt.ChainExpansion, gcrypt.CounterExpansion} {
+			g, _ := gcrypt.MustNewWithEncoding(64, encoding).WithKeyExpansion(expansion)
+			r = append(r, g)
+		}
+	}
+
+	return r
+}
+
+func Test__GCrypto__EncrypterThenDecrypter__ShouldMatch__Decrypt(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("key")
+
+	for _, g := range streamCrypts() {
+		for _, size := range []int{0, 1, 31, 32, 33, 1000, 5003} {
+			data, _ := random.GenerateBytes(size)
+
+			ciphertext := new(bytes.Buffer)
+			enc := g.NewEncrypter(ciphertext, key)
+			for rest := data; len(rest) > 0; rest = rest[min(7, len(rest)):] {
+				_, err := enc.Write(rest[:min(7, len(rest))])
+				ez.AssertNoError(err)
+			}
+			ez.AssertNoError(enc.Close())
+			_, err := enc.Write(data)
+			ez.AssertErrorIs(err, gcrypt.ErrClosed)
+
+			ez.AssertAreEqual(ciphertext.Len(), g.BlockCount(size)*8)
+			expected := g.Decrypt(ciphertext.Bytes(), key)
+			ez.AssertBytesEqual(expected[:size], data)
+
+			ez.AssertNoError(iotest.TestReader(g.NewDecrypter(bytes.NewReader(ciphertext.Bytes()), key), expected))
+		}
+	}
+}
+
+func Test__GCrypto__ReaderAt__ShouldDecrypt__AnyRange(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("key")
+
+	for _, g := range streamCrypts() {
+		data, _ := random.GenerateBytes(200)
+		ciphertext := g.MustEncrypt(data, key)
+		expected := g.Decrypt(ciphertext, key)
+
+		ra, err := g.NewReaderAt(bytes.NewReader(ciphertext), int64(len(ciphertext)), key)
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(ra.Size(), int64(len(expected)))
+		ez.AssertNoError(iotest.TestReader(io.NewSectionReader(ra, 0, ra.Size()), expected))
+	}
+
+	_, err := gcrypt.MustNew(64).NewReaderAt(bytes.NewReader(nil), 12, key)
+	ez.AssertErrorIs(err, gcrypt.ErrMalformedCiphertext)
+}
+
+func Test__GCrypto__ReaderAt__ShouldMatch__Decrypt__ForAllRanges(t *testing.T) {
+	key := []byte("key")
+
+	for _, g := range streamCrypts() {
+		data, _ := random.GenerateBytes(200)
+		ciphertext := g.MustEncrypt(data, key)
+		expected := g.Decrypt(ciphertext, key)
+		ra, _ := g.NewReaderAt(bytes.NewReader(ciphertext), int64(len(ciphertext)), key)
+
+		ez.ForAll(ez.New(t), ez.PairOf(ez.IntRange(0, len(expected)), ez.IntRange(0, 100)), func(p ez.Pair[int, int]) bool {
+			buf := make([]byte, p.Second)
+			n, err := ra.ReadAt(buf, int64(p.First))
+			end := min(p.First+p.Second, len(expected))
+
+			return n == end-p.First && bytes.Equal(buf[:n], expected[p.First:end]) && (err == nil) == (n == p.Second && p.First < len(expected))
+		})
+	}
+}
+
+func Test__GCrypto__Decrypter__ShouldFail__WhenCiphertextIsTruncated(t *testing.T) {
+	ez := ez.New(t)
+	g := gcrypt.MustNew(64)
+	ciphertext := g.MustEncrypt([]byte("Hello, World!"), []byte("key"))
+
+	decrypted, err := io.ReadAll(g.NewDecrypter(bytes.NewReader(ciphertext[:len(ciphertext)-3]), []byte("key")))
+	ez.AssertErrorIs(err, gcrypt.ErrMalformedCiphertext)
+	// the 103 whole blocks decrypt to all but the last bit
+	ez.AssertBytesEqual(decrypted[:12], []byte("Hello, World"))
+}
+
+func Test__Container__SealThenParse__ShouldOpen__ThePlaintext(t *testing.T) {
+	ez := ez.New(t)
+	key, keyID := []byte("key"), []byte("key ID")
+
+	for _, g := range streamCrypts() {
+		for _, tagged := range []bool{false, true} {
+			for _, data := range [][]byte{{}, {42}, []byte("Hello, World!")} {
+				c, err := g.Seal(data, key, keyID, tagged)
+				ez.AssertNoError(err)
+				ez.AssertAreEqual(c.Tagged(), tagged)
+				ez.AssertAreEqual(c.KeyExpansion, g.KeyExpansion())
+
+				bs, err := c.MarshalBinary()
+				ez.AssertNoError(err)
+				parsed, err := gcrypt.ParseContainer(bs)
+				ez.AssertNoError(err)
+				ez.AssertAreEqual(parsed, c)
+
+				opened, err := parsed.Open(key)
+				ez.AssertNoError(err)
+				ez.AssertBytesEqual(opened, data)
+			}
+		}
+	}
+
+	// fresh nonces give different bodies for the same plaintext and key
+	c1, _ := gcrypt.MustNew(64).Seal([]byte("Hello"), key, keyID, false)
+	c2, _ := gcrypt.MustNew(64).Seal([]byte("Hello"), key, keyID, false)
+	ez.AssertFalse(bytes.Equal(c1.Body, c2.Body))
+}
+
+func Test__Container__Open__ShouldFail__WhenKeyIsWrongOrContainerIsTampered(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("key")
+
+	tagged, _ := gcrypt.MustNew(64).Seal([]byte("Hello, World!"), key, nil, true)
+	_, err := tagged.Open([]byte("other key"))
+	ez.AssertErrorIs(err, gcrypt.ErrTagMismatch)
+
+	tagged.Body[0] ^= 1
+	_, err = tagged.Open(key)
+	ez.AssertErrorIs(err, gcrypt.ErrTagMismatch)
+
+	// a byte in a block of 64 bits leaves 7 bytes of padding
+	compact, _ := gcrypt.MustNewWithEncoding(64, gcrypt.Encoding{BitsPerBlock: 64}).Seal([]byte{42}, key, nil, false)
+	_, err = compact.Open([]byte("other key"))
+	ez.AssertErrorIs(err, gcrypt.ErrInvalidPadding)
+}
+
+func Test__ParseContainer__ShouldReject__InvalidFields(t *testing.T) {
+	ez := ez.New(t)
+	c, _ := gcrypt.MustNewWithEncoding(64, gcrypt.Encoding{BitsPerBlock: 8}).Seal([]byte("Hello"), []byte("key"), []byte("id"), true)
+	valid, _ := c.MarshalBinary()
+
+	// corrupt changes a copy at the offsets of the fields documented in Container
+	corrupt := func(f func(bs []byte) []byte) []byte {
+		return f(bytes.Clone(valid))
+	}
+	cases := map[string][]byte{
+		"magic":            corrupt(func(bs []byte) []byte { bs[0] = 'X'; return bs }),
+		"flags":            corrupt(func(bs []byte) []byte { bs[5] |= 2; return bs }),
+		"key expansion":    corrupt(func(bs []byte) []byte { bs[6] = 9; return bs }),
+		"bits per block":   corrupt(func(bs []byte) []byte { bs[7] = 3; return bs }),
+		"headroom":         corrupt(func(bs []byte) []byte { bs[8] = 60; return bs }),
+		"modulus":          corrupt(func(bs []byte) []byte { bs[10] = 100; return bs }),
+		"plaintext length": corrupt(func(bs []byte) []byte { bs[18]++; return bs }),
+		"key ID length":    corrupt(func(bs []byte) []byte { bs[19] = 200; return bs }),
+		"untagged":         corrupt(func(bs []byte) []byte { bs[5] = 0; return bs }),
+		"truncated":        valid[:len(valid)-1],
+		"trailing":         append(bytes.Clone(valid), 0),
+		"header":           valid[:10],
+	}
+
+	for name, bs := range cases {
+		_, err := gcrypt.ParseContainer(bs)
+		if !errors.Is(err, gcrypt.ErrMalformedContainer) {
+			t.Errorf("%s: got %v, want %v", name, err, gcrypt.ErrMalformedContainer)
+		}
+	}
+
+	_, err := gcrypt.ParseContainer(corrupt(func(bs []byte) []byte { bs[4] = 2; return bs }))
+	ez.AssertErrorIs(err, gcrypt.ErrUnsupportedVersion)
+}
@@ -0,0 +1,315 @@
+package gcrypt
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+)
+
+// A GCrypt block is the encoding of a value plus a block of the expanded key,
+// modulo 2^modulusBitsize, and the value is in the low bits of the encoding,
+// below its headroom and noise. So, modulo 2^(BitsPerBlock+Headroom):
+//   - adding ciphertexts adds their values, and decrypts with the sum of their keys;
+//   - adding a plaintext adds its values, and decrypts with the same key;
+//   - multiplying by a public scalar multiplies the values, and decrypts with
+//     the key multiplied by the scalar.
+// With the bit encoding, additions XOR the bits, and a multiplication ANDs them
+// with the parity of the scalar.
+// Ciphertext and ExpandedKey implement these operations, which return new
+// values. A Ciphertext tracks the low bits of its values that multiplications
+// by even scalars zeroed: with the bit encoding, once there is one, every bit
+// decrypts to 0. It also tracks a bound on its values: once it reaches
+// 2^(BitsPerBlock+Headroom), the values may have carried into the noise, and
+// only their low BitsPerBlock bits, as returned by Decrypt, are still right
+
+var ErrDifferentModulus = errors.New("different modulus")
+var ErrDifferentLength = errors.New("different number of blocks")
+var ErrDifferentEncoding = errors.New("different encoding")
+var ErrMalformedCiphertext = errors.New("ciphertext is not a whole number of blocks")
+var ErrHeadroomExceeded = errors.New("values may exceed the headroom of the encoding")
+
+// Ciphertext is a GCrypt ciphertext of Len() blocks
+type Ciphertext struct {
+	modulusBitsize uint64
+	encoding       Encoding
+	blocks         []*uintp.UintP
+	zeroBits       uint64
+	// bound is the largest value a block may hold
+	bound *big.Int
+}
+
+// ExpandedKey decrypts a Ciphertext of as many blocks
+type ExpandedKey struct {
+	modulusBitsize uint64
+	blocks         []*uintp.UintP
+}
+
+// ParseCiphertext reads the output of Encrypt, whose values are bounded as
+// those of a fresh encryption
+func (g *GCrypt) ParseCiphertext(ciphertext []byte) (*Ciphertext, error) {
+	if len(ciphertext)%g.blockSize() != 0 {
+		return nil, errorutils.NewfWithInner(ErrMalformedCiphertext, "%d bytes in blocks of %d", len(ciphertext), g.blockSize())
+	}
+
+	r := new(Ciphertext)
+	r.modulusBitsize = g.modulusBitsize
+	r.encoding = g.encoding
+	r.blocks = g.FromBytes(ciphertext)
+	r.bound = g.encoding.maxValue()
+
+	return r, nil
+}
+
+// EncryptCiphertext is Encrypt, for homomorphic operations
+func (g *GCrypt) EncryptCiphertext(data []byte, key []byte) (*Ciphertext, error) {
+	encoded, err := g.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Ciphertext)
+	r.modulusBitsize = g.modulusBitsize
+	r.encoding = g.encoding
+	r.blocks = g.EncryptEncoded(encoded, key)
+	r.bound = g.encoding.maxValue()
+
+	return r, nil
+}
+
+// NewExpandedKey expands key to decrypt a ciphertext of lengthBlocks blocks
+func (g *GCrypt) NewExpandedKey(key []byte, lengthBlocks int) *ExpandedKey {
+	r := new(ExpandedKey)
+	r.modulusBitsize = g.modulusBitsize
+	r.blocks = g.ExpandKey(key, lengthBlocks)
+
+	return r
+}
+
+func (g *GCrypt) blockSize() int {
+	return int(g.modulusBitsize / 8)
+}
+
+// Len is the number of blocks
+func (c *Ciphertext) Len() int {
+	return len(c.blocks)
+}
+
+func (c *Ciphertext) Bytes() []byte {
+	return c.crypt().ToBytes(c.blocks)
+}
+
+// ZeroBits is the number of low bits of every value that were zeroed by
+// multiplications by even scalars
+func (c *Ciphertext) ZeroBits() uint64 {
+	return c.zeroBits
+}
+
+// HasParity reports whether the low bit of the values survived every
+// multiplication; if not, with the bit encoding, every bit decrypts to 0
+func (c *Ciphertext) HasParity() bool {
+	return c.zeroBits == 0
+}
+
+// HeadroomExceeded reports whether the values may have outgrown
+// BitsPerBlock+Headroom bits, in which case DecryptValues fails
+func (c *Ciphertext) HeadroomExceeded() bool {
+	return c.bound.BitLen() > int(c.encoding.width())
+}
+
+// crypt returns the GCrypt of the ciphertext, whose modulus and encoding were
+// checked when it was created
+func (c *Ciphertext) crypt() *GCrypt {
+	return MustNewWithEncoding(c.modulusBitsize, c.encoding)
+}
+
+// Add returns a ciphertext of the sums of the values of c and d, which decrypts
+// with the sum of their keys
+func (c *Ciphertext) Add(d *Ciphertext) (*Ciphertext, error) {
+	if err := checkSameShape(c.modulusBitsize, len(c.blocks), d.modulusBitsize, len(d.blocks)); err != nil {
+		return nil, err
+	}
+
+	if c.encoding != d.encoding {
+		return nil, ErrDifferentEncoding
+	}
+
+	r := c.clone()
+	for i := range r.blocks {
+		r.blocks[i].Add(d.blocks[i])
+	}
+	r.zeroBits = min(c.zeroBits, d.zeroBits)
+	r.bound = new(big.Int).Add(c.bound, d.bound)
+
+	return r, nil
+}
+
+// AddPlain returns a ciphertext of the sums of the values of c and data, which
+// decrypts with the key of c
+func (c *Ciphertext) AddPlain(data []byte) (*Ciphertext, error) {
+	crypt := c.crypt()
+	if crypt.BlockCount(len(data)) != len(c.blocks) {
+		return nil, errorutils.NewfWithInner(ErrDifferentLength, "%d bytes for %d blocks", len(data), len(c.blocks))
+	}
+
+	encoded, err := crypt.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := c.clone()
+	for i := range r.blocks {
+		r.blocks[i].Add(encoded[i])
+	}
+	// fresh encodings have random low bits
+	r.zeroBits = 0
+	r.bound = new(big.Int).Add(c.bound, c.encoding.maxValue())
+
+	return r, nil
+}
+
+// MulUint returns a ciphertext of the values of c multiplied by s, which
+// decrypts with the key of c multiplied by s
+func (c *Ciphertext) MulUint(s uint64) *Ciphertext {
+	return c.mul(uintp.MustFromUint(c.modulusBitsize, s))
+}
+
+// MulScalar is MulUint with a scalar of the modulus of c
+func (c *Ciphertext) MulScalar(s *uintp.UintP) (*Ciphertext, error) {
+	if s.ModulusBitsize != c.modulusBitsize {
+		return nil, errorutils.NewfWithInner(ErrDifferentModulus, "scalar of %d bits for blocks of %d", s.ModulusBitsize, c.modulusBitsize)
+	}
+
+	return c.mul(s), nil
+}
+
+func (c *Ciphertext) mul(s *uintp.UintP) *Ciphertext {
+	r := c.clone()
+	for i := range r.blocks {
+		r.blocks[i].Mul(s)
+	}
+	r.zeroBits = min(c.modulusBitsize, c.zeroBits+s.TrailingZeros())
+	// the bytes of s are little-endian
+	scalar := s.Bytes()
+	slices.Reverse(scalar)
+	r.bound = new(big.Int).Mul(c.bound, new(big.Int).SetBytes(scalar))
+
+	return r
+}
+
+// Decrypt decrypts c with a key combined as c was, into values modulo 2^BitsPerBlock
+func (c *Ciphertext) Decrypt(key *ExpandedKey) ([]byte, error) {
+	decrypted, err := c.decryptEncoded(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.crypt().Decode(decrypted), nil
+}
+
+// DecryptValues is Decrypt into values modulo 2^(BitsPerBlock+Headroom),
+// one per block. It fails with ErrHeadroomExceeded if the values may not fit
+func (c *Ciphertext) DecryptValues(key *ExpandedKey) ([]uint64, error) {
+	if c.HeadroomExceeded() {
+		return nil, errorutils.NewfWithInner(ErrHeadroomExceeded, "values of up to %d bits, for %d bits of headroom", c.bound.BitLen(), c.encoding.width())
+	}
+
+	decrypted, err := c.decryptEncoded(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.crypt().DecodeValues(decrypted), nil
+}
+
+func (c *Ciphertext) decryptEncoded(key *ExpandedKey) ([]*uintp.UintP, error) {
+	if err := checkSameShape(c.modulusBitsize, len(c.blocks), key.modulusBitsize, len(key.blocks)); err != nil {
+		return nil, err
+	}
+
+	r := make([]*uintp.UintP, len(c.blocks))
+	for i := range c.blocks {
+		r[i] = uintp.Clone(c.blocks[i]).Sub(key.blocks[i])
+	}
+
+	return r, nil
+}
+
+func (c *Ciphertext) clone() *Ciphertext {
+	r := new(Ciphertext)
+	r.modulusBitsize = c.modulusBitsize
+	r.encoding = c.encoding
+	r.blocks = cloneBlocks(c.blocks)
+	r.zeroBits = c.zeroBits
+	r.bound = c.bound
+
+	return r
+}
+
+// Add returns the key of the sum of the ciphertexts of k and l
+func (k *ExpandedKey) Add(l *ExpandedKey) (*ExpandedKey, error) {
+	if err := checkSameShape(k.modulusBitsize, len(k.blocks), l.modulusBitsize, len(l.blocks)); err != nil {
+		return nil, err
+	}
+
+	r := k.clone()
+	for i := range r.blocks {
+		r.blocks[i].Add(l.blocks[i])
+	}
+
+	return r, nil
+}
+
+// MulUint returns the key of the ciphertext of k multiplied by s
+func (k *ExpandedKey) MulUint(s uint64) *ExpandedKey {
+	return k.mul(uintp.MustFromUint(k.modulusBitsize, s))
+}
+
+// MulScalar is MulUint with a scalar of the modulus of k
+func (k *ExpandedKey) MulScalar(s *uintp.UintP) (*ExpandedKey, error) {
+	if s.ModulusBitsize != k.modulusBitsize {
+		return nil, errorutils.NewfWithInner(ErrDifferentModulus, "scalar of %d bits for blocks of %d", s.ModulusBitsize, k.modulusBitsize)
+	}
+
+	return k.mul(s), nil
+}
+
+func (k *ExpandedKey) mul(s *uintp.UintP) *ExpandedKey {
+	r := k.clone()
+	for i := range r.blocks {
+		r.blocks[i].Mul(s)
+	}
+
+	return r
+}
+
+func (k *ExpandedKey) clone() *ExpandedKey {
+	r := new(ExpandedKey)
+	r.modulusBitsize = k.modulusBitsize
+	r.blocks = cloneBlocks(k.blocks)
+
+	return r
+}
+
+func checkSameShape(modulus1 uint64, length1 int, modulus2 uint64, length2 int) error {
+	if modulus1 != modulus2 {
+		return errorutils.NewfWithInner(ErrDifferentModulus, "%d and %d bits", modulus1, modulus2)
+	}
+
+	if length1 != length2 {
+		return errorutils.NewfWithInner(ErrDifferentLength, "%d and %d blocks", length1, length2)
+	}
+
+	return nil
+}
+
+func cloneBlocks(blocks []*uintp.UintP) []*uintp.UintP {
+	r := make([]*uintp.UintP, len(blocks))
+	for i := range blocks {
+		r[i] = uintp.Clone(blocks[i])
+	}
+
+	return r
+}
@@ -0,0 +1,135 @@
+package gcrypt
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"github.com/titosilva/pdpr-go/crypto/random/drbg/sha256drbg"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+)
+
+// KeyExpansion is how a key is expanded to a block per plaintext block
+type KeyExpansion int
+
+const (
+	// ChainExpansion is the expansion of New: a SHA-256 hash chain seeded with
+	// the key, so block i costs the i blocks before it
+	ChainExpansion KeyExpansion = iota
+	// CounterExpansion derives block i from SHA-256(key, i) alone, so any range
+	// of blocks costs its length
+	CounterExpansion
+)
+
+var ErrInvalidKeyExpansion = errors.New("invalid key expansion")
+
+// counterDomain separates the counter expansion from other uses of SHA-256 of the key
+const counterDomain = "pdpr-go/gcrypt/ctr"
+
+func (e KeyExpansion) Check() error {
+	if e != ChainExpansion && e != CounterExpansion {
+		return errorutils.NewfWithInner(ErrInvalidKeyExpansion, "unknown key expansion %d", int(e))
+	}
+
+	return nil
+}
+
+func (e KeyExpansion) String() string {
+	switch e {
+	case ChainExpansion:
+		return "chain"
+	case CounterExpansion:
+		return "counter"
+	default:
+		return "unknown"
+	}
+}
+
+// WithKeyExpansion returns a copy of g that expands keys with e
+func (g *GCrypt) WithKeyExpansion(e KeyExpansion) (*GCrypt, error) {
+	if err := e.Check(); err != nil {
+		return nil, err
+	}
+
+	r := new(GCrypt)
+	*r = *g
+	r.expansion = e
+
+	return r, nil
+}
+
+func (g *GCrypt) KeyExpansion() KeyExpansion {
+	return g.expansion
+}
+
+// ExpandKeyRange returns the count blocks of the expanded key starting at block
+// first, in time proportional to count with the counter expansion, and to
+// first+count with the chain expansion
+func (g *GCrypt) ExpandKeyRange(key []byte, first int, count int) []*uintp.UintP {
+	ks := g.newKeyStream(key, first)
+
+	r := make([]*uintp.UintP, count)
+	for i := range r {
+		r[i] = ks.next()
+	}
+
+	return r
+}
+
+// keyStream generates the blocks of an expanded key in order
+type keyStream struct {
+	g     *GCrypt
+	key   []byte
+	index uint64
+	chain *sha256drbg.SHA256DRBG
+}
+
+// newKeyStream returns the key stream of key, positioned at block first
+func (g *GCrypt) newKeyStream(key []byte, first int) *keyStream {
+	r := new(keyStream)
+	r.g = g
+	r.key = key
+	r.index = uint64(first)
+
+	if g.expansion == ChainExpansion {
+		r.chain = sha256drbg.New()
+		r.chain.Seed(key)
+		for i := 0; i < first; i++ {
+			r.chain.Generate(g.blockSize())
+		}
+	}
+
+	return r
+}
+
+func (ks *keyStream) next() *uintp.UintP {
+	var generated []byte
+	if ks.chain != nil {
+		generated, _ = ks.chain.Generate(ks.g.blockSize())
+	} else {
+		generated = ks.counterBlock()
+	}
+	ks.index++
+
+	// generated has exactly the bytes of a block of the modulus of g
+	return uintp.MustFromBytes(ks.g.modulusBitsize, generated)
+}
+
+// counterBlock is SHA-256(domain, key, index, j) for j = 0, 1, ..., truncated to a block
+func (ks *keyStream) counterBlock() []byte {
+	r := make([]byte, 0, ks.g.blockSize()+sha256.Size)
+	h := sha256.New()
+
+	for j := uint32(0); len(r) < ks.g.blockSize(); j++ {
+		h.Reset()
+		h.Write([]byte(counterDomain))
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(ks.key))))
+		h.Write(ks.key)
+		h.Write(binary.BigEndian.AppendUint64(nil, ks.index))
+		h.Write(binary.BigEndian.AppendUint32(nil, j))
+		r = h.Sum(r)
+	}
+
+	return r[:ks.g.blockSize()]
+}
@@ -0,0 +1,236 @@
+package gcrypt
+
+import (
+	"errors"
+	"io"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// Encrypter, Decrypter and ReaderAt encrypt and decrypt the format of Encrypt
+// and Decrypt a chunk at a time, so neither the plaintext nor the ciphertext
+// needs to fit in memory. ReaderAt only reads and decrypts the blocks of the
+// range it is asked for, which is only cheap with the counter key expansion
+
+// chunkBlocks is the number of blocks encrypted or decrypted at once. It is a
+// multiple of 8, so chunks of the bit encoding are whole bytes
+const chunkBlocks = 256
+
+var ErrClosed = errors.New("encrypter is closed")
+
+// Encrypter encrypts the plaintext written to it to an underlying writer.
+// Unlike Encrypt, it draws the noise of the encoding from crypto/rand, so its
+// ciphertexts are not deterministic, but they decrypt the same
+type Encrypter struct {
+	g      *GCrypt
+	w      io.Writer
+	ks     *keyStream
+	buf    []byte
+	closed bool
+}
+
+// NewEncrypter returns an Encrypter of key writing to w
+func (g *GCrypt) NewEncrypter(w io.Writer, key []byte) *Encrypter {
+	r := new(Encrypter)
+	r.g = g
+	r.w = w
+	r.ks = g.newKeyStream(key, 0)
+
+	return r
+}
+
+// Write encrypts the whole chunks of the plaintext written so far, and keeps
+// the rest until the next Write or Close
+func (e *Encrypter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrClosed
+	}
+
+	e.buf = append(e.buf, p...)
+	size := e.g.chunkBytes()
+
+	written := 0
+	for len(e.buf)-written >= size {
+		if err := e.encryptChunk(e.buf[written : written+size]); err != nil {
+			return 0, err
+		}
+		written += size
+	}
+	e.buf = append(e.buf[:0], e.buf[written:]...)
+
+	return len(p), nil
+}
+
+// Close encrypts the rest of the plaintext, padded with zeros to whole blocks.
+// It does not close the underlying writer
+func (e *Encrypter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	if len(e.buf) == 0 {
+		return nil
+	}
+
+	return e.encryptChunk(e.buf)
+}
+
+func (e *Encrypter) encryptChunk(data []byte) error {
+	blockSize := e.g.blockSize()
+	count := e.g.BlockCount(len(data))
+
+	noise, err := random.GenerateBytes(count * blockSize)
+	if err != nil {
+		return errorutils.NewWithInner(err, "could not encode data")
+	}
+
+	r := make([]byte, 0, count*blockSize)
+	for i := 0; i < count; i++ {
+		block := e.g.encodeBlock(noise[i*blockSize:(i+1)*blockSize], packedValue(data, i, e.g.encoding.BitsPerBlock))
+		r = append(r, block.Add(e.ks.next()).Bytes()...)
+	}
+
+	_, err = e.w.Write(r)
+	return err
+}
+
+// Decrypter decrypts a ciphertext read from an underlying reader, into the
+// output of Decrypt
+type Decrypter struct {
+	g   *GCrypt
+	r   io.Reader
+	ks  *keyStream
+	buf []byte
+	err error
+}
+
+// NewDecrypter returns a Decrypter of key reading from r
+func (g *GCrypt) NewDecrypter(r io.Reader, key []byte) *Decrypter {
+	d := new(Decrypter)
+	d.g = g
+	d.r = r
+	d.ks = g.newKeyStream(key, 0)
+
+	return d
+}
+
+func (d *Decrypter) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+
+		d.fill()
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+
+	return n, nil
+}
+
+// fill decrypts the next chunk, and records the error that ends the stream
+func (d *Decrypter) fill() {
+	blockSize := d.g.blockSize()
+	chunk := make([]byte, chunkBlocks*blockSize)
+
+	n, err := io.ReadFull(d.r, chunk)
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		d.err = io.EOF
+	default:
+		d.err = err
+		return
+	}
+
+	if n%blockSize != 0 {
+		d.err = errorutils.NewfWithInner(ErrMalformedCiphertext, "%d trailing bytes in blocks of %d", n%blockSize, blockSize)
+	}
+
+	d.buf = d.g.decryptBlocks(chunk[:n-n%blockSize], d.ks)
+}
+
+// ReaderAt decrypts byte ranges of a ciphertext, reading only their blocks
+type ReaderAt struct {
+	g      *GCrypt
+	r      io.ReaderAt
+	key    []byte
+	blocks int64
+}
+
+// NewReaderAt returns a ReaderAt of key for the ciphertext of size bytes read
+// from r
+func (g *GCrypt) NewReaderAt(r io.ReaderAt, size int64, key []byte) (*ReaderAt, error) {
+	blockSize := int64(g.blockSize())
+	if size < 0 || size%blockSize != 0 {
+		return nil, errorutils.NewfWithInner(ErrMalformedCiphertext, "%d bytes in blocks of %d", size, blockSize)
+	}
+
+	ra := new(ReaderAt)
+	ra.g = g
+	ra.r = r
+	ra.key = key
+	ra.blocks = size / blockSize
+
+	return ra, nil
+}
+
+// Size is the size of the plaintext, padded as Decrypt pads it
+func (ra *ReaderAt) Size() int64 {
+	return (ra.blocks*int64(ra.g.encoding.BitsPerBlock) + 7) / 8
+}
+
+// ReadAt decrypts the plaintext at off, in time proportional to len(p) with
+// the counter key expansion, and to off+len(p) with the chain expansion
+func (ra *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errorutils.Newf("negative offset %d", off)
+	}
+
+	if off >= ra.Size() {
+		return 0, io.EOF
+	}
+
+	end := min(off+int64(len(p)), ra.Size())
+	bits := int64(ra.g.encoding.BitsPerBlock)
+	first := off * 8 / bits
+	last := min((end*8+bits-1)/bits, ra.blocks)
+
+	blockSize := int64(ra.g.blockSize())
+	ciphertext := make([]byte, (last-first)*blockSize)
+	if n, err := ra.r.ReadAt(ciphertext, first*blockSize); n < len(ciphertext) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	// block first starts on a whole byte: at off with less than 8 bits per
+	// block, and at a multiple of its bits otherwise
+	plaintext := ra.g.decryptBlocks(ciphertext, ra.g.newKeyStream(ra.key, int(first)))
+	n := copy(p[:end-off], plaintext[off-first*bits/8:])
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// chunkBytes is the plaintext size of chunkBlocks blocks
+func (g *GCrypt) chunkBytes() int {
+	return chunkBlocks * int(g.encoding.BitsPerBlock) / 8
+}
+
+// decryptBlocks decrypts the whole blocks of ciphertext with the next blocks of ks
+func (g *GCrypt) decryptBlocks(ciphertext []byte, ks *keyStream) []byte {
+	blocks := g.FromBytes(ciphertext)
+	for i := range blocks {
+		blocks[i].Sub(ks.next())
+	}
+
+	return g.Decode(blocks)
+}
@@ -1,6 +1,9 @@
 package ghash
 
 import (
+	"bytes"
+	"encoding/binary"
+
 	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
 	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
 	"github.com/titosilva/pdpr-go/math/uintp"
@@ -10,31 +13,153 @@ import (
 // The size of each block must be the same size as the lthash modulus
 // GHash also must receive a nonce to be used as the error, added to make it one way
 
+// Index and nonce hashing use independent subkeys derived from the key, so that
+// a nonce can never collide with a block index
+
 type GHash struct {
 	// Undeyling Lthash algorithm
-	lthash     *lthash.LtHash
-	nonceHash  []byte
-	nonceState []*uintp.UintP
-	key        []byte
+	lthash      *lthash.LtHash
+	nonceLthash *lthash.LtHash
+	nonceHash   []byte
+	nonceState  []*uintp.UintP
+	key         []byte
+
+	chunk_count      uint
+	chunk_size_bits  uint
+	block_size_bytes int
 }
 
-func New(modulusBitsize uint) *GHash {
+const (
+	RoleIndex lthash.Role = "pdpr-go/ghash/index"
+	RoleNonce lthash.Role = "pdpr-go/ghash/nonce"
+)
+
+func New(modulusBitsize uint) (*GHash, error) {
 	return NewWithParams(512, modulusBitsize, 16, nil)
 }
 
-func NewWithParams(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) *GHash {
+// MustNew is like New, but panics if the modulus is invalid
+func MustNew(modulusBitsize uint) *GHash {
+	return must(New(modulusBitsize))
+}
+
+// NewWithParams creates a GHash whose parameters are checked as by
+// lthash.Params.Check, but whose blocks may have any size
+func NewWithParams(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) (*GHash, error) {
 	r := new(GHash)
-	r.lthash = lthash.New(chunk_count, chunk_size_bits, block_size_bytes, key)
-	r.key = key
+	r.chunk_count = chunk_count
+	r.chunk_size_bits = chunk_size_bits
+	r.block_size_bytes = block_size_bytes
+	if err := r.setKey(key); err != nil {
+		return nil, err
+	}
 
-	return r
+	return r, nil
+}
+
+// MustNewWithParams is like NewWithParams, but panics if the parameters are invalid
+func MustNewWithParams(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) *GHash {
+	return must(NewWithParams(chunk_count, chunk_size_bits, block_size_bytes, key))
+}
+
+func must(hash *GHash, err error) *GHash {
+	if err != nil {
+		panic(err)
+	}
+
+	return hash
 }
 
-func (hash *GHash) SetNonce(nonce []byte) {
+// NewFromParams creates a GHash with the given LtHash parameters, once they are validated
+func NewFromParams(params lthash.Params, key []byte) (*GHash, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return NewWithParams(params.ChunkCount, params.ChunkSizeBits, params.BlockSizeBytes, key)
+}
+
+func (hash *GHash) setKey(key []byte) error {
+	schedule := lthash.NewKeySchedule(key)
+	indexLthash, err := lthash.NewWithSchedule(hash.chunk_count, hash.chunk_size_bits, hash.block_size_bytes, schedule, RoleIndex)
+	if err != nil {
+		return err
+	}
+
+	nonceLthash, err := lthash.NewWithSchedule(hash.chunk_count, hash.chunk_size_bits, hash.block_size_bytes, schedule, RoleNonce)
+	if err != nil {
+		return err
+	}
+
+	hash.lthash = indexLthash
+	hash.nonceLthash = nonceLthash
+	hash.key = key
+
+	return nil
+}
+
+// KeyID returns the identifier of the key used by this hash
+func (hash *GHash) KeyID() lthash.KeyID {
+	return hash.lthash.KeyID()
+}
+
+// Rotate re-keys the hash. As GHash digests cannot be re-keyed on their own,
+// the nonce (nil if none was set) and every block added to the hash must be
+// given; they are checked against the current digest before rotating.
+func (hash *GHash) Rotate(key []byte, nonce []byte, blocks []*uintp.UintP) error {
+	check, err := NewWithParams(hash.chunk_count, hash.chunk_size_bits, hash.block_size_bytes, hash.key)
+	if err != nil {
+		return err
+	}
+
+	if err := check.rebuild(nonce, blocks); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(check.GetDigest(), hash.GetDigest()) {
+		return lthash.ErrSetMismatch
+	}
+
+	if err := hash.setKey(key); err != nil {
+		return err
+	}
+
+	return hash.rebuild(nonce, blocks)
+}
+
+func (hash *GHash) rebuild(nonce []byte, blocks []*uintp.UintP) error {
+	if nonce != nil {
+		if err := hash.SetNonce(nonce); err != nil {
+			return err
+		}
+	} else {
+		hash.lthash.Reset()
+	}
+
+	return hash.AddBlocks(blocks)
+}
+
+func (hash *GHash) hashNonce(nonce []byte) ([]*uintp.UintP, error) {
+	hash.nonceLthash.Reset()
+	if err := hash.nonceLthash.Add(nonce); err != nil {
+		return nil, err
+	}
+
+	return hash.nonceLthash.GetState(), nil
+}
+
+func (hash *GHash) SetNonce(nonce []byte) error {
+	state, err := hash.hashNonce(nonce)
+	if err != nil {
+		return err
+	}
+
 	hash.lthash.Reset()
-	hash.lthash.Add(nonce)
+	hash.lthash.Combine(state)
 	hash.nonceHash = hash.lthash.GetDigest()
 	hash.nonceState = hash.lthash.GetState()
+
+	return nil
 }
 
 func (hash *GHash) SetNonceHash(nonceHash []byte) {
@@ -44,8 +169,14 @@ func (hash *GHash) SetNonceHash(nonceHash []byte) {
 	hash.nonceState = hash.lthash.GetState()
 }
 
-func (hash *GHash) RemoveNonce(nonce []byte) {
-	hash.lthash.Remove(nonce)
+func (hash *GHash) RemoveNonce(nonce []byte) error {
+	state, err := hash.hashNonce(nonce)
+	if err != nil {
+		return err
+	}
+
+	hash.lthash.CombineInverse(state)
+	return nil
 }
 
 func (hash *GHash) RemoveNonceState(nonceState []*uintp.UintP) {
@@ -79,36 +210,51 @@ func (hash *GHash) GetState() []*uintp.UintP {
 	return hash.lthash.GetState()
 }
 
-func (hash *GHash) AddBytes(data []byte) {
-	crypt := gcrypt.New(hash.lthash.ModulusBitsize)
-	blocks := crypt.FromBytes(data)
-	hash.AddBlocks(blocks)
+// blocksOf splits data in blocks of the size of the modulus, which was checked when the hash was created
+func (hash *GHash) blocksOf(data []byte) []*uintp.UintP {
+	return gcrypt.MustNew(hash.lthash.ModulusBitsize).FromBytes(data)
+}
+
+func (hash *GHash) AddBytes(data []byte) error {
+	return hash.AddBlocks(hash.blocksOf(data))
 }
 
-func (hash *GHash) AddBlocks(blocks []*uintp.UintP) {
+func (hash *GHash) AddBlocks(blocks []*uintp.UintP) error {
 	for i := 0; i < len(blocks); i++ {
-		hash.AddBlockWithIndex(blocks[i], uint(i))
+		if err := hash.AddBlockWithIndex(blocks[i], uint(i)); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
-func (hash *GHash) RemoveBytes(data []byte) {
-	crypt := gcrypt.New(hash.lthash.ModulusBitsize)
-	blocks := crypt.FromBytes(data)
-	hash.RemoveBlocks(blocks)
+func (hash *GHash) RemoveBytes(data []byte) error {
+	return hash.RemoveBlocks(hash.blocksOf(data))
 }
 
-func (hash *GHash) RemoveBlocks(blocks []*uintp.UintP) {
+func (hash *GHash) RemoveBlocks(blocks []*uintp.UintP) error {
 	for i := 0; i < len(blocks); i++ {
-		hash.RemoveBlockWithIndex(blocks[i], uint(i))
+		if err := hash.RemoveBlockWithIndex(blocks[i], uint(i)); err != nil {
+			return err
+		}
 	}
+
+	return nil
+}
+
+func (hash *GHash) AddBlockWithIndex(block *uintp.UintP, index uint) error {
+	return hash.lthash.AddMul(block, indexBytes(index))
 }
 
-func (hash *GHash) AddBlockWithIndex(block *uintp.UintP, index uint) {
-	hash.lthash.AddMul(block, []byte{byte(index)})
+func (hash *GHash) RemoveBlockWithIndex(block *uintp.UintP, index uint) error {
+	return hash.lthash.RemoveMul(block, indexBytes(index))
 }
 
-func (hash *GHash) RemoveBlockWithIndex(block *uintp.UintP, index uint) {
-	hash.lthash.RemoveMul(block, []byte{byte(index)})
+// indexBytes encodes an index in 8 bytes big-endian, so distinct indices are
+// never hashed from the same bytes
+func indexBytes(index uint) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(index))
 }
 
 func (hash GHash) GetDigest() []byte {
@@ -7,7 +7,7 @@ import (
 )
 
 func runBenchmark(b *testing.B, indexCount int, chunkCount uint, chunkSize uint) {
-	g := ghash.NewWithParams(chunkCount, chunkSize, 64, nil)
+	g := ghash.MustNewWithParams(chunkCount, chunkSize, 64, nil)
 	bs, err := generateRandomBytes(indexCount * int(chunkSize) / 8)
 	b.ResetTimer()
 
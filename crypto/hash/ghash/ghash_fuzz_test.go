@@ -0,0 +1,71 @@
+package ghash_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/crypto/hash/ghash"
+)
+
+// maxFuzzBlocks bounds the data, as every block is hashed separately
+const maxFuzzBlocks = 64
+
+func fuzzHash(size byte, key []byte) *ghash.GHash {
+	return ghash.MustNewWithParams(1+uint(size%8), 64*(1+uint(size/8%4)), 16, key)
+}
+
+func FuzzGHash__NonceAndState__ShouldRoundTrip(f *testing.F) {
+	f.Add(byte(0), []byte("This is a nonce"), []byte("Hello, World!"), []byte("old key"), []byte("new key"))
+	f.Add(byte(9), []byte{}, []byte{}, []byte{}, []byte{0})
+	f.Add(byte(31), bytes.Repeat([]byte{0xff}, 40), bytes.Repeat([]byte{0xff}, 64), []byte{1}, []byte{1})
+
+	f.Fuzz(func(t *testing.T, size byte, nonce []byte, data []byte, oldKey []byte, newKey []byte) {
+		hash := fuzzHash(size, oldKey)
+		crypt := gcrypt.MustNew(uint64(64 * (1 + uint(size/8%4))))
+		blocks := crypt.FromBytes(data)
+		blocks = blocks[:min(len(blocks), maxFuzzBlocks)]
+
+		if err := hash.SetNonce(nonce); err != nil {
+			t.Fatal(err)
+		}
+
+		fromState := fuzzHash(size, oldKey)
+		fromState.SetNonceState(hash.GetNonceState())
+		fromHash := fuzzHash(size, oldKey)
+		fromHash.SetNonceHash(hash.GetNonceHash())
+
+		if !bytes.Equal(fromState.GetDigest(), hash.GetDigest()) || !bytes.Equal(fromHash.GetDigest(), hash.GetDigest()) {
+			t.Fatal("nonce state or hash does not round trip")
+		}
+
+		hash.AddBlocks(blocks)
+		withData := hash.GetDigest()
+		hash.RemoveBlocks(blocks)
+		if !bytes.Equal(hash.GetDigest(), hash.GetNonceHash()) {
+			t.Fatal("removing blocks does not undo adding them")
+		}
+
+		hash.RemoveNonce(nonce)
+		if !bytes.Equal(hash.GetDigest(), fuzzHash(size, oldKey).GetDigest()) {
+			t.Fatal("removing the nonce does not yield the empty digest")
+		}
+
+		hash.SetNonce(nonce)
+		hash.AddBlocks(blocks)
+		if !bytes.Equal(hash.GetDigest(), withData) {
+			t.Fatal("hashing is not deterministic")
+		}
+
+		expected := fuzzHash(size, newKey)
+		expected.SetNonce(nonce)
+		expected.AddBlocks(blocks)
+		if err := hash.Rotate(newKey, nonce, blocks); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(hash.GetDigest(), expected.GetDigest()) {
+			t.Fatal("rotating does not yield the hash with the new key")
+		}
+	})
+}
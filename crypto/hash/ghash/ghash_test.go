@@ -1,10 +1,13 @@
 package ghash_test
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
 	"github.com/titosilva/pdpr-go/crypto/hash/ghash"
+	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
 	"github.com/titosilva/pdpr-go/crypto/random"
 	"github.com/titosilva/pdpr-go/internal/ez"
 	"github.com/titosilva/pdpr-go/math/uintp"
@@ -16,12 +19,12 @@ func Test__GHash__AddThenRemove__ShouldEqual__Original(t *testing.T) {
 	data, _ := random.GenerateBytes(64)
 	base, _ := random.GenerateBytes(64)
 
-	hash := ghash.NewWithParams(1, 64, 128, nil)
+	hash := ghash.MustNewWithParams(1, 64, 128, nil)
 	hash.AddBytes(base)
 	hash.AddBytes(data)
 	hash.RemoveBytes(data)
 
-	baseHash := ghash.NewWithParams(1, 64, 128, nil)
+	baseHash := ghash.MustNewWithParams(1, 64, 128, nil)
 	baseHash.AddBytes(base)
 
 	ez.AssertAreEqual(hash.GetDigest(), baseHash.GetDigest())
@@ -29,15 +32,15 @@ func Test__GHash__AddThenRemove__ShouldEqual__Original(t *testing.T) {
 
 func Test__GHash__AddThenRemove__ShouldEqual__Original__WithGCryptoMethodsWithoutByteConversion(t *testing.T) {
 	ez := ez.New(t)
-	crypt := gcrypt.New(64)
+	crypt := gcrypt.MustNew(64)
 
 	rnd, _ := random.GenerateBytes(1)
-	encData := crypt.Encode(rnd)
+	encData := crypt.MustEncode(rnd)
 
 	rnd, _ = random.GenerateBytes(1)
 	encKey := crypt.ExpandKey(rnd, len(encData))
 
-	hash := ghash.NewWithParams(1, 64, 128, nil)
+	hash := ghash.MustNewWithParams(1, 64, 128, nil)
 	hash.AddBlocks(encKey)
 	hash.AddBlocks(encData)
 
@@ -46,7 +49,7 @@ func Test__GHash__AddThenRemove__ShouldEqual__Original__WithGCryptoMethodsWithou
 		encrypted[i] = uintp.Clone(encData[i]).Add(encKey[i])
 	}
 
-	encHash := ghash.NewWithParams(1, 64, 128, nil)
+	encHash := ghash.MustNewWithParams(1, 64, 128, nil)
 	encHash.AddBlocks(encrypted)
 
 	ez.AssertAreEqual(hash.GetDigest(), encHash.GetDigest())
@@ -54,11 +57,11 @@ func Test__GHash__AddThenRemove__ShouldEqual__Original__WithGCryptoMethodsWithou
 
 func Test__GHash__AddBytesAndAddBlocks__ShouldEqual(t *testing.T) {
 	ez := ez.New(t)
-	crypt := gcrypt.New(64)
+	crypt := gcrypt.MustNew(64)
 
 	rnd, _ := random.GenerateBytes(1)
-	data := crypt.EncodeToBytes(rnd)
-	encData := crypt.Encode(rnd)
+	data := crypt.ToBytes(crypt.MustEncode(rnd))
+	encData := crypt.MustEncode(rnd)
 	expData := crypt.FromBytes(data)
 	ez.AssertAreEqual(expData, encData)
 
@@ -68,11 +71,11 @@ func Test__GHash__AddBytesAndAddBlocks__ShouldEqual(t *testing.T) {
 	expKey := crypt.FromBytes(key)
 	ez.AssertAreEqual(expKey, encKey)
 
-	hash := ghash.NewWithParams(1, 64, 128, nil)
+	hash := ghash.MustNewWithParams(1, 64, 128, nil)
 	hash.AddBytes(key)
 	hash.AddBytes(data)
 
-	blockHash := ghash.NewWithParams(1, 64, 128, nil)
+	blockHash := ghash.MustNewWithParams(1, 64, 128, nil)
 	blockHash.AddBlocks(encKey)
 	blockHash.AddBlocks(encData)
 
@@ -81,11 +84,11 @@ func Test__GHash__AddBytesAndAddBlocks__ShouldEqual(t *testing.T) {
 
 func Test__GHash__AddThenRemove__ShouldEqual__Original__WithGCryptoMethodsWithByteConversions(t *testing.T) {
 	ez := ez.New(t)
-	crypt := gcrypt.New(64)
+	crypt := gcrypt.MustNew(64)
 
 	rnd, _ := random.GenerateBytes(1)
-	data := crypt.EncodeToBytes(rnd)
-	encData := crypt.Encode(rnd)
+	data := crypt.ToBytes(crypt.MustEncode(rnd))
+	encData := crypt.MustEncode(rnd)
 	expData := crypt.FromBytes(data)
 	ez.AssertAreEqual(expData, encData)
 
@@ -95,7 +98,7 @@ func Test__GHash__AddThenRemove__ShouldEqual__Original__WithGCryptoMethodsWithBy
 	expKey := crypt.FromBytes(key)
 	ez.AssertAreEqual(expKey, encKey)
 
-	hash := ghash.NewWithParams(1, 64, 128, nil)
+	hash := ghash.MustNewWithParams(1, 64, 128, nil)
 	hash.AddBytes(key)
 	hash.AddBytes(data)
 
@@ -104,7 +107,7 @@ func Test__GHash__AddThenRemove__ShouldEqual__Original__WithGCryptoMethodsWithBy
 		encrypted[i] = uintp.Clone(encData[i]).Add(encKey[i])
 	}
 
-	encHash := ghash.NewWithParams(1, 64, 128, nil)
+	encHash := ghash.MustNewWithParams(1, 64, 128, nil)
 	encHash.AddBlocks(encrypted)
 
 	ez.AssertAreEqual(hash.GetDigest(), encHash.GetDigest())
@@ -115,17 +118,17 @@ func Test__GHashOverGCrypto(t *testing.T) {
 	data := []byte("Hello, World!")
 	key := []byte("This is a key")
 
-	crypt := gcrypt.New(64)
+	crypt := gcrypt.MustNew(64)
 
-	dataHash := ghash.NewWithParams(1, 64, 128, nil)
-	encodedData := crypt.Encode(data)
-	encodedDataBytes := crypt.EncodeToBytes(data)
+	dataHash := ghash.MustNewWithParams(1, 64, 128, nil)
+	encodedData := crypt.MustEncode(data)
+	encodedDataBytes := crypt.ToBytes(crypt.MustEncode(data))
 	dataHash.AddBytes(encodedDataBytes)
 	dataDigest := dataHash.GetDigest()
 
-	encrypted := crypt.Encrypt(data, key)
+	encrypted := crypt.MustEncrypt(data, key)
 
-	encryptedHash := ghash.NewWithParams(1, 64, 128, nil)
+	encryptedHash := ghash.MustNewWithParams(1, 64, 128, nil)
 	encryptedHash.AddBytes(encrypted)
 	encodedKey := crypt.ExpandKeyToBytes(key, len(encodedData))
 	encryptedHash.RemoveBytes(encodedKey)
@@ -141,27 +144,28 @@ func Test__GHashOverGCrypto__WithNonces(t *testing.T) {
 	data := []byte("Hello, World!")
 	key := []byte("This is a key")
 
-	crypt := gcrypt.New(64)
+	crypt := gcrypt.MustNew(64)
 
-	dataHash := ghash.NewWithParams(1, 64, 128, nil)
-	dataHash.SetNonce([]byte("This is a nonce"))
+	dataHash := ghash.MustNewWithParams(1, 64, 128, nil)
+	// the nonce of a fresh challenge, chosen by the verifier
+	dataHash.SetNonce(challengeNonce(crypt.BlockCount(len(data))))
 	dataNonceState := dataHash.GetNonceState()
-	encodedData := crypt.Encode(data)
-	encodedDataBytes := crypt.EncodeToBytes(data)
+	encodedData := crypt.MustEncode(data)
+	encodedDataBytes := crypt.ToBytes(crypt.MustEncode(data))
 	dataHash.AddBytes(encodedDataBytes)
 	dataDigest := dataHash.GetDigest()
 
-	keyHash := ghash.NewWithParams(1, 64, 128, nil)
+	keyHash := ghash.MustNewWithParams(1, 64, 128, nil)
 	keyHash.SetNonce([]byte("This is a key nonce"))
 	keyNonceState := keyHash.GetNonceState()
 
-	encrypted := crypt.Encrypt(data, key)
+	encrypted := crypt.MustEncrypt(data, key)
 
 	for i := 0; i < len(dataNonceState); i++ {
 		dataNonceState[i].Add(keyNonceState[i])
 	}
 
-	encryptedHash := ghash.NewWithParams(1, 64, 128, nil)
+	encryptedHash := ghash.MustNewWithParams(1, 64, 128, nil)
 	encryptedHash.SetNonceState(dataNonceState)
 	encryptedHash.AddBytes(encrypted)
 	encodedKey := crypt.ExpandKeyToBytes(key, len(encodedData))
@@ -173,3 +177,63 @@ func Test__GHashOverGCrypto__WithNonces(t *testing.T) {
 	ez.AssertAreEqual(crypt.Decrypt(encrypted, key), data)
 	ez.AssertAreEqual(dataDigest, recoveredHash)
 }
+
+func Test__GHash__Rotate__ShouldEqual__HashWithNewKey(t *testing.T) {
+	ez := ez.New(t)
+	crypt := gcrypt.MustNew(64)
+	blocks := crypt.MustEncode([]byte("Hello, World!"))
+	nonce := []byte("This is a nonce")
+
+	hash := ghash.MustNewWithParams(4, 64, 128, []byte("old key"))
+	hash.SetNonce(nonce)
+	hash.AddBlocks(blocks)
+
+	expected := ghash.MustNewWithParams(4, 64, 128, []byte("new key"))
+	expected.SetNonce(nonce)
+	expected.AddBlocks(blocks)
+
+	ez.AssertNoError(hash.Rotate([]byte("new key"), nonce, blocks))
+	ez.AssertAreEqual(hash.GetDigest(), expected.GetDigest())
+	ez.Assert(hash.KeyID() == expected.KeyID())
+
+	err := hash.Rotate([]byte("old key"), []byte("another nonce"), blocks)
+	ez.Assert(errors.Is(err, lthash.ErrSetMismatch))
+}
+
+func Test__GHash__SetNonceHash__ShouldEqual__SetNonce(t *testing.T) {
+	ez := ez.New(t)
+
+	hash := ghash.MustNewWithParams(8, 128, 16, nil)
+	hash.SetNonce([]byte("This is a nonce"))
+
+	other := ghash.MustNewWithParams(8, 128, 16, nil)
+	other.SetNonceHash(hash.GetNonceHash())
+
+	ez.AssertAreEqual(other.GetDigest(), hash.GetDigest())
+}
+
+func Test__GHash__NewFromParams__ShouldReject__InsecureParams(t *testing.T) {
+	ez := ez.New(t)
+
+	_, err := ghash.NewFromParams(lthash.Params{ChunkCount: 8, ChunkSizeBits: 64, BlockSizeBytes: 8}, nil)
+	ez.Assert(errors.Is(err, lthash.ErrInsecureParams))
+
+	hash, err := ghash.NewFromParams(lthash.Level128, []byte("key"))
+	ez.AssertNoError(err)
+	hash.AddBytes([]byte("Hello, World!"))
+	ez.AssertAreEqual(len(hash.GetDigest()), 1024*128/8)
+}
+
+func Test__GHash__AddBlockWithIndex__ShouldNotCollide__ForIndicesThatShareTheirLowByte(t *testing.T) {
+	ez := ez.New(t)
+
+	block := uintp.MustFromBytes(64, []byte{0, 0, 0, 0, 0, 0, 0, 1})
+
+	hash := ghash.MustNewWithParams(8, 64, 8, nil)
+	hash.AddBlockWithIndex(block, 1)
+
+	other := ghash.MustNewWithParams(8, 64, 8, nil)
+	other.AddBlockWithIndex(block, 257)
+
+	ez.AssertFalse(bytes.Equal(hash.GetDigest(), other.GetDigest()))
+}
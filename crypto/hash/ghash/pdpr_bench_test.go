@@ -5,10 +5,12 @@ import (
 
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
 	"github.com/titosilva/pdpr-go/crypto/hash/ghash"
 	"github.com/titosilva/pdpr-go/math/uintp"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
 )
 
 func generateRandomBytes(size int) ([]byte, error) {
@@ -23,20 +25,31 @@ func generateRandomBytes(size int) ([]byte, error) {
 	return bytes, nil
 }
 
+// challengeNonce returns the nonce of a fresh challenge of blockCount blocks,
+// chosen at random by the verifier
+func challengeNonce(blockCount int) []byte {
+	challenge, err := freshness.NewVerifier(time.Minute).Issue("bench", uint64(blockCount), 1, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	return challenge.Nonce
+}
+
 func encrypt(data []byte, key []byte, modulusBitsize uint64, block_size_bits int) ([]byte, []*uintp.UintP, []byte) {
-	crypt := gcrypt.New(modulusBitsize)
+	crypt := gcrypt.MustNew(modulusBitsize)
 
-	dataHash := ghash.NewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
-	dataHash.SetNonce([]byte("This is a nonce"))
+	dataHash := ghash.MustNewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
+	dataHash.SetNonce(challengeNonce(crypt.BlockCount(len(data))))
 	dataNonceState := dataHash.GetNonceState()
-	encodedDataBytes := crypt.EncodeToBytes(data)
+	encodedDataBytes := crypt.ToBytes(crypt.MustEncode(data))
 	dataHash.AddBytes(encodedDataBytes)
 
-	keyHash := ghash.NewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
+	keyHash := ghash.MustNewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
 	keyHash.SetNonce([]byte("This is a key nonce"))
 	keyNonceState := keyHash.GetNonceState()
 
-	encrypted := crypt.Encrypt(data, key)
+	encrypted := crypt.MustEncrypt(data, key)
 
 	for i := 0; i < len(dataNonceState); i++ {
 		dataNonceState[i].Add(keyNonceState[i])
@@ -46,7 +59,7 @@ func encrypt(data []byte, key []byte, modulusBitsize uint64, block_size_bits int
 }
 
 func generateProof(encrypted []byte, encryptedNonceState []*uintp.UintP, modulusBitsize uint64, block_size_bits int) []*uintp.UintP {
-	encryptedHash := ghash.NewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
+	encryptedHash := ghash.MustNewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
 	encryptedHash.SetNonceState(encryptedNonceState)
 	encryptedHash.AddBytes(encrypted)
 
@@ -54,42 +67,42 @@ func generateProof(encrypted []byte, encryptedNonceState []*uintp.UintP, modulus
 }
 
 func verifyProof(encryptedHashState []*uintp.UintP, encrypted []byte, key []byte, modulusBitsize uint64, block_size_bits int) {
-	crypt := gcrypt.New(modulusBitsize)
+	crypt := gcrypt.MustNew(modulusBitsize)
 	encodedKey := crypt.ExpandKeyToBytes(key, len(encrypted))
 
-	encryptedHashObj := ghash.NewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
+	encryptedHashObj := ghash.MustNewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
 	encryptedHashObj.SetNonceState(encryptedHashState)
 	encryptedHashObj.RemoveBytes(encodedKey)
 	encryptedHashObj.RemoveNonce([]byte("This is a key nonce"))
 }
 
 func decrypt(encrypted []byte, key []byte, modulusBitsize uint64) []byte {
-	crypt := gcrypt.New(modulusBitsize)
+	crypt := gcrypt.MustNew(modulusBitsize)
 	return crypt.Decrypt(encrypted, key)
 }
 
 func runPdpr(data []byte, key []byte, modulusBitsize uint64, block_size_bits int) {
-	crypt := gcrypt.New(modulusBitsize)
+	crypt := gcrypt.MustNew(modulusBitsize)
 
-	dataHash := ghash.NewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
-	dataHash.SetNonce([]byte("This is a nonce"))
+	dataHash := ghash.MustNewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
+	dataHash.SetNonce(challengeNonce(crypt.BlockCount(len(data))))
 	dataNonceState := dataHash.GetNonceState()
-	encodedData := crypt.Encode(data)
-	encodedDataBytes := crypt.EncodeToBytes(data)
+	encodedData := crypt.MustEncode(data)
+	encodedDataBytes := crypt.ToBytes(crypt.MustEncode(data))
 	dataHash.AddBytes(encodedDataBytes)
 	dataDigest := dataHash.GetDigest()
 
-	keyHash := ghash.NewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
+	keyHash := ghash.MustNewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
 	keyHash.SetNonce([]byte("This is a key nonce"))
 	keyNonceState := keyHash.GetNonceState()
 
-	encrypted := crypt.Encrypt(data, key)
+	encrypted := crypt.MustEncrypt(data, key)
 
 	for i := 0; i < len(dataNonceState); i++ {
 		dataNonceState[i].Add(keyNonceState[i])
 	}
 
-	encryptedHash := ghash.NewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
+	encryptedHash := ghash.MustNewWithParams(500, uint(modulusBitsize), block_size_bits/8, nil)
 	encryptedHash.SetNonceState(dataNonceState)
 	encryptedHash.AddBytes(encrypted)
 	encodedKey := crypt.ExpandKeyToBytes(key, len(encodedData))
@@ -0,0 +1,63 @@
+package lthash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// KeySchedule derives independent subkeys for each role an LtHash is used for
+// (e.g. index hashing and nonce hashing in GHash) from a single master key.
+// The master key may have any length, including zero.
+type KeySchedule struct {
+	master []byte
+	salt   []byte
+}
+
+// KeyID identifies a master key without revealing it
+type KeyID string
+
+// Role labels a use of the LtHash, and is used as the HKDF info parameter
+type Role string
+
+const (
+	RoleData Role = "pdpr-go/lthash/data"
+	roleID   Role = "pdpr-go/lthash/key-id"
+)
+
+const subkeySize = 32
+const keyIDSize = 8
+
+var scheduleSalt = []byte("pdpr-go/lthash/key-schedule/v1")
+
+func NewKeySchedule(master []byte) *KeySchedule {
+	r := new(KeySchedule)
+	r.master = append([]byte{}, master...)
+	r.salt = scheduleSalt
+
+	return r
+}
+
+// Derive returns the subkey to be used by the XOF for the given role
+func (ks *KeySchedule) Derive(role Role) []byte {
+	return ks.expand(role, subkeySize)
+}
+
+// ID returns the identifier of the master key of this schedule
+func (ks *KeySchedule) ID() KeyID {
+	return KeyID(hex.EncodeToString(ks.expand(roleID, keyIDSize)))
+}
+
+func (ks *KeySchedule) expand(role Role, size int) []byte {
+	kdf := hkdf.New(sha256.New, ks.master, ks.salt, []byte(role))
+
+	r := make([]byte, size)
+	if _, err := io.ReadFull(kdf, r); err != nil {
+		// hkdf only fails when more than 255 hash blocks are requested
+		panic(err)
+	}
+
+	return r
+}
@@ -1,7 +1,10 @@
 package lthash
 
 import (
+	"errors"
+
 	"github.com/titosilva/pdpr-go/internal/collections/structures/list"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
 	"github.com/titosilva/pdpr-go/math/uintp"
 
 	"golang.org/x/crypto/blake2b"
@@ -15,42 +18,108 @@ type LtHash struct {
 	block_size_bytes int
 	xof              blake2b.XOF
 	chunk_buf        []byte
+	keyID            KeyID
 }
 
 func getChunksWithZero(chunk_bits uint, chunk_count uint) []*uintp.UintP {
 	chunks := make([]*uintp.UintP, chunk_count)
 
 	for i := range chunks {
-		chunks[i] = uintp.FromUint(uint64(chunk_bits), 0)
+		chunks[i] = uintp.MustFromUint(uint64(chunk_bits), 0)
 	}
 
 	return chunks
 }
 
-func New(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) *LtHash {
-	xof, err := blake2b.NewXOF(uint32(chunk_count*chunk_size_bits), key)
+var ErrSetMismatch = errors.New("the given set does not match the current digest")
+
+func newXof(chunk_count uint, chunk_size_bits uint, xofKey []byte) (blake2b.XOF, error) {
+	xof, err := blake2b.NewXOF(uint32(chunk_count*chunk_size_bits), xofKey)
 	if err != nil {
-		panic(err)
+		return nil, errorutils.NewWithInner(err, "could not create the XOF")
+	}
+
+	return xof, nil
+}
+
+// scheduleFor returns the key schedule for a raw key, or nil if the hash must be unkeyed
+func scheduleFor(key []byte) *KeySchedule {
+	if key == nil {
+		return nil
+	}
+
+	return NewKeySchedule(key)
+}
+
+func keyFor(schedule *KeySchedule, role Role) ([]byte, KeyID) {
+	if schedule == nil {
+		return nil, ""
+	}
+
+	return schedule.Derive(role), schedule.ID()
+}
+
+// New creates an LtHash keyed by the RoleData subkey derived from key.
+// A nil key yields an unkeyed hash.
+// The parameters are checked as by Params.Check, but blocks may have any size,
+// and they are not checked by Params.Validate
+func New(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) (*LtHash, error) {
+	return NewWithSchedule(chunk_count, chunk_size_bits, block_size_bytes, scheduleFor(key), RoleData)
+}
+
+// MustNew is like New, but panics if the parameters are invalid
+func MustNew(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) *LtHash {
+	return must(New(chunk_count, chunk_size_bits, block_size_bytes, key))
+}
+
+// NewWithSchedule creates an LtHash keyed by the subkey derived for role.
+// A nil schedule yields an unkeyed hash.
+func NewWithSchedule(chunk_count uint, chunk_size_bits uint, block_size_bytes int, schedule *KeySchedule, role Role) (*LtHash, error) {
+	hash, err := newDirect(chunk_count, chunk_size_bits, block_size_bytes, schedule, role)
+	if err != nil {
+		return nil, err
 	}
 
 	r := new(LtHash)
-	r.chunks = getChunksWithZero(chunk_size_bits, chunk_count)
-	r.chunk_count = chunk_count
-	r.chunk_size_bits = chunk_size_bits
-	r.block_size_bytes = block_size_bytes
-	r.ModulusBitsize = uint64(chunk_size_bits)
-	r.xof = xof
-	r.chunk_buf = make([]byte, chunk_size_bits/8)
+	*r = hash
 
-	return r
+	return r, nil
+}
+
+// MustNewWithSchedule is like NewWithSchedule, but panics if the parameters are invalid
+func MustNewWithSchedule(chunk_count uint, chunk_size_bits uint, block_size_bytes int, schedule *KeySchedule, role Role) *LtHash {
+	return must(NewWithSchedule(chunk_count, chunk_size_bits, block_size_bytes, schedule, role))
+}
+
+func NewDirect(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) (LtHash, error) {
+	return newDirect(chunk_count, chunk_size_bits, block_size_bytes, scheduleFor(key), RoleData)
+}
+
+// MustNewDirect is like NewDirect, but panics if the parameters are invalid
+func MustNewDirect(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) LtHash {
+	return *must(NewWithSchedule(chunk_count, chunk_size_bits, block_size_bytes, scheduleFor(key), RoleData))
 }
 
-func NewDirect(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key []byte) LtHash {
-	xof, err := blake2b.NewXOF(uint32(chunk_count*chunk_size_bits), key)
+func must(hash *LtHash, err error) *LtHash {
 	if err != nil {
 		panic(err)
 	}
 
+	return hash
+}
+
+func newDirect(chunk_count uint, chunk_size_bits uint, block_size_bytes int, schedule *KeySchedule, role Role) (LtHash, error) {
+	params := Params{ChunkCount: chunk_count, ChunkSizeBits: chunk_size_bits, BlockSizeBytes: block_size_bytes}
+	if err := params.check(); err != nil {
+		return LtHash{}, err
+	}
+
+	xofKey, keyID := keyFor(schedule, role)
+	xof, err := newXof(chunk_count, chunk_size_bits, xofKey)
+	if err != nil {
+		return LtHash{}, err
+	}
+
 	return LtHash{
 		chunks:           getChunksWithZero(chunk_size_bits, chunk_count),
 		chunk_count:      chunk_count,
@@ -59,84 +128,139 @@ func NewDirect(chunk_count uint, chunk_size_bits uint, block_size_bytes int, key
 		ModulusBitsize:   uint64(chunk_size_bits),
 		xof:              xof,
 		chunk_buf:        make([]byte, chunk_size_bits/8),
+		keyID:            keyID,
+	}, nil
+}
+
+// KeyID returns the identifier of the key used by this hash, or an empty id if it is unkeyed
+func (hash LtHash) KeyID() KeyID {
+	return hash.keyID
+}
+
+// Rekey switches the hash to the subkey derived for role by schedule.
+// Since a digest cannot be re-keyed without its elements, set must hold
+// every element that was added to the hash; the digest is recomputed from it
+// after checking that it matches the current digest.
+func (hash *LtHash) Rekey(schedule *KeySchedule, role Role, set [][]byte) error {
+	check, err := newDirect(hash.chunk_count, hash.chunk_size_bits, hash.block_size_bytes, nil, role)
+	if err != nil {
+		return err
+	}
+
+	check.xof = hash.xof
+	for _, element := range set {
+		if err := check.Add(element); err != nil {
+			return err
+		}
+	}
+
+	for i := range hash.chunks {
+		if !hash.chunks[i].Equals(check.chunks[i]) {
+			return ErrSetMismatch
+		}
 	}
+
+	xofKey, keyID := keyFor(schedule, role)
+	xof, err := newXof(hash.chunk_count, hash.chunk_size_bits, xofKey)
+	if err != nil {
+		return err
+	}
+
+	hash.xof = xof
+	hash.keyID = keyID
+	hash.Reset()
+	for _, element := range set {
+		if err := hash.Add(element); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (hash *LtHash) Reset() {
 	hash.chunks = getChunksWithZero(hash.chunk_size_bits, hash.chunk_count)
 }
 
-func (hash LtHash) randomizeThenCombine(bytes []byte) {
+func (hash LtHash) randomizeThenCombine(bytes []byte) error {
 	hash.xof.Reset()
 	hash.xof.Write(bytes)
 
 	for i := range hash.chunks {
-		_, err := hash.xof.Read(hash.chunk_buf)
-		if err != nil {
-			panic(err)
+		if _, err := hash.xof.Read(hash.chunk_buf); err != nil {
+			return errorutils.NewWithInner(err, "could not read the XOF")
 		}
 
-		to_add := uintp.FromBytes(uint64(hash.chunk_size_bits), hash.chunk_buf)
+		to_add := uintp.MustFromBytes(uint64(hash.chunk_size_bits), hash.chunk_buf)
 		hash.chunks[i].Add(to_add)
 	}
+
+	return nil
 }
 
-func (hash LtHash) randomizeThenCombineInverse(bytes []byte) {
+func (hash LtHash) randomizeThenCombineInverse(bytes []byte) error {
 	hash.xof.Reset()
 	hash.xof.Write(bytes)
 
 	for i := range hash.chunks {
-		_, err := hash.xof.Read(hash.chunk_buf)
-		if err != nil {
-			panic(err)
+		if _, err := hash.xof.Read(hash.chunk_buf); err != nil {
+			return errorutils.NewWithInner(err, "could not read the XOF")
 		}
 
 		hash.chunks[i].SubBytes(hash.chunk_buf)
 	}
+
+	return nil
 }
 
-func (hash LtHash) randomizeThenCombineMul(mul *uintp.UintP, bytes []byte) {
+func (hash LtHash) randomizeThenCombineMul(mul *uintp.UintP, bytes []byte) error {
 	hash.xof.Reset()
 	hash.xof.Write(bytes)
 
 	for i := range hash.chunks {
-		_, err := hash.xof.Read(hash.chunk_buf)
-		if err != nil {
-			panic(err)
+		if _, err := hash.xof.Read(hash.chunk_buf); err != nil {
+			return errorutils.NewWithInner(err, "could not read the XOF")
 		}
 
-		to_add := uintp.FromBytes(uint64(hash.chunk_size_bits), hash.chunk_buf)
+		to_add := uintp.MustFromBytes(uint64(hash.chunk_size_bits), hash.chunk_buf)
 		to_add.Mul(mul)
 		hash.chunks[i].Add(to_add)
 	}
+
+	return nil
 }
 
-func (hash *LtHash) Add(bytes []byte) {
-	hash.randomizeThenCombine(bytes)
+func (hash *LtHash) Add(bytes []byte) error {
+	return hash.randomizeThenCombine(bytes)
 }
 
-func (hash *LtHash) AddMul(mul *uintp.UintP, bytes []byte) {
-	hash.randomizeThenCombineMul(mul, bytes)
+func (hash *LtHash) AddMul(mul *uintp.UintP, bytes []byte) error {
+	return hash.randomizeThenCombineMul(mul, bytes)
 }
 
-func (hash *LtHash) Remove(bytes []byte) {
-	hash.randomizeThenCombineInverse(bytes)
+func (hash *LtHash) Remove(bytes []byte) error {
+	return hash.randomizeThenCombineInverse(bytes)
 }
 
-func (hash *LtHash) RemoveMul(mul *uintp.UintP, bytes []byte) {
-	hash.randomizeThenCombineMul(mul.Inverse(), bytes)
+func (hash *LtHash) RemoveMul(mul *uintp.UintP, bytes []byte) error {
+	return hash.randomizeThenCombineMul(mul.Inverse(), bytes)
 }
 
-func (hash *LtHash) ComputeDigest(bytes []byte) {
+func (hash *LtHash) ComputeDigest(bytes []byte) error {
 	offset := 0
 
 	l := list.NewFrom(bytes)
 
 	for offset < len(bytes) {
 		part := l.Skip(offset).Take(hash.block_size_bytes).ToArray()
-		hash.Add(part)
+		if err := hash.Add(part); err != nil {
+			return err
+		}
+
 		offset += len(part)
 	}
+
+	return nil
 }
 
 func (hash LtHash) GetDigest() []byte {
@@ -168,9 +292,17 @@ func (hash *LtHash) Combine(state []*uintp.UintP) {
 func (hash *LtHash) CombineBytes(state []byte) {
 	toCombine := make([]*uintp.UintP, hash.chunk_count)
 
-	for i := 0; i < len(state); i += int(hash.ModulusBitsize / 8) {
-		block := state[i : i+int(hash.ModulusBitsize)]
-		toCombine[i/int(hash.ModulusBitsize/8)] = uintp.FromBytes(hash.ModulusBitsize, block)
+	chunkBytes := int(hash.ModulusBitsize / 8)
+
+	for i := 0; i+chunkBytes <= len(state) && i/chunkBytes < len(toCombine); i += chunkBytes {
+		block := state[i : i+chunkBytes]
+		toCombine[i/chunkBytes] = uintp.MustFromBytes(hash.ModulusBitsize, block)
+	}
+
+	for i := range toCombine {
+		if toCombine[i] == nil {
+			toCombine[i] = uintp.MustNew(hash.ModulusBitsize)
+		}
 	}
 
 	hash.Combine(toCombine)
@@ -21,7 +21,7 @@ func generateRandomBytes(size int) ([]byte, error) {
 }
 
 func runBenchmark(b *testing.B, sizeOfFile int, blockSize int, chunkCount uint, chunkSize uint) {
-	lt := lthash.NewDirect(chunkCount, chunkSize, blockSize, nil)
+	lt := lthash.MustNewDirect(chunkCount, chunkSize, blockSize, nil)
 	bs, err := generateRandomBytes(sizeOfFile)
 	b.ResetTimer()
 
@@ -0,0 +1,59 @@
+package lthash_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
+	"github.com/titosilva/pdpr-go/math/uintp"
+)
+
+func fuzzHash(size byte, key []byte) *lthash.LtHash {
+	return lthash.MustNew(1+uint(size%16), 64*(1+uint(size/16%4)), 16, key)
+}
+
+func FuzzLtHash__AddThenRemove__ShouldBeInvertible(f *testing.F) {
+	f.Add(byte(0), []byte("a"), []byte("b"), []byte("c"), []byte{})
+	f.Add(byte(17), []byte{}, []byte{}, []byte{0}, []byte("This is a key"))
+	f.Add(byte(63), []byte("same"), []byte("same"), bytes.Repeat([]byte{0xff}, 64), []byte{1})
+
+	f.Fuzz(func(t *testing.T, size byte, a []byte, b []byte, mul []byte, key []byte) {
+		if len(key) == 0 {
+			key = nil
+		}
+
+		ab := fuzzHash(size, key)
+		ab.Add(a)
+		ab.Add(b)
+
+		ba := fuzzHash(size, key)
+		ba.Add(b)
+		ba.Add(a)
+
+		if !bytes.Equal(ab.GetDigest(), ba.GetDigest()) {
+			t.Fatal("adding is not commutative")
+		}
+
+		onlyB := fuzzHash(size, key)
+		onlyB.Add(b)
+
+		ab.Remove(a)
+		if !bytes.Equal(ab.GetDigest(), onlyB.GetDigest()) {
+			t.Fatal("removing an element does not undo adding it")
+		}
+
+		m := uintp.MustFromBytes(ab.ModulusBitsize, mul)
+		ab.AddMul(m, a)
+		ab.RemoveMul(m, a)
+		if !bytes.Equal(ab.GetDigest(), onlyB.GetDigest()) {
+			t.Fatal("removing a multiple of an element does not undo adding it")
+		}
+
+		combined := fuzzHash(size, key)
+		combined.CombineBytes(onlyB.GetDigest())
+		combined.CombineInverse(onlyB.GetState())
+		if !bytes.Equal(combined.GetDigest(), fuzzHash(size, key).GetDigest()) {
+			t.Fatal("combining the inverse of a state does not undo combining it")
+		}
+	})
+}
@@ -2,9 +2,11 @@ package lthash_test
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"errors"
 
 	"encoding/base64"
 	"encoding/binary"
@@ -39,7 +41,7 @@ func EncryptMessage(key []byte, message []byte) ([]byte, error) {
 
 func Test__LtHash__Should__EnableFileRecoveryEasily(t *testing.T) {
 	file_block_size_bytes := 256
-	encrypted_hash := lthash.NewDirect(500, 128, file_block_size_bytes, nil)
+	encrypted_hash := lthash.MustNewDirect(500, 128, file_block_size_bytes, nil)
 
 	file, err := os.Open("test.txt")
 	if err != nil {
@@ -80,7 +82,7 @@ func Test__LtHash__Should__EnableFileRecoveryEasily(t *testing.T) {
 	encrypted_hash.ComputeDigest(encrypted)
 
 	blocks_to_insert := 250
-	nonce_hash := lthash.NewDirect(500, 128, file_block_size_bytes, nil)
+	nonce_hash := lthash.MustNewDirect(500, 128, file_block_size_bytes, nil)
 	for i := 0; i < blocks_to_insert; i++ {
 		nonces_and_position := make([]byte, file_block_size_bytes+8)
 		_, err_nonces := rand.Read(nonces_and_position)
@@ -103,7 +105,7 @@ func Test__LtHash__Should__EnableFileRecoveryEasily(t *testing.T) {
 		encrypted = append(encrypted[:position], append(nonces, encrypted[position:]...)...)
 	}
 
-	tampered_hash := lthash.NewDirect(500, 128, file_block_size_bytes, nil)
+	tampered_hash := lthash.MustNewDirect(500, 128, file_block_size_bytes, nil)
 	tampered_hash.ComputeDigest(encrypted)
 
 	original_hash_b64 := base64.StdEncoding.EncodeToString(encrypted_hash.GetDigest())
@@ -141,16 +143,16 @@ var test_vectors = []struct {
 
 func Test__AddMul__Should__BeHomomorphic(t *testing.T) {
 	for _, vec := range test_vectors {
-		m1 := uintp.FromHex(uint64(vec.chunk_size_bits), vec.m1)
-		m2 := uintp.FromHex(uint64(vec.chunk_size_bits), vec.m2)
+		m1 := uintp.MustFromHex(uint64(vec.chunk_size_bits), vec.m1)
+		m2 := uintp.MustFromHex(uint64(vec.chunk_size_bits), vec.m2)
 
-		hash_mul := lthash.NewDirect(vec.chunk_count, vec.chunk_size_bits, 256, nil)
+		hash_mul := lthash.MustNewDirect(vec.chunk_count, vec.chunk_size_bits, 256, nil)
 		hash_mul.AddMul(m1, vec.bytes_to_add)
 		hash_mul.AddMul(m2, vec.bytes_to_add)
 
 		m1.Add(m2)
 
-		hash := lthash.NewDirect(vec.chunk_count, vec.chunk_size_bits, 256, nil)
+		hash := lthash.MustNewDirect(vec.chunk_count, vec.chunk_size_bits, 256, nil)
 		hash.AddMul(m1, vec.bytes_to_add)
 
 		e := ez.New(t)
@@ -160,15 +162,15 @@ func Test__AddMul__Should__BeHomomorphic(t *testing.T) {
 
 func Test__RemoveMul__Should__BeHomomorphic(t *testing.T) {
 	for _, vec := range test_vectors {
-		m1 := uintp.FromHex(uint64(vec.chunk_size_bits), vec.m1)
-		m2 := uintp.FromHex(uint64(vec.chunk_size_bits), vec.m2)
+		m1 := uintp.MustFromHex(uint64(vec.chunk_size_bits), vec.m1)
+		m2 := uintp.MustFromHex(uint64(vec.chunk_size_bits), vec.m2)
 
-		hash_mul := lthash.NewDirect(vec.chunk_count, vec.chunk_size_bits, 256, nil)
+		hash_mul := lthash.MustNewDirect(vec.chunk_count, vec.chunk_size_bits, 256, nil)
 		hash_mul.AddMul(m1, vec.bytes_to_add)
 
 		m1.Add(m2)
 
-		hash := lthash.NewDirect(vec.chunk_count, vec.chunk_size_bits, 256, nil)
+		hash := lthash.MustNewDirect(vec.chunk_count, vec.chunk_size_bits, 256, nil)
 		hash.AddMul(m1, vec.bytes_to_add)
 
 		hash.RemoveMul(m2, vec.bytes_to_add)
@@ -177,3 +179,125 @@ func Test__RemoveMul__Should__BeHomomorphic(t *testing.T) {
 		e.AssertAreEqual(hash.GetDigest(), hash_mul.GetDigest())
 	}
 }
+
+func Test__LtHash__New__ShouldAcceptKeysLongerThan64Bytes(t *testing.T) {
+	ez := ez.New(t)
+	key := make([]byte, 200)
+
+	hash := lthash.MustNew(16, 64, 256, key)
+	hash.Add([]byte{0x01})
+
+	ez.AssertAreEqual(len(hash.GetDigest()), 16*8)
+	ez.Assert(hash.KeyID() == lthash.NewKeySchedule(key).ID())
+}
+
+func Test__KeySchedule__DifferentRoles__ShouldDeriveDifferentSubkeys(t *testing.T) {
+	ez := ez.New(t)
+	ks := lthash.NewKeySchedule([]byte("This is a key"))
+
+	ez.AssertFalse(bytes.Equal(ks.Derive("role/a"), ks.Derive("role/b")))
+	ez.AssertAreEqual(ks.Derive("role/a"), lthash.NewKeySchedule([]byte("This is a key")).Derive("role/a"))
+	ez.AssertFalse(ks.ID() == lthash.NewKeySchedule([]byte("This is another key")).ID())
+}
+
+func Test__LtHash__Rekey__ShouldEqual__HashWithNewKey(t *testing.T) {
+	ez := ez.New(t)
+	set := [][]byte{{0x01}, {0x02, 0x03}, []byte("element")}
+	oldKs := lthash.NewKeySchedule([]byte("old key"))
+	newKs := lthash.NewKeySchedule([]byte("new key"))
+
+	hash := lthash.MustNewWithSchedule(32, 64, 256, oldKs, lthash.RoleData)
+	expected := lthash.MustNewWithSchedule(32, 64, 256, newKs, lthash.RoleData)
+	for _, element := range set {
+		hash.Add(element)
+		expected.Add(element)
+	}
+
+	ez.AssertNoError(hash.Rekey(newKs, lthash.RoleData, set))
+	ez.AssertAreEqual(hash.GetDigest(), expected.GetDigest())
+	ez.Assert(hash.KeyID() == newKs.ID())
+}
+
+func Test__LtHash__Rekey__ShouldFail__WhenSetDoesNotMatch(t *testing.T) {
+	ez := ez.New(t)
+	ks := lthash.NewKeySchedule([]byte("old key"))
+
+	hash := lthash.MustNewWithSchedule(32, 64, 256, ks, lthash.RoleData)
+	hash.Add([]byte{0x01})
+	digest := hash.GetDigest()
+
+	err := hash.Rekey(lthash.NewKeySchedule([]byte("new key")), lthash.RoleData, [][]byte{{0x02}})
+	ez.Assert(errors.Is(err, lthash.ErrSetMismatch))
+	ez.AssertAreEqual(hash.GetDigest(), digest)
+}
+
+func Test__Params__Presets__ShouldBeValid__AndReachTheirLevel(t *testing.T) {
+	ez := ez.New(t)
+
+	for level, params := range map[int]lthash.Params{128: lthash.Level128, 192: lthash.Level192, 256: lthash.Level256} {
+		ez.AssertNoError(params.Validate())
+		ez.Assert(params.SecurityBits() >= level)
+	}
+
+	ez.Assert(lthash.Level128.SecurityBits() < lthash.Level192.SecurityBits())
+	ez.Assert(lthash.Level192.SecurityBits() < lthash.Level256.SecurityBits())
+}
+
+func Test__Params__Validate__ShouldReject__InsecureOrInconsistentParams(t *testing.T) {
+	ez := ez.New(t)
+
+	insecure := lthash.Params{ChunkCount: 16, ChunkSizeBits: 64, BlockSizeBytes: 8}
+	ez.AssertNoError(insecure.Check())
+	ez.Assert(errors.Is(insecure.Validate(), lthash.ErrInsecureParams))
+
+	for _, params := range []lthash.Params{
+		{ChunkCount: 0, ChunkSizeBits: 128, BlockSizeBytes: 16},
+		{ChunkCount: 1024, ChunkSizeBits: 100, BlockSizeBytes: 16},
+		{ChunkCount: 1024, ChunkSizeBits: 128, BlockSizeBytes: 0},
+		{ChunkCount: 1 << 30, ChunkSizeBits: 128, BlockSizeBytes: 16},
+		{ChunkCount: 1024, ChunkSizeBits: 128, BlockSizeBytes: 8},
+		{ChunkCount: 1024, ChunkSizeBits: 128, BlockSizeBytes: 32},
+	} {
+		ez.Assert(errors.Is(params.Validate(), lthash.ErrInvalidParams))
+		ez.AssertAreEqual(params.SecurityBits(), 0)
+	}
+}
+
+func Test__Params__Check__ShouldReject__BlocksThatDoNotMatchChunks__ButNewShouldHashThem(t *testing.T) {
+	ez := ez.New(t)
+	params := lthash.Params{ChunkCount: 16, ChunkSizeBits: 64, BlockSizeBytes: 16}
+
+	ez.Assert(errors.Is(params.Check(), lthash.ErrInvalidParams))
+
+	_, err := lthash.New(params.ChunkCount, params.ChunkSizeBits, params.BlockSizeBytes, nil)
+	ez.AssertNoError(err)
+}
+
+func Test__LtHash__New__ShouldReturnError__WhenParamsAreInvalid(t *testing.T) {
+	ez := ez.New(t)
+
+	_, err := lthash.New(16, 100, 16, nil)
+	ez.Assert(errors.Is(err, lthash.ErrInvalidParams))
+
+	_, err = lthash.NewWithSchedule(0, 64, 8, nil, lthash.RoleData)
+	ez.Assert(errors.Is(err, lthash.ErrInvalidParams))
+}
+
+func Test__LtHash__HashOfUnion__ShouldEqual__CombinedHashes(t *testing.T) {
+	set := ez.SliceOf(ez.Bytes(1, 32), 0, 8)
+
+	ez.ForAll(ez.New(t), ez.PairOf(set, set), func(p ez.Pair[[][]byte, [][]byte]) bool {
+		a, b, union := lthash.MustNewDirect(16, 64, 8, nil), lthash.MustNewDirect(16, 64, 8, nil), lthash.MustNewDirect(16, 64, 8, nil)
+		for _, element := range p.First {
+			a.Add(element)
+			union.Add(element)
+		}
+		for _, element := range p.Second {
+			b.Add(element)
+			union.Add(element)
+		}
+
+		a.Combine(b.GetState())
+		return bytes.Equal(a.GetDigest(), union.GetDigest())
+	})
+}
@@ -0,0 +1,162 @@
+package lthash
+
+import (
+	"errors"
+	"math"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// Params are the construction parameters of an LtHash: ChunkCount chunks of
+// ChunkSizeBits bits each, i.e. vectors of Z_q^n with n = ChunkCount and q = 2^ChunkSizeBits
+type Params struct {
+	ChunkCount     uint
+	ChunkSizeBits  uint
+	BlockSizeBytes int
+}
+
+// Presets for each security level, according to SecurityBits. The chunk size
+// matches the GCrypt modulus of the same level, so they can be used with GHash
+var (
+	Level128 = Params{ChunkCount: 1024, ChunkSizeBits: 128, BlockSizeBytes: 16}
+	Level192 = Params{ChunkCount: 768, ChunkSizeBits: 192, BlockSizeBytes: 24}
+	Level256 = Params{ChunkCount: 768, ChunkSizeBits: 256, BlockSizeBytes: 32}
+)
+
+// MinSecurityBits is the smallest estimated security accepted by Validate
+const MinSecurityBits = 128
+
+// The estimate of SecurityBits assumes sets of up to 2^MaxSetSizeBits elements
+const MaxSetSizeBits = 32
+
+// subkeyBits bounds the security of keyed hashes, whose XOF keys are 256-bit subkeys
+const subkeyBits = subkeySize * 8
+
+// maxXofSize is the largest output of the BLAKE2b XOF, in bytes
+const maxXofSize = math.MaxUint32 - 1
+
+var ErrInsecureParams = errors.New("insecure parameters")
+var ErrInvalidParams = errors.New("invalid parameters")
+
+// New creates an LtHash with these parameters, once they are validated
+func (p Params) New(key []byte) (*LtHash, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return New(p.ChunkCount, p.ChunkSizeBits, p.BlockSizeBytes, key)
+}
+
+// Check rejects parameters the construction cannot work with, and blocks that
+// do not match the chunks, whatever their security
+func (p Params) Check() error {
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	// GHash blocks are GCrypt ciphertext blocks, of a chunk each
+	if uint64(p.BlockSizeBytes)*8 != uint64(p.ChunkSizeBits) {
+		return errorutils.NewfWithInner(ErrInvalidParams, "blocks of %d bytes do not match chunks of %d bits", p.BlockSizeBytes, p.ChunkSizeBits)
+	}
+
+	return nil
+}
+
+// check rejects parameters the construction cannot work with. Blocks of any
+// size are hashed, so New and NewDirect accept them
+func (p Params) check() error {
+	if p.ChunkCount == 0 {
+		return errorutils.NewWithInner(ErrInvalidParams, "chunk count must be positive")
+	}
+
+	if p.ChunkSizeBits == 0 || p.ChunkSizeBits%64 != 0 {
+		return errorutils.NewfWithInner(ErrInvalidParams, "chunk size must be a positive multiple of 64 bits, got %d", p.ChunkSizeBits)
+	}
+
+	if p.BlockSizeBytes <= 0 {
+		return errorutils.NewfWithInner(ErrInvalidParams, "block size must be positive, got %d", p.BlockSizeBytes)
+	}
+
+	// the XOF is created with an output size of ChunkCount * ChunkSizeBits
+	if uint64(p.ChunkCount)*uint64(p.ChunkSizeBits) > maxXofSize {
+		return errorutils.NewfWithInner(ErrInvalidParams, "%d chunks of %d bits exceed the output of the XOF", p.ChunkCount, p.ChunkSizeBits)
+	}
+
+	return nil
+}
+
+// Validate rejects inconsistent parameters, and parameters whose estimated
+// security is below MinSecurityBits
+func (p Params) Validate() error {
+	if err := p.Check(); err != nil {
+		return err
+	}
+
+	if bits := p.SecurityBits(); bits < MinSecurityBits {
+		return errorutils.NewfWithInner(ErrInsecureParams, "%d chunks of %d bits give an estimated %d bits of security, below %d; use %d chunks or more",
+			p.ChunkCount, p.ChunkSizeBits, bits, MinSecurityBits, p.chunksFor(MinSecurityBits))
+	}
+
+	return nil
+}
+
+// SecurityBits estimates the security of the parameters against collisions.
+//
+// Finding a collision between two sets of up to 2^MaxSetSizeBits elements is
+// solving SIS in dimension n over Z_q, for a vector x of norm below
+// beta = 2^(MaxSetSizeBits+16), which is the case for sets of that many elements
+// of up to 2^32 distinct values. Lattice reduction finds vectors of norm about
+// 2^(2 sqrt(n log q log delta)) (Micciancio and Regev), so an attack needs a root
+// Hermite factor delta with log delta = (log beta)^2 / (4 n log q). The cost of
+// reaching delta is estimated with the core-SVP model: BKZ with block size b,
+// which reaches delta(b) = ((pi b)^(1/b) b / (2 pi e))^(1/(2(b-1))), costs 2^(0.292 b).
+//
+// The estimate is also bounded by the birthday bound on the state and by the
+// size of the subkeys
+func (p Params) SecurityBits() int {
+	if p.Check() != nil {
+		return 0
+	}
+
+	stateBits := float64(p.ChunkCount) * float64(p.ChunkSizeBits)
+	logBeta := float64(MaxSetSizeBits + 16)
+	logDelta := logBeta * logBeta / (4 * stateBits)
+
+	return int(min(coreSVPBits(logDelta), stateBits/2, subkeyBits))
+}
+
+// coreSVPBits returns the cost of the smallest BKZ block size reaching the root Hermite factor 2^logDelta
+func coreSVPBits(logDelta float64) float64 {
+	const minBlockSize, maxBlockSize = 50, 4096
+
+	for b := minBlockSize; b <= maxBlockSize; b++ {
+		if math.Log2(rootHermiteFactor(float64(b))) <= logDelta {
+			return 0.292 * float64(b)
+		}
+	}
+
+	return 0.292 * maxBlockSize
+}
+
+func rootHermiteFactor(b float64) float64 {
+	return math.Pow(math.Pow(math.Pi*b, 1/b)*b/(2*math.Pi*math.E), 1/(2*(b-1)))
+}
+
+// chunksFor returns the smallest chunk count reaching the given security, with the same chunk size
+func (p Params) chunksFor(bits int) uint {
+	q := p
+	for q.ChunkCount = 1; q.SecurityBits() < bits; q.ChunkCount *= 2 {
+	}
+
+	low, high := q.ChunkCount/2, q.ChunkCount
+	for low+1 < high {
+		q.ChunkCount = (low + high) / 2
+		if q.SecurityBits() >= bits {
+			high = q.ChunkCount
+		} else {
+			low = q.ChunkCount
+		}
+	}
+
+	return high
+}
@@ -0,0 +1,237 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"math/bits"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Merkle trees as defined by RFC 6962: leaves are hashed with a 0x00 prefix and
+// inner nodes with a 0x01 prefix, so that a leaf can never be taken for a node.
+// Trees with a size that is not a power of two are split at the largest power
+// of two smaller than the size
+
+type HashFunc func() hash.Hash
+
+var SHA256 HashFunc = sha256.New
+
+var BLAKE2b256 HashFunc = func() hash.Hash {
+	h, err := blake2b.New256(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return h
+}
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var ErrIndexOutOfRange = errors.New("leaf index is out of range")
+var ErrInvalidSize = errors.New("invalid tree size")
+
+// Tree caches the roots of its complete subtrees as they are built, so that
+// any root, proof or node costs O(log^2 n) hashes at most
+type Tree struct {
+	hashFunc HashFunc
+	// levels[h] holds the roots of the complete subtrees of 2^h leaves, from
+	// the left, so levels[0] holds the leaf hashes
+	levels [][][]byte
+}
+
+func New(hashFunc HashFunc) *Tree {
+	r := new(Tree)
+	r.hashFunc = hashFunc
+	r.levels = make([][][]byte, 0)
+
+	return r
+}
+
+func NewFrom(hashFunc HashFunc, data [][]byte) *Tree {
+	r := New(hashFunc)
+
+	for i := range data {
+		r.Append(data[i])
+	}
+
+	return r
+}
+
+// NewFromCiphertext builds a tree whose leaves are the GCrypt blocks of ciphertext
+func NewFromCiphertext(hashFunc HashFunc, crypt *gcrypt.GCrypt, ciphertext []byte) *Tree {
+	return NewFrom(hashFunc, CiphertextLeaves(crypt, ciphertext))
+}
+
+// CiphertextLeaves splits a GCrypt ciphertext in the bytes of each of its blocks
+func CiphertextLeaves(crypt *gcrypt.GCrypt, ciphertext []byte) [][]byte {
+	blocks := crypt.FromBytes(ciphertext)
+	r := make([][]byte, len(blocks))
+
+	for i := range blocks {
+		r[i] = blocks[i].Bytes()
+	}
+
+	return r
+}
+
+func (t *Tree) Append(data []byte) {
+	t.push(0, LeafHash(t.hashFunc, data))
+}
+
+// push appends the root of a complete subtree of 2^h leaves, and the roots of
+// the subtrees it completes
+func (t *Tree) push(h int, root []byte) {
+	if h == len(t.levels) {
+		t.levels = append(t.levels, nil)
+	}
+
+	t.levels[h] = append(t.levels[h], root)
+	if n := len(t.levels[h]); n%2 == 0 {
+		t.push(h+1, nodeHash(t.hashFunc, t.levels[h][n-2], t.levels[h][n-1]))
+	}
+}
+
+func (t *Tree) Size() uint64 {
+	if len(t.levels) == 0 {
+		return 0
+	}
+
+	return uint64(len(t.levels[0]))
+}
+
+func (t *Tree) Root() []byte {
+	return t.rootOf(0, t.Size())
+}
+
+// RootAt is the root the tree had when it had size leaves
+func (t *Tree) RootAt(size uint64) ([]byte, error) {
+	if size > t.Size() {
+		return nil, ErrInvalidSize
+	}
+
+	return t.rootOf(0, size), nil
+}
+
+// InclusionProof returns the audit path of the leaf at index
+func (t *Tree) InclusionProof(index uint64) ([][]byte, error) {
+	if index >= t.Size() {
+		return nil, ErrIndexOutOfRange
+	}
+
+	return t.path(index, 0, t.Size()), nil
+}
+
+// ConsistencyProof proves that the tree with oldSize leaves is a prefix of the current tree
+func (t *Tree) ConsistencyProof(oldSize uint64) ([][]byte, error) {
+	if oldSize == 0 || oldSize > t.Size() {
+		return nil, ErrInvalidSize
+	}
+
+	return t.subproof(oldSize, 0, t.Size(), true), nil
+}
+
+// Locate returns the indices of the leaves of data that differ from the ones
+// of the tree, descending only into subtrees whose roots differ
+func (t *Tree) Locate(data [][]byte) ([]uint64, error) {
+	if uint64(len(data)) != t.Size() {
+		return nil, ErrInvalidSize
+	}
+
+	other := NewFrom(t.hashFunc, data)
+	return t.locate(other, 0, t.Size()), nil
+}
+
+func (t *Tree) locate(other *Tree, start uint64, n uint64) []uint64 {
+	if bytes.Equal(t.rootOf(start, n), other.rootOf(start, n)) {
+		return nil
+	}
+
+	if n == 1 {
+		return []uint64{start}
+	}
+
+	k := split(n)
+	return append(t.locate(other, start, k), t.locate(other, start+k, n-k)...)
+}
+
+// rootOf returns the root of the subtree of the n leaves from start. The
+// subtrees RFC 6962 splits a tree into start at a multiple of the largest
+// power of two not above their size, so those of 2^h leaves are in levels[h]
+func (t *Tree) rootOf(start uint64, n uint64) []byte {
+	if n == 0 {
+		h := t.hashFunc()
+		return h.Sum(nil)
+	}
+
+	if n&(n-1) == 0 {
+		h := bits.TrailingZeros64(n)
+		return t.levels[h][start>>h]
+	}
+
+	k := split(n)
+	return nodeHash(t.hashFunc, t.rootOf(start, k), t.rootOf(start+k, n-k))
+}
+
+func (t *Tree) path(index uint64, start uint64, n uint64) [][]byte {
+	if n <= 1 {
+		return [][]byte{}
+	}
+
+	k := split(n)
+	if index < k {
+		return append(t.path(index, start, k), t.rootOf(start+k, n-k))
+	}
+
+	return append(t.path(index-k, start+k, n-k), t.rootOf(start, k))
+}
+
+func (t *Tree) subproof(m uint64, start uint64, n uint64, complete bool) [][]byte {
+	if m == n {
+		if complete {
+			return [][]byte{}
+		}
+
+		return [][]byte{t.rootOf(start, n)}
+	}
+
+	k := split(n)
+	if m <= k {
+		return append(t.subproof(m, start, k, complete), t.rootOf(start+k, n-k))
+	}
+
+	return append(t.subproof(m-k, start+k, n-k, false), t.rootOf(start, k))
+}
+
+func LeafHash(hashFunc HashFunc, data []byte) []byte {
+	h := hashFunc()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+
+	return h.Sum(nil)
+}
+
+func nodeHash(hashFunc HashFunc, left []byte, right []byte) []byte {
+	h := hashFunc()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+
+	return h.Sum(nil)
+}
+
+// split returns the largest power of two smaller than n
+func split(n uint64) uint64 {
+	k := uint64(1)
+	for k<<1 < n {
+		k <<= 1
+	}
+
+	return k
+}
@@ -0,0 +1,178 @@
+package merkle_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/crypto/hash/merkle"
+	"github.com/titosilva/pdpr-go/internal/ez"
+)
+
+var hashFuncs = []merkle.HashFunc{merkle.SHA256, merkle.BLAKE2b256}
+
+func leaves(count int) [][]byte {
+	r := make([][]byte, count)
+
+	for i := range r {
+		r[i] = []byte(fmt.Sprintf("leaf %d", i))
+	}
+
+	return r
+}
+
+func Test__Merkle__SHA256__ShouldMatch__RFC6962Values(t *testing.T) {
+	ez := ez.New(t)
+
+	empty := merkle.New(merkle.SHA256)
+	ez.AssertAreEqual(hex.EncodeToString(empty.Root()), "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+
+	emptyLeaf := merkle.NewFrom(merkle.SHA256, [][]byte{{}})
+	ez.AssertAreEqual(hex.EncodeToString(emptyLeaf.Root()), "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d")
+}
+
+// rfc6962Leaves are the leaves of the reference tree of the certificate
+// transparency implementations, with the roots of its first 1 to 8 leaves
+var rfc6962Leaves = []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"}
+
+var rfc6962Roots = []string{
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+func hexes(values [][]byte) []string {
+	r := make([]string, len(values))
+
+	for i := range values {
+		r[i] = hex.EncodeToString(values[i])
+	}
+
+	return r
+}
+
+func Test__Merkle__SHA256__ShouldMatch__RFC6962Trees(t *testing.T) {
+	ez := ez.New(t)
+
+	tree := merkle.New(merkle.SHA256)
+	for i, leaf := range rfc6962Leaves {
+		data, _ := hex.DecodeString(leaf)
+		tree.Append(data)
+		ez.AssertAreEqual(hex.EncodeToString(tree.Root()), rfc6962Roots[i])
+	}
+
+	for size := range rfc6962Roots {
+		root, err := tree.RootAt(uint64(size + 1))
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(hex.EncodeToString(root), rfc6962Roots[size])
+	}
+
+	proof, _ := tree.InclusionProof(0)
+	ez.AssertAreEqual(hexes(proof), []string{
+		"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+		"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+		"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+	})
+
+	proof, _ = tree.InclusionProof(5)
+	ez.AssertAreEqual(hexes(proof), []string{
+		"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	})
+
+	proof, _ = tree.ConsistencyProof(4)
+	ez.AssertAreEqual(hexes(proof), []string{"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4"})
+
+	proof, _ = tree.ConsistencyProof(6)
+	ez.AssertAreEqual(hexes(proof), []string{
+		"0ebc5d3437fbe2db158b9f126a1d118e308181031d0a949f8dededebc558ef6a",
+		"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	})
+}
+
+func Test__Merkle__InclusionProof__ShouldVerify__ForEveryLeaf(t *testing.T) {
+	ez := ez.New(t)
+
+	for _, hashFunc := range hashFuncs {
+		for size := 1; size <= 20; size++ {
+			data := leaves(size)
+			tree := merkle.NewFrom(hashFunc, data)
+
+			for i := range data {
+				proof, err := tree.InclusionProof(uint64(i))
+				ez.AssertNoError(err)
+				ez.Assert(merkle.VerifyInclusion(hashFunc, uint64(i), tree.Size(), data[i], proof, tree.Root()))
+				ez.AssertFalse(merkle.VerifyInclusion(hashFunc, uint64(i), tree.Size(), []byte("tampered"), proof, tree.Root()))
+				if size > 1 {
+					other := uint64((i + 1) % size)
+					ez.AssertFalse(merkle.VerifyInclusion(hashFunc, other, tree.Size(), data[i], proof, tree.Root()))
+				}
+			}
+		}
+	}
+}
+
+func Test__Merkle__ConsistencyProof__ShouldVerify__ForEveryPrefix(t *testing.T) {
+	ez := ez.New(t)
+
+	for _, hashFunc := range hashFuncs {
+		for size := 1; size <= 16; size++ {
+			tree := merkle.NewFrom(hashFunc, leaves(size))
+
+			for old := 1; old <= size; old++ {
+				oldRoot, err := tree.RootAt(uint64(old))
+				ez.AssertNoError(err)
+
+				proof, err := tree.ConsistencyProof(uint64(old))
+				ez.AssertNoError(err)
+				ez.Assert(merkle.VerifyConsistency(hashFunc, uint64(old), tree.Size(), oldRoot, tree.Root(), proof))
+
+				forged := merkle.NewFrom(hashFunc, append(leaves(old-1), []byte("forged")))
+				if old < size {
+					ez.AssertFalse(merkle.VerifyConsistency(hashFunc, uint64(old), tree.Size(), forged.Root(), tree.Root(), proof))
+				}
+			}
+		}
+	}
+}
+
+func Test__Merkle__Locate__ShouldReturn__CorruptedBlocks(t *testing.T) {
+	ez := ez.New(t)
+	crypt := gcrypt.MustNew(128)
+	encrypted := crypt.MustEncrypt([]byte("Hello, World!"), []byte("This is a key"))
+
+	tree := merkle.NewFromCiphertext(merkle.BLAKE2b256, crypt, encrypted)
+	ez.AssertAreEqual(tree.Size(), uint64(13*8))
+
+	encrypted[16*5] ^= 1
+	encrypted[16*77+3] ^= 1
+
+	corrupted, err := tree.Locate(merkle.CiphertextLeaves(crypt, encrypted))
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(corrupted, []uint64{5, 77})
+}
+
+func Test__Merkle__SpotCheck__ShouldFail__ForCorruptedBlock(t *testing.T) {
+	ez := ez.New(t)
+	crypt := gcrypt.MustNew(64)
+	encrypted := crypt.MustEncrypt([]byte("Hello, World!"), []byte("This is a key"))
+
+	tree := merkle.NewFromCiphertext(merkle.SHA256, crypt, encrypted)
+	root := tree.Root()
+
+	encrypted[8*10] ^= 1
+	blocks := merkle.CiphertextLeaves(crypt, encrypted)
+
+	for _, i := range []uint64{9, 10, 11} {
+		proof, _ := tree.InclusionProof(i)
+		ez.AssertAreEqual(merkle.VerifyInclusion(merkle.SHA256, i, tree.Size(), blocks[i], proof, root), i != 10)
+	}
+}
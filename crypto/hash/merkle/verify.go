@@ -0,0 +1,83 @@
+package merkle
+
+import "bytes"
+
+// VerifyInclusion checks that data is the leaf at index of the tree with the given size and root
+func VerifyInclusion(hashFunc HashFunc, index uint64, size uint64, data []byte, proof [][]byte, root []byte) bool {
+	if index >= size {
+		return false
+	}
+
+	fn, sn := index, size-1
+	r := LeafHash(hashFunc, data)
+
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(hashFunc, p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(hashFunc, r, p)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+// VerifyConsistency checks that the tree of oldSize leaves and oldRoot is a
+// prefix of the tree of newSize leaves and newRoot
+func VerifyConsistency(hashFunc HashFunc, oldSize uint64, newSize uint64, oldRoot []byte, newRoot []byte, proof [][]byte) bool {
+	if oldSize == 0 || oldSize > newSize {
+		return false
+	}
+
+	if oldSize == newSize {
+		return len(proof) == 0 && bytes.Equal(oldRoot, newRoot)
+	}
+
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+
+	if len(proof) == 0 {
+		return false
+	}
+
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+
+		if fn&1 == 1 || fn == sn {
+			fr = nodeHash(hashFunc, c, fr)
+			sr = nodeHash(hashFunc, c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = nodeHash(hashFunc, sr, c)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return sn == 0 && bytes.Equal(fr, oldRoot) && bytes.Equal(sr, newRoot)
+}
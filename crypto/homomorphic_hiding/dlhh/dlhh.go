@@ -21,7 +21,7 @@ func (dlh *DLHider) Hide(data []byte) []byte {
 func (dlh *DLHider) CombineHidden(hidden1 []byte, hidden2 []byte) []byte {
 	h1Nat := nmod.NewFromBigEndianBytes(hidden1, dlh.dlg.Mod)
 	h2Nat := nmod.NewFromBigEndianBytes(hidden2, dlh.dlg.Mod)
-	r := h1Nat.Mul(h2Nat)
+	r := h1Nat.MustMul(h2Nat)
 
 	return r.Bytes()
 }
@@ -29,7 +29,7 @@ func (dlh *DLHider) CombineHidden(hidden1 []byte, hidden2 []byte) []byte {
 func (dlh *DLHider) CombinePlain(data1 []byte, data2 []byte) []byte {
 	d1Nat := nmod.NewFromBigEndianBytes(data1, dlh.dlg.MulMod)
 	d2Nat := nmod.NewFromBigEndianBytes(data2, dlh.dlg.MulMod)
-	r := d1Nat.Add(d2Nat)
+	r := d1Nat.MustAdd(d2Nat)
 
 	return r.Bytes()
 }
@@ -37,7 +37,7 @@ func (dlh *DLHider) CombinePlain(data1 []byte, data2 []byte) []byte {
 func (dlh *DLHider) SubtractPlain(data1 []byte, data2 []byte) []byte {
 	d1Nat := nmod.NewFromBigEndianBytes(data1, dlh.dlg.MulMod)
 	d2Nat := nmod.NewFromBigEndianBytes(data2, dlh.dlg.MulMod)
-	r := d1Nat.Sub(d2Nat)
+	r := d1Nat.MustSub(d2Nat)
 
 	return r.Bytes()
 }
@@ -56,3 +56,23 @@ func (dlh *DLHider) VerifyHidden(hidden1 []byte, hidden2 []byte) bool {
 
 	return h1Nat.Equal(h2Nat)
 }
+
+// ScaleHidden returns the hiding of the hidden data multiplied by scalar
+func (dlh *DLHider) ScaleHidden(hidden []byte, scalar []byte) []byte {
+	hNat := nmod.NewFromBigEndianBytes(hidden, dlh.dlg.Mod)
+	r := hNat.ExpBytes(scalar)
+
+	return r.Bytes()
+}
+
+// ScalePlain returns data multiplied by scalar, modulo the order of the generator,
+// whose hiding is the hiding of data scaled by ScaleHidden
+func (dlh *DLHider) ScalePlain(data []byte, scalar []byte) []byte {
+	// the hiding only depends on data modulo the order of the generator, and
+	// products modulo MulMod, which is not a multiple of it, would not match
+	dNat := nmod.NewFromBigEndianBytes(data, dlh.dlg.Order)
+	sNat := nmod.NewFromBigEndianBytes(scalar, dlh.dlg.Order)
+	r := dNat.MustMul(sNat)
+
+	return r.Bytes()
+}
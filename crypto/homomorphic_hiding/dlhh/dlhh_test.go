@@ -44,3 +44,36 @@ func Test__Combine__ShouldCombineHiddenDataCorrectly__WhenTwoHiddenDataArePassed
 		t.Errorf("Expected the combined data to be valid")
 	}
 }
+
+func Test__ScaleHidden__ShouldHideScaledData__WhenScalarIsPassed(t *testing.T) {
+	// Arrange
+	dlg := dl.NewOakley2Group()
+	dlh := dlhh.New(dlg)
+	data := random(32)
+
+	// Act
+	hidden := dlh.Hide(data)
+	tripled := dlh.ScaleHidden(hidden, []byte{3})
+
+	// Assert
+	expected := dlh.Hide(dlh.CombinePlain(dlh.CombinePlain(data, data), data))
+	if !dlh.VerifyHidden(tripled, expected) {
+		t.Errorf("Expected the scaled hidden data to be the hiding of the scaled data")
+	}
+}
+
+func Test__ScalePlain__ShouldMatch__ScaleHidden(t *testing.T) {
+	// Arrange
+	dlg := dl.NewOakley2Group()
+	dlh := dlhh.New(dlg)
+	data := random(127)
+	scalar := random(32)
+
+	// Act
+	scaled := dlh.ScalePlain(data, scalar)
+
+	// Assert
+	if !dlh.VerifyHidden(dlh.Hide(scaled), dlh.ScaleHidden(dlh.Hide(data), scalar)) {
+		t.Errorf("Expected the hiding of the scaled data to be the scaled hidden data")
+	}
+}
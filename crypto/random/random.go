@@ -20,5 +20,5 @@ func GenerateUintp(modulusBitsize uint64) (*uintp.UintP, error) {
 		return nil, err
 	}
 
-	return uintp.FromBytes(modulusBitsize, bs), nil
+	return uintp.FromBytes(modulusBitsize, bs)
 }
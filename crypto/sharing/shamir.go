@@ -0,0 +1,283 @@
+// Package sharing splits secrets, such as PDPr keys, into n shares so that
+// any k of them recover the secret: Shamir secret sharing over a prime field,
+// and Feldman and Pedersen verifiable secret sharing over a discrete log group.
+package sharing
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/nmod"
+)
+
+// Field is a prime field. Secrets are split in chunks one byte shorter than the
+// prime, and each chunk is shared with its own polynomial
+type Field struct {
+	prime      *big.Int
+	mod        *nmod.Mod
+	size       int
+	inverseExp []byte
+}
+
+// Share is the point (Index, p(Index)) of the polynomial of each chunk.
+// Blinding is only set by Pedersen, and holds the points of the blinding polynomials
+type Share struct {
+	Index    uint32   `json:"index"`
+	Length   int      `json:"length"`
+	Values   [][]byte `json:"values"`
+	Blinding [][]byte `json:"blinding,omitempty"`
+}
+
+// MaxShares bounds the number of shares, keeping the indices small
+const MaxShares = 1 << 16
+
+var ErrThreshold = errors.New("invalid threshold or share count")
+var ErrInvalidShare = errors.New("invalid share")
+
+// NewField creates the field of integers modulo prime, given in big-endian
+func NewField(prime []byte) (*Field, error) {
+	p := new(big.Int).SetBytes(prime)
+	if p.BitLen() < 16 || !p.ProbablyPrime(32) {
+		return nil, errorutils.New("the modulus of the field must be an odd prime of at least 16 bits")
+	}
+
+	mod, err := nmod.NewModulusFromBigEndianBytes(p.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Field)
+	r.prime = p
+	r.mod = mod
+	r.size = len(p.Bytes())
+	// inverses are computed as x^(p-2), by Fermat's little theorem
+	r.inverseExp = new(big.Int).Sub(p, big.NewInt(2)).Bytes()
+
+	return r, nil
+}
+
+// ChunkSize is the number of bytes of the secret shared by each polynomial
+func (f *Field) ChunkSize() int {
+	return f.size - 1
+}
+
+func (f *Field) element(bs []byte) (*nmod.NatMod, error) {
+	if len(bs) != f.size || new(big.Int).SetBytes(bs).Cmp(f.prime) >= 0 {
+		return nil, ErrInvalidShare
+	}
+
+	return nmod.NewFromBigEndianBytes(bs, f.mod), nil
+}
+
+func (f *Field) random() (*nmod.NatMod, error) {
+	v, err := rand.Int(rand.Reader, f.prime)
+	if err != nil {
+		return nil, err
+	}
+
+	return nmod.NewFromBigEndianBytes(v.FillBytes(make([]byte, f.size)), f.mod), nil
+}
+
+func (f *Field) inverse(x *nmod.NatMod) *nmod.NatMod {
+	return x.ExpBytes(f.inverseExp)
+}
+
+// chunks splits the secret into field elements
+func (f *Field) chunks(secret []byte) []*nmod.NatMod {
+	size := f.ChunkSize()
+	r := make([]*nmod.NatMod, 0, len(secret)/size+1)
+
+	for start := 0; start < len(secret); start += size {
+		end := min(start+size, len(secret))
+		r = append(r, nmod.NewFromBigEndianBytes(secret[start:end], f.mod))
+	}
+
+	return r
+}
+
+// join is the inverse of chunks
+func (f *Field) join(chunks []*nmod.NatMod, length int) ([]byte, error) {
+	size := f.ChunkSize()
+	r := make([]byte, 0, length)
+
+	for _, c := range chunks {
+		bs := c.Bytes()
+		chunkLength := min(size, length-len(r))
+		if chunkLength <= 0 {
+			return nil, ErrInvalidShare
+		}
+
+		// the chunk is the low chunkLength bytes of the element, and higher bytes must be zero
+		for _, b := range bs[:len(bs)-chunkLength] {
+			if b != 0 {
+				return nil, ErrInvalidShare
+			}
+		}
+
+		r = append(r, bs[len(bs)-chunkLength:]...)
+	}
+
+	if len(r) != length {
+		return nil, ErrInvalidShare
+	}
+
+	return r, nil
+}
+
+// polynomial returns random coefficients of a polynomial of degree threshold-1
+// with constant term secret
+func (f *Field) polynomial(secret *nmod.NatMod, threshold int) ([]*nmod.NatMod, error) {
+	r := make([]*nmod.NatMod, threshold)
+	r[0] = secret
+
+	for i := 1; i < threshold; i++ {
+		c, err := f.random()
+		if err != nil {
+			return nil, err
+		}
+
+		r[i] = c
+	}
+
+	return r, nil
+}
+
+// evaluate evaluates the polynomial at x, by Horner's rule
+func (f *Field) evaluate(coefficients []*nmod.NatMod, x uint32) *nmod.NatMod {
+	xNat := nmod.NewFromUint(uint64(x), f.mod)
+	r := nmod.NewFromUint(0, f.mod)
+
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		r = r.MustMul(xNat).MustAdd(coefficients[i])
+	}
+
+	return r
+}
+
+// checkThreshold also requires fewer shares than the prime, as the share of
+// index p would be evaluated at zero, which is the secret
+func checkThreshold(field *Field, threshold int, count int) error {
+	if threshold < 1 || count < threshold || count >= MaxShares || big.NewInt(int64(count)).Cmp(field.prime) >= 0 {
+		return errorutils.NewfWithInner(ErrThreshold, "cannot split into %d shares with threshold %d", count, threshold)
+	}
+
+	return nil
+}
+
+// point returns the index of a share modulo the prime, the x it is evaluated at.
+// Indices that are zero modulo the prime are rejected by the callers
+func (f *Field) point(index uint32) uint64 {
+	return new(big.Int).Mod(big.NewInt(int64(index)), f.prime).Uint64()
+}
+
+// Split splits the secret into count shares, any threshold of which recover it
+func Split(field *Field, secret []byte, threshold int, count int) ([]*Share, error) {
+	shares, _, err := split(field, secret, threshold, count)
+	return shares, err
+}
+
+// split also returns the polynomials, for the verifiable schemes to commit to
+func split(field *Field, secret []byte, threshold int, count int) ([]*Share, [][]*nmod.NatMod, error) {
+	if err := checkThreshold(field, threshold, count); err != nil {
+		return nil, nil, err
+	}
+
+	chunks := field.chunks(secret)
+	polynomials := make([][]*nmod.NatMod, len(chunks))
+	for i, c := range chunks {
+		p, err := field.polynomial(c, threshold)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		polynomials[i] = p
+	}
+
+	return evaluateShares(field, polynomials, len(secret), count), polynomials, nil
+}
+
+func evaluateShares(field *Field, polynomials [][]*nmod.NatMod, length int, count int) []*Share {
+	r := make([]*Share, count)
+
+	for i := range r {
+		share := &Share{Index: uint32(i + 1), Length: length, Values: make([][]byte, len(polynomials))}
+		for j, p := range polynomials {
+			share.Values[j] = field.evaluate(p, share.Index).Bytes()
+		}
+
+		r[i] = share
+	}
+
+	return r
+}
+
+// Combine recovers the secret from at least threshold shares.
+// Fewer shares yield a wrong secret, or an error, as in any threshold scheme
+func Combine(field *Field, shares []*Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errorutils.NewWithInner(ErrInvalidShare, "no shares")
+	}
+
+	length, chunkCount := shares[0].Length, len(shares[0].Values)
+	seen := make(map[uint64]bool)
+
+	// indices are compared modulo the prime, which is where they are evaluated
+	for _, s := range shares {
+		x := field.point(s.Index)
+		if x == 0 || seen[x] {
+			return nil, errorutils.NewfWithInner(ErrInvalidShare, "share index %d is invalid or repeated", s.Index)
+		}
+		seen[x] = true
+
+		if s.Length != length || len(s.Values) != chunkCount {
+			return nil, errorutils.NewWithInner(ErrInvalidShare, "shares of different secrets")
+		}
+	}
+
+	coefficients := lagrangeAtZero(field, shares)
+	chunks := make([]*nmod.NatMod, chunkCount)
+
+	for j := range chunks {
+		sum := nmod.NewFromUint(0, field.mod)
+		for i, s := range shares {
+			y, err := field.element(s.Values[j])
+			if err != nil {
+				return nil, err
+			}
+
+			sum = sum.MustAdd(y.MustMul(coefficients[i]))
+		}
+
+		chunks[j] = sum
+	}
+
+	return field.join(chunks, length)
+}
+
+// lagrangeAtZero returns the Lagrange coefficients that interpolate the shares at zero:
+// l_i = prod over j != i of x_j / (x_j - x_i)
+func lagrangeAtZero(field *Field, shares []*Share) []*nmod.NatMod {
+	r := make([]*nmod.NatMod, len(shares))
+
+	for i, si := range shares {
+		xi := nmod.NewFromUint(uint64(si.Index), field.mod)
+		num := nmod.NewFromUint(1, field.mod)
+		den := nmod.NewFromUint(1, field.mod)
+
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+
+			xj := nmod.NewFromUint(uint64(sj.Index), field.mod)
+			num = num.MustMul(xj)
+			den = den.MustMul(xj.MustSub(xi))
+		}
+
+		r[i] = num.MustMul(field.inverse(den))
+	}
+
+	return r
+}
@@ -0,0 +1,123 @@
+package sharing_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/sharing"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/math/dl"
+)
+
+// 2^127 - 1
+var mersenne127 = []byte{0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+var secret = []byte("This is a key, and it is longer than a chunk of the field")
+
+func Test__Shamir__AnyThresholdShares__ShouldRecoverSecret(t *testing.T) {
+	ez := ez.New(t)
+	field, err := sharing.NewField(mersenne127)
+	ez.AssertNoError(err)
+
+	shares, err := sharing.Split(field, secret, 3, 5)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(len(shares), 5)
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		selected := make([]*sharing.Share, 0, len(subset))
+		for _, i := range subset {
+			selected = append(selected, shares[i])
+		}
+
+		recovered, err := sharing.Combine(field, selected)
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(recovered, secret)
+	}
+
+	recovered, err := sharing.Combine(field, shares[:2])
+	ez.Assert(err != nil || string(recovered) != string(secret))
+}
+
+func Test__Shamir__ShouldFail__WhenSharesAreRepeatedOrInvalid(t *testing.T) {
+	ez := ez.New(t)
+	field, _ := sharing.NewField(mersenne127)
+	shares, _ := sharing.Split(field, secret, 2, 3)
+
+	_, err := sharing.Combine(field, []*sharing.Share{shares[0], shares[0]})
+	ez.Assert(errors.Is(err, sharing.ErrInvalidShare))
+
+	_, err = sharing.Split(field, secret, 4, 3)
+	ez.Assert(errors.Is(err, sharing.ErrThreshold))
+
+	_, err = sharing.NewField([]byte{0x7f, 0xff, 0xff, 0xfe})
+	ez.Assert(err != nil)
+}
+
+func Test__Shamir__ShouldReject__IndicesThatAreZeroOrRepeatedModuloThePrime(t *testing.T) {
+	ez := ez.New(t)
+	// 32771, so the share of index 32771 would be the secret
+	field, err := sharing.NewField([]byte{0x80, 0x03})
+	ez.AssertNoError(err)
+
+	_, err = sharing.Split(field, []byte{0x42}, 3, 32771)
+	ez.AssertErrorIs(err, sharing.ErrThreshold)
+
+	shares, err := sharing.Split(field, []byte{0x42}, 2, 32770)
+	ez.AssertNoError(err)
+
+	recovered, err := sharing.Combine(field, []*sharing.Share{shares[0], shares[32769]})
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(recovered, []byte{0x42})
+
+	zero := &sharing.Share{Index: 32771, Length: 1, Values: shares[0].Values}
+	_, err = sharing.Combine(field, []*sharing.Share{shares[1], zero})
+	ez.AssertErrorIs(err, sharing.ErrInvalidShare)
+
+	// index 32772 is 1 modulo the prime
+	collision := &sharing.Share{Index: 32772, Length: 1, Values: shares[1].Values}
+	_, err = sharing.Combine(field, []*sharing.Share{shares[0], collision})
+	ez.AssertErrorIs(err, sharing.ErrInvalidShare)
+}
+
+func Test__Feldman__Shares__ShouldVerifyAndRecoverSecret(t *testing.T) {
+	ez := ez.New(t)
+	vss, err := sharing.NewFeldman(dl.NewOakley2Group())
+	ez.AssertNoError(err)
+
+	shares, commitment, err := vss.Split(secret, 2, 3)
+	ez.AssertNoError(err)
+
+	for _, s := range shares {
+		ez.Assert(vss.Verify(s, commitment))
+	}
+
+	recovered, err := vss.Combine(shares[1:])
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(recovered, secret)
+
+	shares[0].Values[0][len(shares[0].Values[0])-1] ^= 1
+	ez.AssertFalse(vss.Verify(shares[0], commitment))
+
+	shares[1].Index = 3
+	ez.AssertFalse(vss.Verify(shares[1], commitment))
+}
+
+func Test__Pedersen__Shares__ShouldVerifyAndRecoverSecret(t *testing.T) {
+	ez := ez.New(t)
+	vss, err := sharing.NewPedersen(dl.NewOakley2Group())
+	ez.AssertNoError(err)
+
+	shares, commitment, err := vss.Split(secret, 2, 3)
+	ez.AssertNoError(err)
+
+	for _, s := range shares {
+		ez.Assert(vss.Verify(s, commitment))
+	}
+
+	recovered, err := vss.Combine([]*sharing.Share{shares[2], shares[0]})
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(recovered, secret)
+
+	shares[0].Blinding[0][len(shares[0].Blinding[0])-1] ^= 1
+	ez.AssertFalse(vss.Verify(shares[0], commitment))
+}
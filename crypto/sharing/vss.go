@@ -0,0 +1,214 @@
+package sharing
+
+import (
+	"crypto/sha256"
+	"io"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/dl"
+	"github.com/titosilva/pdpr-go/math/nmod"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Commitment is published by the dealer, so that each shareholder can verify
+// its share: Values[j][i] commits to the i-th coefficient of the j-th chunk
+type Commitment struct {
+	Values [][][]byte `json:"values"`
+}
+
+// Feldman verifiable secret sharing. Shares are Shamir shares over the order of
+// the group, and the commitments are g^a for each coefficient a. The commitment
+// to the secret is g^s, so only high-entropy secrets, such as keys, should be shared
+type Feldman struct {
+	group *dl.DiscreteLogGroup
+	field *Field
+}
+
+// Pedersen verifiable secret sharing, whose commitments g^a h^b hide the secret.
+// Nobody knows the discrete log of h, which is derived by hashing
+type Pedersen struct {
+	group *dl.DiscreteLogGroup
+	field *Field
+	h     *nmod.NatMod
+}
+
+var pedersenSalt = []byte("pdpr-go/sharing/pedersen/h/v1")
+
+func NewFeldman(group *dl.DiscreteLogGroup) (*Feldman, error) {
+	field, err := NewField(group.OrderBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Feldman)
+	r.group = group
+	r.field = field
+
+	return r, nil
+}
+
+func NewPedersen(group *dl.DiscreteLogGroup) (*Pedersen, error) {
+	field, err := NewField(group.OrderBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// a uniform element squared is a uniform quadratic residue, i.e. an element
+	// of the subgroup of the safe prime generated by g
+	kdf := hkdf.New(sha256.New, group.Gen.Bytes(), pedersenSalt, nil)
+	bs := make([]byte, len(group.OrderBytes))
+	if _, err := io.ReadFull(kdf, bs); err != nil {
+		return nil, err
+	}
+	bs[0] &= 0x3f
+
+	h := nmod.NewFromBigEndianBytes(bs, group.Mod)
+
+	r := new(Pedersen)
+	r.group = group
+	r.field = field
+	r.h = h.MustMul(h)
+
+	return r, nil
+}
+
+// Field is the field the shares are computed over
+func (v *Feldman) Field() *Field {
+	return v.field
+}
+
+func (v *Feldman) Split(secret []byte, threshold int, count int) ([]*Share, *Commitment, error) {
+	shares, polynomials, err := split(v.field, secret, threshold, count)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := &Commitment{Values: make([][][]byte, len(polynomials))}
+	for j, p := range polynomials {
+		r.Values[j] = make([][]byte, len(p))
+		for i, a := range p {
+			r.Values[j][i] = v.group.Gen.ExpBytes(a.Bytes()).Bytes()
+		}
+	}
+
+	return shares, r, nil
+}
+
+// Verify checks that g^y equals the product of the commitments C_i^(x^i)
+func (v *Feldman) Verify(share *Share, commitment *Commitment) bool {
+	if len(share.Values) != len(commitment.Values) || v.field.point(share.Index) == 0 {
+		return false
+	}
+
+	for j, value := range share.Values {
+		y, err := v.field.element(value)
+		if err != nil {
+			return false
+		}
+
+		expected, err := evaluateCommitment(v.group, v.field, commitment.Values[j], share.Index)
+		if err != nil || !v.group.Gen.ExpBytes(y.Bytes()).Equal(expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (v *Feldman) Combine(shares []*Share) ([]byte, error) {
+	return Combine(v.field, shares)
+}
+
+func (v *Pedersen) Field() *Field {
+	return v.field
+}
+
+func (v *Pedersen) Split(secret []byte, threshold int, count int) ([]*Share, *Commitment, error) {
+	shares, polynomials, err := split(v.field, secret, threshold, count)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blindings := make([][]*nmod.NatMod, len(polynomials))
+	r := &Commitment{Values: make([][][]byte, len(polynomials))}
+
+	for j, p := range polynomials {
+		blinding, err := v.field.random()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		blindings[j], err = v.field.polynomial(blinding, threshold)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r.Values[j] = make([][]byte, len(p))
+		for i, a := range p {
+			gA := v.group.Gen.ExpBytes(a.Bytes())
+			hB := v.h.ExpBytes(blindings[j][i].Bytes())
+			r.Values[j][i] = gA.MustMul(hB).Bytes()
+		}
+	}
+
+	for i, blinded := range evaluateShares(v.field, blindings, 0, len(shares)) {
+		shares[i].Blinding = blinded.Values
+	}
+
+	return shares, r, nil
+}
+
+// Verify checks that g^y h^y' equals the product of the commitments C_i^(x^i)
+func (v *Pedersen) Verify(share *Share, commitment *Commitment) bool {
+	if len(share.Values) != len(commitment.Values) || len(share.Blinding) != len(share.Values) || v.field.point(share.Index) == 0 {
+		return false
+	}
+
+	for j := range share.Values {
+		y, err := v.field.element(share.Values[j])
+		if err != nil {
+			return false
+		}
+
+		b, err := v.field.element(share.Blinding[j])
+		if err != nil {
+			return false
+		}
+
+		expected, err := evaluateCommitment(v.group, v.field, commitment.Values[j], share.Index)
+		if err != nil {
+			return false
+		}
+
+		actual := v.group.Gen.ExpBytes(y.Bytes()).MustMul(v.h.ExpBytes(b.Bytes()))
+		if !actual.Equal(expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (v *Pedersen) Combine(shares []*Share) ([]byte, error) {
+	return Combine(v.field, shares)
+}
+
+// evaluateCommitment computes the product of C_i^(x^i), which is g to the
+// polynomial at x, as exponents are taken modulo the order of g
+func evaluateCommitment(group *dl.DiscreteLogGroup, field *Field, commitment [][]byte, index uint32) (*nmod.NatMod, error) {
+	size := len(group.Gen.Bytes())
+	x := nmod.NewFromUint(uint64(index), field.mod)
+	power := nmod.NewFromUint(1, field.mod)
+	r := nmod.NewFromUint(1, group.Mod)
+
+	for _, c := range commitment {
+		if len(c) != size {
+			return nil, errorutils.NewWithInner(ErrInvalidShare, "malformed commitment")
+		}
+
+		r = r.MustMul(nmod.NewFromBigEndianBytes(c, group.Mod).ExpBytes(power.Bytes()))
+		power = power.MustMul(x)
+	}
+
+	return r, nil
+}
@@ -1,6 +1,9 @@
 package ez
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"path"
 	"reflect"
@@ -40,6 +43,64 @@ func (ez *EzTest) Assert(condition bool, msg ...string) {
 	}
 }
 
+func (ez *EzTest) AssertErrorIs(err error, target error, msg ...string) {
+	if !errors.Is(err, target) {
+		defMsg := fmt.Sprintf("Expected error %v, but got %v", target, err)
+		ez.failWithMsg(1, defMsg, msg)
+	}
+}
+
+func (ez *EzTest) AssertPanics(f func(), msg ...string) {
+	if !panics(f) {
+		ez.failWithMsg(1, "Expected a panic, but the function returned", msg)
+	}
+}
+
+// AssertBytesEqual shows the first difference of got and want in hex
+func (ez *EzTest) AssertBytesEqual(got []byte, want []byte, msg ...string) {
+	if !bytes.Equal(got, want) {
+		ez.failWithMsg(1, hexDiff(got, want), msg)
+	}
+}
+
+func panics(f func()) (r bool) {
+	defer func() {
+		if recover() != nil {
+			r = true
+		}
+	}()
+
+	f()
+	return false
+}
+
+const hexDiffWindow = 16
+
+func hexDiff(got []byte, want []byte) string {
+	i := 0
+	for i < len(got) && i < len(want) && got[i] == want[i] {
+		i++
+	}
+
+	start := max(0, i-hexDiffWindow/2)
+	window := func(bs []byte) string {
+		end := min(len(bs), start+hexDiffWindow)
+		if start >= end {
+			return ""
+		}
+
+		r := hex.EncodeToString(bs[start:end])
+		if end < len(bs) {
+			r += "..."
+		}
+		return r
+	}
+
+	marker := strings.Repeat("  ", i-start) + "^"
+	return fmt.Sprintf("Expected bytes to be equal, but they differ at byte %d (got %d bytes, want %d), from byte %d:\n got: %s\nwant: %s\n      %s",
+		i, len(got), len(want), start, window(got), window(want), marker)
+}
+
 func (ez *EzTest) assertWithLevel(callLevel int, condition bool, msg ...string) {
 	if !condition {
 		ez.failWithMsg(callLevel+1, "Expected condition to be true, but got false", msg)
@@ -0,0 +1,87 @@
+package ez
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// Failures call t.Fatal, so these tests are internal, to check the failing
+// paths without failing themselves
+
+func Test__ForAll__ShouldPass__WhenPropertyHolds(t *testing.T) {
+	ez := New(t)
+	calls := 0
+
+	ForAll(ez, PairOf(Bytes(0, 32), Uint64()), func(p Pair[[]byte, uint64]) bool {
+		calls++
+		return len(p.First) <= 32
+	})
+
+	ez.AssertAreEqual(calls, Checks)
+}
+
+func Test__ShrinkFailure__ShouldFind__MinimalValue(t *testing.T) {
+	ez := New(t)
+	r := rand.New(rand.NewPCG(1, 2))
+
+	// fails for every slice with a byte of at least 10
+	prop := func(bs []byte) bool {
+		for _, b := range bs {
+			if b >= 10 {
+				return false
+			}
+		}
+		return true
+	}
+
+	g := Bytes(0, 64)
+	v := g.generate(r)
+	for holds(prop, v) {
+		v = g.generate(r)
+	}
+
+	shrunk, _ := shrinkFailure(g, prop, v)
+	ez.AssertBytesEqual(shrunk, []byte{10})
+}
+
+func Test__ShrinkFailure__ShouldRespect__Bounds(t *testing.T) {
+	ez := New(t)
+
+	shrunk, _ := shrinkFailure(IntRange(5, 100), func(v int) bool { return v < 42 }, 99)
+	ez.AssertAreEqual(shrunk, 42)
+
+	slice, _ := shrinkFailure(SliceOf(IntRange(5, 100), 2, 10), func(vs []int) bool { return false }, []int{50, 60, 70})
+	ez.AssertAreEqual(slice, []int{5, 5})
+}
+
+func Test__Holds__ShouldFail__WhenPropertyPanics(t *testing.T) {
+	ez := New(t)
+
+	ez.AssertFalse(holds(func(v int) bool { panic(v) }, 1))
+	ez.AssertPanics(func() { panic("panic") })
+	ez.AssertFalse(panics(func() {}))
+}
+
+func Test__HexDiff__ShouldPoint__FirstDifference(t *testing.T) {
+	ez := New(t)
+
+	msg := hexDiff([]byte{0, 1, 2, 3}, []byte{0, 1, 0xff, 3})
+	ez.Assert(strings.Contains(msg, "differ at byte 2"))
+	ez.Assert(strings.Contains(msg, " got: 00010203"))
+	ez.Assert(strings.Contains(msg, "want: 0001ff03"))
+	ez.Assert(strings.HasSuffix(msg, "    ^"))
+
+	msg = hexDiff(make([]byte, 40), make([]byte, 30))
+	ez.Assert(strings.Contains(msg, "differ at byte 30 (got 40 bytes, want 30)"))
+}
+
+func Test__AssertErrorIs__ShouldMatch__WrappedErrors(t *testing.T) {
+	ez := New(t)
+	target := errors.New("target")
+
+	ez.AssertErrorIs(fmt.Errorf("wrapped: %w", target), target)
+	ez.AssertBytesEqual(nil, []byte{})
+}
@@ -0,0 +1,88 @@
+package ez
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Checks is the number of random values ForAll tries
+var Checks = 100
+
+// maxShrinks bounds the shrinking of a failing value
+const maxShrinks = 1000
+
+// SeedEnv sets the seed of ForAll, to replay a failure
+const SeedEnv = "EZ_SEED"
+
+// ForAll checks that prop holds for Checks values of g. A panic fails the
+// property too. On failure, the value is shrunk into a simpler one that still
+// fails, which is reported along with the seed to replay it
+func ForAll[T any](ez *EzTest, g Gen[T], prop func(v T) bool) {
+	seed := seed()
+	r := rand.New(rand.NewPCG(seed, 0))
+
+	for i := 0; i < Checks; i++ {
+		v := g.generate(r)
+		if holds(prop, v) {
+			continue
+		}
+
+		shrunk, steps := shrinkFailure(g, prop, v)
+		defMsg := fmt.Sprintf("Property failed after %d checks (%s=%d), shrunk in %d steps to: %s", i+1, SeedEnv, seed, steps, show(shrunk))
+		ez.failWithMsg(1, defMsg, nil)
+		return
+	}
+}
+
+func seed() uint64 {
+	if s, ok := os.LookupEnv(SeedEnv); ok {
+		if r, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return r
+		}
+	}
+
+	return uint64(time.Now().UnixNano())
+}
+
+func holds[T any](prop func(v T) bool, v T) bool {
+	var r bool
+	if panics(func() { r = prop(v) }) {
+		return false
+	}
+
+	return r
+}
+
+// shrinkFailure greedily takes the first simpler candidate that still fails,
+// until none does
+func shrinkFailure[T any](g Gen[T], prop func(v T) bool, v T) (T, int) {
+	steps := 0
+	for steps < maxShrinks {
+		shrunk := false
+		for _, c := range g.shrink(v) {
+			if !holds(prop, c) {
+				v, shrunk = c, true
+				steps++
+				break
+			}
+		}
+
+		if !shrunk {
+			break
+		}
+	}
+
+	return v, steps
+}
+
+func show(v any) string {
+	switch v := v.(type) {
+	case []byte:
+		return fmt.Sprintf("%x", v)
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
@@ -0,0 +1,153 @@
+package ez
+
+import (
+	"math/rand/v2"
+)
+
+// Gen generates random values of T for ForAll, and shrinks them into simpler
+// candidates when a property fails
+type Gen[T any] struct {
+	generate func(r *rand.Rand) T
+	shrink   func(v T) []T
+}
+
+// NewGen builds a generator; shrink may be nil, for values that do not shrink
+func NewGen[T any](generate func(r *rand.Rand) T, shrink func(v T) []T) Gen[T] {
+	if shrink == nil {
+		shrink = func(T) []T { return nil }
+	}
+
+	return Gen[T]{generate, shrink}
+}
+
+// Pair holds the values of two generators
+type Pair[A any, B any] struct {
+	First  A
+	Second B
+}
+
+// Bytes generates slices with a length in [minLen, maxLen], shrinking them
+// into shorter slices and smaller bytes
+func Bytes(minLen int, maxLen int) Gen[[]byte] {
+	generate := func(r *rand.Rand) []byte {
+		bs := make([]byte, minLen+r.IntN(maxLen-minLen+1))
+		for i := range bs {
+			bs[i] = byte(r.Uint32())
+		}
+		return bs
+	}
+
+	shrink := func(bs []byte) [][]byte {
+		r := [][]byte{}
+		for _, n := range shorter(len(bs), minLen) {
+			r = append(r, bs[:n])
+		}
+
+		for i, b := range bs {
+			for _, c := range shrinkUint(uint64(b)) {
+				smaller := append([]byte{}, bs...)
+				smaller[i] = byte(c)
+				r = append(r, smaller)
+			}
+		}
+		return r
+	}
+
+	return NewGen(generate, shrink)
+}
+
+// Uint64 generates any uint64, shrinking towards 0
+func Uint64() Gen[uint64] {
+	return NewGen(func(r *rand.Rand) uint64 { return r.Uint64() }, shrinkUint)
+}
+
+// IntRange generates ints in [lo, hi], shrinking towards lo
+func IntRange(lo int, hi int) Gen[int] {
+	generate := func(r *rand.Rand) int {
+		return lo + r.IntN(hi-lo+1)
+	}
+
+	shrink := func(v int) []int {
+		r := []int{}
+		for _, c := range shrinkUint(uint64(v - lo)) {
+			r = append(r, lo+int(c))
+		}
+		return r
+	}
+
+	return NewGen(generate, shrink)
+}
+
+// SliceOf generates slices of g with a length in [minLen, maxLen], shrinking
+// them into shorter slices and then their elements
+func SliceOf[T any](g Gen[T], minLen int, maxLen int) Gen[[]T] {
+	generate := func(r *rand.Rand) []T {
+		vs := make([]T, minLen+r.IntN(maxLen-minLen+1))
+		for i := range vs {
+			vs[i] = g.generate(r)
+		}
+		return vs
+	}
+
+	shrink := func(vs []T) [][]T {
+		r := [][]T{}
+		for _, n := range shorter(len(vs), minLen) {
+			r = append(r, vs[:n])
+		}
+
+		for i := range vs {
+			for _, c := range g.shrink(vs[i]) {
+				smaller := append([]T{}, vs...)
+				smaller[i] = c
+				r = append(r, smaller)
+			}
+		}
+		return r
+	}
+
+	return NewGen(generate, shrink)
+}
+
+// PairOf generates both values independently, shrinking one at a time
+func PairOf[A any, B any](a Gen[A], b Gen[B]) Gen[Pair[A, B]] {
+	generate := func(r *rand.Rand) Pair[A, B] {
+		return Pair[A, B]{a.generate(r), b.generate(r)}
+	}
+
+	shrink := func(p Pair[A, B]) []Pair[A, B] {
+		r := []Pair[A, B]{}
+		for _, c := range a.shrink(p.First) {
+			r = append(r, Pair[A, B]{c, p.Second})
+		}
+		for _, c := range b.shrink(p.Second) {
+			r = append(r, Pair[A, B]{p.First, c})
+		}
+		return r
+	}
+
+	return NewGen(generate, shrink)
+}
+
+// shrinkUint returns 0, half of v and v-1, which are smaller than v
+func shrinkUint(v uint64) []uint64 {
+	switch v {
+	case 0:
+		return nil
+	case 1:
+		return []uint64{0}
+	case 2:
+		return []uint64{0, 1}
+	default:
+		return []uint64{0, v / 2, v - 1}
+	}
+}
+
+// shorter returns the lengths to try when shrinking a slice of length n,
+// from the shortest one
+func shorter(n int, minLen int) []int {
+	r := []int{}
+	for _, c := range shrinkUint(uint64(n - minLen)) {
+		r = append(r, minLen+int(c))
+	}
+	return r
+}
@@ -1,6 +1,8 @@
 package dl
 
 import (
+	"math/big"
+
 	"github.com/titosilva/pdpr-go/math/nmod"
 )
 
@@ -8,6 +10,10 @@ type DiscreteLogGroup struct {
 	Mod    *nmod.Mod
 	MulMod *nmod.Mod
 	Gen    *nmod.NatMod
+	// Order is the prime order of the subgroup generated by Gen, and
+	// OrderBytes its big-endian encoding
+	Order      *nmod.Mod
+	OrderBytes []byte
 }
 
 func (dlg *DiscreteLogGroup) Index(n *nmod.NatMod) (*nmod.NatMod, error) {
@@ -53,9 +59,17 @@ func NewOakley2Group() *DiscreteLogGroup {
 	mm, _ := nmod.NewModulusFromBigEndianBytes(oakley2primeMinusOneBytes)
 	g := nmod.NewFromUint(2, m)
 
+	// The prime is safe, p = 2q + 1, and 2 is a quadratic residue, so it generates the subgroup of order q
+	q := new(big.Int).SetBytes(oakley2primeBytes)
+	q.Rsh(q, 1)
+	qBytes := q.FillBytes(make([]byte, len(oakley2primeBytes)))
+	o, _ := nmod.NewModulusFromBigEndianBytes(qBytes)
+
 	return &DiscreteLogGroup{
-		Mod:    m,
-		MulMod: mm,
-		Gen:    g,
+		Mod:        m,
+		MulMod:     mm,
+		Gen:        g,
+		Order:      o,
+		OrderBytes: qBytes,
 	}
 }
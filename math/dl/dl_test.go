@@ -1,6 +1,7 @@
 package dl_test
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/titosilva/pdpr-go/math/dl"
@@ -27,13 +28,43 @@ func Test__Oakley2Group__ShouldHaveMultiplicativeOrder1LessThanGroupsOrder(t *te
 
 	// Arrange
 	og := dl.NewOakley2Group()
-	p := nmod.NewFromBigEndianBytes(oakley2primeBytes, og.Mod)
 
 	// Act
-	r := og.Gen.Exp(p)
+	r := og.Gen.ExpBytes(oakley2primeBytes)
 
 	// Assert
 	if !r.Equal(nmod.NewFromUint(2, og.Mod)) {
 		t.Errorf("Expected the result to be equals one")
 	}
 }
+
+func Test__Oakley2Group__GeneratorShouldHaveOrder__Order(t *testing.T) {
+	og := dl.NewOakley2Group()
+
+	r := og.Gen.ExpBytes(og.OrderBytes)
+
+	if !r.Equal(nmod.NewFromUint(1, og.Mod)) {
+		t.Errorf("Expected the generator to the order to be equals one")
+	}
+}
+
+func Test__Oakley2Group__GeneratorToThePrimeMinusOne__ShouldBeOne(t *testing.T) {
+	// Arrange
+	og := dl.NewOakley2Group()
+	// p - 1 = 2q, taken literally rather than reduced modulo anything
+	pMinusOne := new(big.Int).Lsh(new(big.Int).SetBytes(og.OrderBytes), 1).Bytes()
+
+	// Act
+	r := og.Gen.ExpBytes(pMinusOne)
+	reduced := og.Gen.Exp(nmod.NewFromBigEndianBytes(pMinusOne, og.Mod))
+
+	// Assert
+	if !r.Equal(nmod.NewFromUint(1, og.Mod)) {
+		t.Errorf("Expected the generator to p - 1 to be one")
+	}
+
+	// p - 1 is below p, so NewFromBigEndianBytes leaves it as is
+	if !reduced.Equal(r) {
+		t.Errorf("Expected p - 1 to be the same exponent after NewFromBigEndianBytes")
+	}
+}
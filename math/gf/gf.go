@@ -0,0 +1,129 @@
+package gf
+
+import "sync"
+
+// Field is a binary field GF(2^Bits), with Bits being 8 or 16.
+// Elements are stored in uint16 regardless of the field size, and
+// multiplication uses log/exp tables built from a primitive polynomial
+type Field struct {
+	Bits  uint
+	order int
+	exp   []uint16
+	log   []uint16
+}
+
+const (
+	gf8Poly  = 0x11d
+	gf16Poly = 0x1100b
+)
+
+var gf8 = sync.OnceValue(func() *Field { return newField(8, gf8Poly) })
+var gf16 = sync.OnceValue(func() *Field { return newField(16, gf16Poly) })
+
+// GF8 returns the field GF(2^8), whose elements fit in one byte
+func GF8() *Field {
+	return gf8()
+}
+
+// GF16 returns the field GF(2^16), whose elements fit in two bytes
+func GF16() *Field {
+	return gf16()
+}
+
+func newField(bits uint, poly uint32) *Field {
+	r := new(Field)
+	r.Bits = bits
+	r.order = 1 << bits
+	r.exp = make([]uint16, 2*(r.order-1))
+	r.log = make([]uint16, r.order)
+
+	x := uint32(1)
+	for i := 0; i < r.order-1; i++ {
+		r.exp[i] = uint16(x)
+		r.exp[i+r.order-1] = uint16(x)
+		r.log[x] = uint16(i)
+
+		x <<= 1
+		if x&uint32(r.order) != 0 {
+			x ^= poly
+		}
+	}
+
+	return r
+}
+
+// Order is the number of elements of the field
+func (f *Field) Order() int {
+	return f.order
+}
+
+// SymbolBytes is the number of bytes used to serialize an element
+func (f *Field) SymbolBytes() int {
+	return int(f.Bits / 8)
+}
+
+func (f *Field) Add(a, b uint16) uint16 {
+	return a ^ b
+}
+
+func (f *Field) Sub(a, b uint16) uint16 {
+	return a ^ b
+}
+
+func (f *Field) Mul(a, b uint16) uint16 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return f.exp[int(f.log[a])+int(f.log[b])]
+}
+
+// Div panics on division by zero, as integer division does
+func (f *Field) Div(a, b uint16) uint16 {
+	if b == 0 {
+		panic("division by zero")
+	}
+
+	if a == 0 {
+		return 0
+	}
+
+	return f.exp[int(f.log[a])+f.order-1-int(f.log[b])]
+}
+
+func (f *Field) Inv(a uint16) uint16 {
+	return f.Div(1, a)
+}
+
+// Exp returns a raised to n
+func (f *Field) Exp(a uint16, n int) uint16 {
+	if n == 0 {
+		return 1
+	}
+
+	if a == 0 {
+		return 0
+	}
+
+	return f.exp[(int(f.log[a])*n)%(f.order-1)]
+}
+
+// Symbol reads the i-th element serialized in bs
+func (f *Field) Symbol(bs []byte, i int) uint16 {
+	if f.Bits == 8 {
+		return uint16(bs[i])
+	}
+
+	return uint16(bs[2*i])<<8 | uint16(bs[2*i+1])
+}
+
+// SetSymbol writes v as the i-th element serialized in bs
+func (f *Field) SetSymbol(bs []byte, i int, v uint16) {
+	if f.Bits == 8 {
+		bs[i] = byte(v)
+		return
+	}
+
+	bs[2*i] = byte(v >> 8)
+	bs[2*i+1] = byte(v)
+}
@@ -0,0 +1,49 @@
+package gf_test
+
+import (
+	"testing"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/math/gf"
+)
+
+func Test__GF8__MulThenDiv__ShouldReturnOriginalValue(t *testing.T) {
+	ez := ez.New(t)
+	f := gf.GF8()
+
+	for a := 0; a < f.Order(); a++ {
+		for b := 1; b < f.Order(); b++ {
+			ez.Assert(f.Div(f.Mul(uint16(a), uint16(b)), uint16(b)) == uint16(a))
+		}
+	}
+}
+
+func Test__GF16__Inv__ShouldMultiplyToOne(t *testing.T) {
+	ez := ez.New(t)
+	f := gf.GF16()
+
+	for a := 1; a < f.Order(); a += 7 {
+		ez.Assert(f.Mul(uint16(a), f.Inv(uint16(a))) == 1)
+	}
+}
+
+func Test__GF8__Mul__ShouldMatch__KnownValues(t *testing.T) {
+	ez := ez.New(t)
+	f := gf.GF8()
+
+	// x^7 * x = x^8 = x^4 + x^3 + x^2 + 1 modulo 0x11d
+	ez.Assert(f.Mul(0x80, 0x02) == 0x1d)
+	ez.Assert(f.Exp(0x02, 8) == 0x1d)
+	ez.Assert(f.Exp(0x02, 255) == 1)
+}
+
+func Test__GF16__Symbol__ShouldRoundTrip(t *testing.T) {
+	ez := ez.New(t)
+	f := gf.GF16()
+	bs := make([]byte, 4)
+
+	f.SetSymbol(bs, 1, 0xcafe)
+
+	ez.AssertAreEqual(bs, []byte{0x00, 0x00, 0xca, 0xfe})
+	ez.Assert(f.Symbol(bs, 1) == 0xcafe)
+}
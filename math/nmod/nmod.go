@@ -16,38 +16,57 @@ type NatMod struct {
 
 type Mod struct {
 	value   *bigmod.Modulus
+	big     *big.Int
 	hash    string
 	byteLen int
 }
 
+// ErrDifferentModulus is returned by operations on numbers of different moduli.
+// Their Must* variants panic instead, for numbers known to share their modulus
 var ErrDifferentModulus = errors.New("different modulus")
 
 func ensureSameModulus(i, j *NatMod) error {
 	return i.modulus.Equal(j.modulus)
 }
 
+func must(r *NatMod, err error) *NatMod {
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
 func new(value *bigmod.Nat, modulus *Mod) *NatMod {
 	return &NatMod{value, modulus}
 }
 
-func newNatFromUint(value uint64, modulus *Mod) *bigmod.Nat {
+// natFromBytes reduces the big-endian value modulo the modulus
+func natFromBytes(value []byte, modulus *Mod) *bigmod.Nat {
 	r := bigmod.NewNat()
-	valueBuf := make([]byte, 8)
-	binary.BigEndian.PutUint64(valueBuf, value)
+	if _, err := r.SetBytes(value, modulus.value); err != nil {
+		// value is not smaller than the modulus
+		var reduced big.Int
+		reduced.Mod(big.NewInt(0).SetBytes(value), modulus.big)
+		r.SetBytes(reduced.Bytes(), modulus.value)
+	}
 
-	r.SetBytes(valueBuf, modulus.value)
 	return r
 }
 
+func newNatFromUint(value uint64, modulus *Mod) *bigmod.Nat {
+	return natFromBytes(binary.BigEndian.AppendUint64(nil, value), modulus)
+}
+
+// NewFromUint reduces value modulo the modulus
 func NewFromUint(value uint64, modulus *Mod) *NatMod {
 	r := newNatFromUint(value, modulus)
 	return new(r, modulus)
 }
 
+// NewFromBigEndianBytes reduces value modulo the modulus
 func NewFromBigEndianBytes(value []byte, modulus *Mod) *NatMod {
-	r := bigmod.NewNat()
-	r.SetBytes(value, modulus.value)
-	return new(r, modulus)
+	return new(natFromBytes(value, modulus), modulus)
 }
 
 func NewModulusFromInt(value uint64) (*Mod, error) {
@@ -62,7 +81,7 @@ func NewModulusFromInt(value uint64) (*Mod, error) {
 	sha.Write(biBytes)
 	hash := string(sha.Sum(nil))
 
-	return &Mod{mod, hash, len(biBytes)}, nil
+	return &Mod{mod, bi, hash, len(biBytes)}, nil
 }
 
 func NewModulusFromBigEndianBytes(value []byte) (*Mod, error) {
@@ -73,23 +92,31 @@ func NewModulusFromBigEndianBytes(value []byte) (*Mod, error) {
 		return nil, err
 	}
 
+	// leading zeros do not make a different modulus
+	biBytes := bi.Bytes()
 	sha := sha256.New()
-	sha.Write(bi.Bytes())
+	sha.Write(biBytes)
 	hash := string(sha.Sum(nil))
 
-	return &Mod{mod, hash, len(value)}, nil
+	return &Mod{mod, bi, hash, len(biBytes)}, nil
 }
 
-func (i *NatMod) Add(j *NatMod) *NatMod {
+// Add returns ErrDifferentModulus if i and j have different moduli
+func (i *NatMod) Add(j *NatMod) (*NatMod, error) {
 	if err := ensureSameModulus(i, j); err != nil {
-		return nil
+		return nil, err
 	}
 
 	result := NewFromUint(0, i.modulus)
 	result.value.Add(i.value, result.modulus.value)
 	result.value.Add(j.value, result.modulus.value)
 
-	return result
+	return result, nil
+}
+
+// MustAdd is like Add, but panics if i and j have different moduli
+func (i *NatMod) MustAdd(j *NatMod) *NatMod {
+	return must(i.Add(j))
 }
 
 func (i *NatMod) Exp(exp *NatMod) *NatMod {
@@ -102,28 +129,40 @@ func (i *NatMod) ExpBytes(exp []byte) *NatMod {
 	return new(result, i.modulus)
 }
 
-func (i *NatMod) Mul(j *NatMod) *NatMod {
+// Mul returns ErrDifferentModulus if i and j have different moduli
+func (i *NatMod) Mul(j *NatMod) (*NatMod, error) {
 	if err := ensureSameModulus(i, j); err != nil {
-		return nil
+		return nil, err
 	}
 
 	result := NewFromUint(0, i.modulus)
 	result.value.Add(i.value, result.modulus.value)
 	result.value.Mul(j.value, result.modulus.value)
 
-	return result
+	return result, nil
+}
+
+// MustMul is like Mul, but panics if i and j have different moduli
+func (i *NatMod) MustMul(j *NatMod) *NatMod {
+	return must(i.Mul(j))
 }
 
-func (i *NatMod) Sub(j *NatMod) *NatMod {
+// Sub returns ErrDifferentModulus if i and j have different moduli
+func (i *NatMod) Sub(j *NatMod) (*NatMod, error) {
 	if err := ensureSameModulus(i, j); err != nil {
-		return nil
+		return nil, err
 	}
 
 	result := NewFromUint(0, i.modulus)
 	result.value.Add(i.value, result.modulus.value)
 	result.value.Sub(j.value, result.modulus.value)
 
-	return result
+	return result, nil
+}
+
+// MustSub is like Sub, but panics if i and j have different moduli
+func (i *NatMod) MustSub(j *NatMod) *NatMod {
+	return must(i.Sub(j))
 }
 
 func (i *NatMod) Equal(j *NatMod) bool {
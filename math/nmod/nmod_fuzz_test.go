@@ -0,0 +1,79 @@
+package nmod_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/math/nmod"
+)
+
+// maxFuzzSize bounds the moduli and values, in bytes
+const maxFuzzSize = 64
+
+func FuzzNatMod__Arithmetic__ShouldMatch__BigInt(f *testing.F) {
+	f.Add([]byte{7}, []byte{4}, []byte{3}, []byte{2}, uint64(9))
+	f.Add([]byte{0xff, 0xff, 0xff, 0xfb}, []byte{0xff, 0xff, 0xff, 0xff, 0xff}, []byte{0}, []byte{0xff, 0xff}, uint64(0))
+	f.Add([]byte{0, 0, 1, 1}, []byte{1, 1}, []byte{1, 2}, []byte{}, ^uint64(0))
+
+	f.Fuzz(func(t *testing.T, m []byte, a []byte, b []byte, e []byte, u uint64) {
+		if len(m) == 0 || len(m) > maxFuzzSize || len(a) > maxFuzzSize || len(b) > maxFuzzSize || len(e) > maxFuzzSize {
+			return
+		}
+
+		// bigmod only supports odd moduli
+		m[len(m)-1] |= 1
+		mBig := new(big.Int).SetBytes(m)
+		if mBig.Cmp(big.NewInt(1)) == 0 {
+			return
+		}
+
+		mod, err := nmod.NewModulusFromBigEndianBytes(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		x := nmod.NewFromBigEndianBytes(a, mod)
+		y := nmod.NewFromBigEndianBytes(b, mod)
+		xBig := new(big.Int).Mod(new(big.Int).SetBytes(a), mBig)
+		yBig := new(big.Int).Mod(new(big.Int).SetBytes(b), mBig)
+
+		check := func(op string, got *nmod.NatMod, err error, want *big.Int) {
+			t.Helper()
+			if err != nil {
+				t.Fatalf("%s: %v", op, err)
+			}
+
+			want.Mod(want, mBig)
+			if new(big.Int).SetBytes(got.Bytes()).Cmp(want) != 0 {
+				t.Fatalf("%s mod %x: got %x, want %x", op, mBig, got.Bytes(), want)
+			}
+		}
+
+		check("NewFromBigEndianBytes", x, nil, new(big.Int).Set(xBig))
+		check("NewFromUint", nmod.NewFromUint(u, mod), nil, new(big.Int).SetUint64(u))
+		sum, err := x.Add(y)
+		check("Add", sum, err, new(big.Int).Add(xBig, yBig))
+		difference, err := x.Sub(y)
+		check("Sub", difference, err, new(big.Int).Sub(xBig, yBig))
+		product, err := x.Mul(y)
+		check("Mul", product, err, new(big.Int).Mul(xBig, yBig))
+		check("ExpBytes", x.ExpBytes(e), nil, new(big.Int).Exp(xBig, new(big.Int).SetBytes(e), mBig))
+
+		padded, err := nmod.NewModulusFromBigEndianBytes(append([]byte{0}, m...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !x.ModulusIs(padded) {
+			t.Fatal("leading zeros changed the modulus")
+		}
+
+		other, err := nmod.NewModulusFromBigEndianBytes(new(big.Int).Add(mBig, big.NewInt(2)).Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := x.Add(nmod.NewFromUint(1, other)); !errors.Is(err, nmod.ErrDifferentModulus) {
+			t.Fatal("numbers of different moduli were added")
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package nmod_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/titosilva/pdpr-go/math/nmod"
@@ -13,7 +14,7 @@ func Test__Add__ShouldAddNumbersCorrectly__WhenNumbersHaveSameModulus(t *testing
 	m2, _ := nmod.NewModulusFromInt(7)
 	n2 := nmod.NewFromUint(3, m2)
 
-	result := n1.Add(n2)
+	result := n1.MustAdd(n2)
 	zero := nmod.NewFromUint(0, m1)
 
 	if !result.Equal(zero) {
@@ -28,7 +29,7 @@ func Test__Add__ShouldAddNumbersCorrectly__WhenNumbersHaveSameModulus__2(t *test
 	m2, _ := nmod.NewModulusFromInt(7)
 	n2 := nmod.NewFromUint(5, m2)
 
-	result := n1.Add(n2)
+	result := n1.MustAdd(n2)
 	two := nmod.NewFromUint(2, m1)
 
 	if !result.Equal(two) {
@@ -43,10 +44,10 @@ func Test__Add__ShouldReturnError__WhenNumbersHaveDifferentModulus(t *testing.T)
 	m2, _ := nmod.NewModulusFromInt(5)
 	n2 := nmod.NewFromUint(3, m2)
 
-	result := n1.Add(n2)
+	result, err := n1.Add(n2)
 
-	if result != nil {
-		t.Errorf("Expected nil to be returned")
+	if result != nil || !errors.Is(err, nmod.ErrDifferentModulus) {
+		t.Errorf("Expected ErrDifferentModulus to be returned")
 	}
 }
 
@@ -57,7 +58,7 @@ func Test__Sub__ShouldSubtractNumbersCorrectly__WhenNumbersHaveSameModulus(t *te
 	m2, _ := nmod.NewModulusFromInt(7)
 	n2 := nmod.NewFromUint(3, m2)
 
-	result := n1.Sub(n2)
+	result := n1.MustSub(n2)
 	one := nmod.NewFromUint(1, m1)
 
 	if !result.Equal(one) {
@@ -72,10 +73,10 @@ func Test__Sub__ShouldReturnError__WhenNumbersHaveDifferentModulus(t *testing.T)
 	m2, _ := nmod.NewModulusFromInt(5)
 	n2 := nmod.NewFromUint(3, m2)
 
-	result := n1.Sub(n2)
+	result, err := n1.Sub(n2)
 
-	if result != nil {
-		t.Errorf("Expected nil to be returned")
+	if result != nil || !errors.Is(err, nmod.ErrDifferentModulus) {
+		t.Errorf("Expected ErrDifferentModulus to be returned")
 	}
 }
 
@@ -2,7 +2,10 @@ package uintp
 
 import (
 	"encoding/hex"
+	"errors"
 	"math/bits"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
 )
 
 // UintP is a big integer with a modulus of 2^ModulusBitsize
@@ -13,28 +16,67 @@ type UintP struct {
 	value          []uint64
 }
 
-func New(modBitsize uint64) *UintP {
-	if modBitsize%64 != 0 {
-		panic("p must be a multiple of 64")
+var ErrInvalidModulus = errors.New("modulus bitsize must be a positive multiple of 64")
+var ErrInvalidHex = errors.New("invalid hex string")
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// CheckModulus returns ErrInvalidModulus if modBitsize cannot be the modulus of an UintP
+func CheckModulus(modBitsize uint64) error {
+	if modBitsize == 0 || modBitsize%64 != 0 {
+		return errorutils.NewfWithInner(ErrInvalidModulus, "got %d bits", modBitsize)
 	}
 
+	return nil
+}
+
+func New(modBitsize uint64) (*UintP, error) {
+	if err := CheckModulus(modBitsize); err != nil {
+		return nil, err
+	}
+
+	return zero(modBitsize), nil
+}
+
+// MustNew is like New, but panics if the modulus is invalid
+func MustNew(modBitsize uint64) *UintP {
+	return must(New(modBitsize))
+}
+
+func zero(modBitsize uint64) *UintP {
 	return &UintP{
 		ModulusBitsize: modBitsize,
 		value:          make([]uint64, modBitsize/64),
 	}
 }
 
-func FromUint(p uint64, u uint64) *UintP {
-	r := New(p)
+func must(u *UintP, err error) *UintP {
+	if err != nil {
+		panic(err)
+	}
+
+	return u
+}
+
+func FromUint(p uint64, u uint64) (*UintP, error) {
+	r, err := New(p)
+	if err != nil {
+		return nil, err
+	}
+
 	r.value[0] = u
+	return r, nil
+}
 
-	return r
+// MustFromUint is like FromUint, but panics if the modulus is invalid
+func MustFromUint(p uint64, u uint64) *UintP {
+	return must(FromUint(p, u))
 }
 
-func FromHex(p uint64, s string) *UintP {
+// FromHex parses a big-endian hex string
+func FromHex(p uint64, s string) (*UintP, error) {
 	bs, err := hex.DecodeString(s)
 	if err != nil {
-		panic(err)
+		return nil, errorutils.NewWithInner(ErrInvalidHex, err.Error())
 	}
 
 	// reverse bs
@@ -45,8 +87,27 @@ func FromHex(p uint64, s string) *UintP {
 	return FromBytes(p, bs)
 }
 
-func FromBytes(p uint64, bs []byte) *UintP {
-	r := New(p)
+// MustFromHex is like FromHex, but panics if the modulus or the string is invalid
+func MustFromHex(p uint64, s string) *UintP {
+	return must(FromHex(p, s))
+}
+
+// FromBytes reads little-endian bytes, ignoring those beyond the modulus
+func FromBytes(p uint64, bs []byte) (*UintP, error) {
+	if err := CheckModulus(p); err != nil {
+		return nil, err
+	}
+
+	return fromBytes(p, bs), nil
+}
+
+// MustFromBytes is like FromBytes, but panics if the modulus is invalid
+func MustFromBytes(p uint64, bs []byte) *UintP {
+	return must(FromBytes(p, bs))
+}
+
+func fromBytes(p uint64, bs []byte) *UintP {
+	r := zero(p)
 
 	for i := range bs {
 		if i >= len(r.value)*8 {
@@ -76,7 +137,7 @@ func (u *UintP) Add(v *UintP) *UintP {
 }
 
 func (u *UintP) Mul(v *UintP) *UintP {
-	f := FromUint(u.ModulusBitsize, 0)
+	f := zero(u.ModulusBitsize)
 
 	for i := range v.value {
 		r := Clone(u)
@@ -93,16 +154,17 @@ func (u *UintP) MulUint(v uint64) *UintP {
 	carry := uint64(0)
 
 	for i := range u.value {
-		nxt_carry, lo := bits.Mul64(u.value[i], v)
-		u.value[i] = lo + carry
-		carry = nxt_carry
+		hi, lo := bits.Mul64(u.value[i], v)
+		var c uint64
+		u.value[i], c = bits.Add64(lo, carry, 0)
+		carry = hi + c
 	}
 
 	return u
 }
 
 func (u *UintP) AddBytes(bs []byte) *UintP {
-	return u.Add(FromBytes(u.ModulusBitsize, bs[:]))
+	return u.Add(fromBytes(u.ModulusBitsize, bs))
 }
 
 func (u *UintP) AddUint(v uint64) *UintP {
@@ -143,7 +205,7 @@ func (u *UintP) SubBytes(bs []byte) *UintP {
 }
 
 func (u *UintP) Inverse() *UintP {
-	r := New(u.ModulusBitsize)
+	r := zero(u.ModulusBitsize)
 	for i := range u.value {
 		r.value[i] = ^u.value[i]
 	}
@@ -199,17 +261,30 @@ func (u *UintP) Bytes() []byte {
 	return r
 }
 
-func (u *UintP) Bit(index uint64) byte {
+func (u *UintP) checkIndex(index uint64) error {
 	if index >= u.ModulusBitsize {
-		panic("index out of range")
+		return errorutils.NewfWithInner(ErrIndexOutOfRange, "bit %d of a %d bits integer", index, u.ModulusBitsize)
 	}
 
-	return byte(u.value[index/64] & (1 << (index % 64)))
+	return nil
 }
 
-func (u *UintP) SetBit(index uint64, bit bool) *UintP {
-	if index >= u.ModulusBitsize {
-		panic("index out of range")
+func (u *UintP) Bit(index uint64) (byte, error) {
+	if err := u.checkIndex(index); err != nil {
+		return 0, err
+	}
+
+	return byte(u.value[index/64]>>(index%64)) & 1, nil
+}
+
+// LowBit returns the least significant bit, which every UintP has
+func (u *UintP) LowBit() byte {
+	return byte(u.value[0]) & 1
+}
+
+func (u *UintP) SetBit(index uint64, bit bool) (*UintP, error) {
+	if err := u.checkIndex(index); err != nil {
+		return nil, err
 	}
 
 	if bit {
@@ -218,5 +293,27 @@ func (u *UintP) SetBit(index uint64, bit bool) *UintP {
 		u.value[index/64] &= ^(1 << (index % 64))
 	}
 
-	return u
+	return u, nil
+}
+
+// Uint64 returns the value modulo 2^64
+func (u *UintP) Uint64() uint64 {
+	return u.value[0]
+}
+
+// TrailingZeros returns the number of trailing zero bits, which is the
+// modulus bitsize for 0
+func (u *UintP) TrailingZeros() uint64 {
+	for i, v := range u.value {
+		if v != 0 {
+			return uint64(i)*64 + uint64(bits.TrailingZeros64(v))
+		}
+	}
+
+	return u.ModulusBitsize
+}
+
+// SetLowBit sets the least significant bit, which every UintP has
+func (u *UintP) SetLowBit(bit bool) *UintP {
+	return must(u.SetBit(0, bit))
 }
@@ -0,0 +1,101 @@
+package uintp_test
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/math/uintp"
+)
+
+// Every operation is checked against math/big, modulo 2^bits
+
+func fuzzBits(size byte) uint64 {
+	return 64 * (1 + uint64(size%4))
+}
+
+func toBig(u *uintp.UintP) *big.Int {
+	bs := u.Bytes()
+	slices.Reverse(bs)
+
+	return new(big.Int).SetBytes(bs)
+}
+
+// expected reduces the little-endian bytes bs, truncated as FromBytes does, modulo 2^bits
+func expected(bits uint64, bs []byte) *big.Int {
+	bs = slices.Clone(bs[:min(len(bs), int(bits/8))])
+	slices.Reverse(bs)
+
+	return new(big.Int).SetBytes(bs)
+}
+
+func reduce(bits uint64, v *big.Int) *big.Int {
+	modulus := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	return v.Mod(v, modulus)
+}
+
+func FuzzUintp__Arithmetic__ShouldMatch__BigInt(f *testing.F) {
+	f.Add(byte(0), []byte{1}, []byte{2}, uint64(3), uint16(1))
+	f.Add(byte(1), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, []byte{0xfe, 0xca}, uint64(1<<63), uint16(64))
+	f.Add(byte(3), slices.Repeat([]byte{0xff}, 32), slices.Repeat([]byte{0xff}, 32), ^uint64(0), uint16(255))
+
+	f.Fuzz(func(t *testing.T, size byte, a []byte, b []byte, c uint64, shift uint16) {
+		bits := fuzzBits(size)
+		u := uintp.MustFromBytes(bits, a)
+		v := uintp.MustFromBytes(bits, b)
+		x, y := expected(bits, a), expected(bits, b)
+
+		check := func(op string, got *uintp.UintP, want *big.Int) {
+			t.Helper()
+			if toBig(got).Cmp(reduce(bits, want)) != 0 {
+				t.Fatalf("%s of %d bits: got %x, want %x", op, bits, toBig(got), reduce(bits, want))
+			}
+		}
+
+		check("FromBytes", u, new(big.Int).Set(x))
+		check("Add", uintp.Clone(u).Add(v), new(big.Int).Add(x, y))
+		check("Sub", uintp.Clone(u).Sub(v), new(big.Int).Sub(x, y))
+		check("SubBytes", uintp.Clone(u).SubBytes(v.Bytes()), new(big.Int).Sub(x, y))
+		check("Mul", uintp.Clone(u).Mul(v), new(big.Int).Mul(x, y))
+		check("MulUint", uintp.Clone(u).MulUint(c), new(big.Int).Mul(x, new(big.Int).SetUint64(c)))
+		check("AddUint", uintp.Clone(u).AddUint(c), new(big.Int).Add(x, new(big.Int).SetUint64(c)))
+		check("Inverse", u.Inverse(), new(big.Int).Neg(x))
+		check("ShiftLeft", uintp.Clone(u).ShiftLeft(uint64(shift)), new(big.Int).Lsh(x, uint(shift)))
+
+		if !uintp.MustFromBytes(bits, u.Bytes()).Equals(u) {
+			t.Fatalf("Bytes of %x does not round trip", x)
+		}
+
+		if !uintp.Clone(u).Add(u.Inverse()).Equals(uintp.MustNew(bits)) {
+			t.Fatalf("%x plus its inverse is not zero", x)
+		}
+	})
+}
+
+func FuzzUintp__FromHex__ShouldMatch__BigInt(f *testing.F) {
+	f.Add(byte(0), "01fffffffffffffffe")
+	f.Add(byte(1), "cafe")
+	f.Add(byte(2), "not hex")
+
+	f.Fuzz(func(t *testing.T, size byte, s string) {
+		bits := fuzzBits(size)
+		u, err := uintp.FromHex(bits, s)
+
+		want, ok := new(big.Int).SetString(s, 16)
+		if err != nil {
+			// hex.DecodeString only accepts an even number of digits, without a sign
+			if ok && len(s)%2 == 0 && s[0] != '+' && s[0] != '-' {
+				t.Fatalf("%q was rejected: %v", s, err)
+			}
+			return
+		}
+
+		if s == "" {
+			want = new(big.Int)
+		}
+
+		if toBig(u).Cmp(reduce(bits, want)) != 0 {
+			t.Fatalf("%q parsed as %x", s, toBig(u))
+		}
+	})
+}
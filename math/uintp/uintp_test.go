@@ -2,6 +2,7 @@ package uintp_test
 
 import (
 	"encoding/hex"
+	"errors"
 	"testing"
 
 	"github.com/titosilva/pdpr-go/internal/ez"
@@ -24,10 +25,10 @@ func Test__Uintp__Add__ShouldEqual__Sum(t *testing.T) {
 	for _, tc := range testCasesAdd {
 		ez := ez.New(t)
 
-		u := uintp.FromHex(tc.p, tc.u)
-		v := uintp.FromHex(tc.p, tc.v)
+		u := uintp.MustFromHex(tc.p, tc.u)
+		v := uintp.MustFromHex(tc.p, tc.v)
 
-		exp := uintp.FromHex(tc.p, tc.exp)
+		exp := uintp.MustFromHex(tc.p, tc.exp)
 
 		r := u.Add(v)
 		ez.Assert(r.Equals(exp))
@@ -61,8 +62,8 @@ func Test__Uintp__ShiftLeft__ShouldEqual__HardcodedResult(t *testing.T) {
 	for _, tc := range testCasesShiftLeft {
 		ez := ez.New(t)
 
-		u := uintp.FromHex(tc.p, tc.u)
-		exp := uintp.FromHex(tc.p, tc.exp)
+		u := uintp.MustFromHex(tc.p, tc.u)
+		exp := uintp.MustFromHex(tc.p, tc.exp)
 
 		r := u.ShiftLeft(tc.shift)
 		ez.Assert(r.Equals(exp))
@@ -70,7 +71,7 @@ func Test__Uintp__ShiftLeft__ShouldEqual__HardcodedResult(t *testing.T) {
 }
 
 func Test__Uintp__MulUint__PowerOf2__ShouldEqual__ShiftLeft(t *testing.T) {
-	u := uintp.FromUint(128, 1)
+	u := uintp.MustFromUint(128, 1)
 	v := uintp.Clone(u)
 
 	ez := ez.New(t)
@@ -78,7 +79,7 @@ func Test__Uintp__MulUint__PowerOf2__ShouldEqual__ShiftLeft(t *testing.T) {
 }
 
 func Test__Uintp__MulUint__PowerOf2WithOverflow__ShouldEqual__ShiftLeft(t *testing.T) {
-	u := uintp.FromBytes(128, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	u := uintp.MustFromBytes(128, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
 	v := uintp.Clone(u)
 
 	r := u.MulUint(uint64(2))
@@ -101,7 +102,7 @@ var testCasesMulUintOverflow = []struct {
 
 func Test__Uintp__MulUint__PowerOf2WithOverflow__ShouldEqual__HardcodedResult(t *testing.T) {
 	for _, tc := range testCasesMulUintOverflow {
-		u := uintp.FromHex(tc.p, tc.u)
+		u := uintp.MustFromHex(tc.p, tc.u)
 		bs, _ := hex.DecodeString(tc.v)
 		v := uint64(0)
 
@@ -111,15 +112,15 @@ func Test__Uintp__MulUint__PowerOf2WithOverflow__ShouldEqual__HardcodedResult(t
 
 		r := u.MulUint(v)
 
-		exp := uintp.FromHex(tc.p, tc.exp)
+		exp := uintp.MustFromHex(tc.p, tc.exp)
 		ez := ez.New(t)
 		ez.Assert(r.Equals(exp))
 	}
 }
 
 func Test__Uintp__Mul__PowerOf2__ShouldEqual__ShiftLeft(t *testing.T) {
-	u := uintp.FromUint(128, 1)
-	v := uintp.FromUint(128, 1<<32)
+	u := uintp.MustFromUint(128, 1)
+	v := uintp.MustFromUint(128, 1<<32)
 
 	u_cp := uintp.Clone(u)
 
@@ -144,10 +145,10 @@ func Test__Uintp__Mul__LargeNumbers__ShouldEqual__RightAnswer(t *testing.T) {
 	for _, tc := range testCases {
 		ez := ez.New(t)
 
-		u := uintp.FromHex(tc.p, tc.u)
-		v := uintp.FromHex(tc.p, tc.v)
+		u := uintp.MustFromHex(tc.p, tc.u)
+		v := uintp.MustFromHex(tc.p, tc.v)
 
-		exp := uintp.FromHex(tc.p, tc.exp)
+		exp := uintp.MustFromHex(tc.p, tc.exp)
 
 		r := u.Mul(v)
 		ez.Assert(r.Equals(exp))
@@ -157,15 +158,46 @@ func Test__Uintp__Mul__LargeNumbers__ShouldEqual__RightAnswer(t *testing.T) {
 func Test__Uintp__FromHex__Should__ConvertCorrectly(t *testing.T) {
 	ez := ez.New(t)
 
-	u := uintp.FromHex(128, "01fffffffffffffffe")
-	exp := uintp.FromBytes(128, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	u := uintp.MustFromHex(128, "01fffffffffffffffe")
+	exp := uintp.MustFromBytes(128, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
 	ez.Assert(u.Equals(exp))
 }
 
 func Test__Uintp__SetBit__Should__EqualShiftLeft__WhenOtherBitsAreZero(t *testing.T) {
-	u := uintp.FromUint(128, 0)
-	v := uintp.FromUint(128, 1)
+	u := uintp.MustFromUint(128, 0)
+	v := uintp.MustFromUint(128, 1)
 
 	ez := ez.New(t)
-	ez.Assert(u.SetBit(3, true).Equals(v.ShiftLeft(3)))
+	set, err := u.SetBit(3, true)
+	ez.AssertNoError(err)
+	ez.Assert(set.Equals(v.ShiftLeft(3)))
+
+	bit, err := set.Bit(3)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(bit, byte(1))
+}
+
+func Test__Uintp__ShouldReturnErrors__OnInvalidInput(t *testing.T) {
+	ez := ez.New(t)
+
+	_, err := uintp.New(100)
+	ez.Assert(errors.Is(err, uintp.ErrInvalidModulus))
+	_, err = uintp.New(0)
+	ez.Assert(errors.Is(err, uintp.ErrInvalidModulus))
+	_, err = uintp.FromHex(64, "xyz")
+	ez.Assert(errors.Is(err, uintp.ErrInvalidHex))
+
+	u := uintp.MustFromUint(64, 1)
+	_, err = u.Bit(64)
+	ez.Assert(errors.Is(err, uintp.ErrIndexOutOfRange))
+	_, err = u.SetBit(64, true)
+	ez.Assert(errors.Is(err, uintp.ErrIndexOutOfRange))
+}
+
+func Test__UintP__TrailingZeros__ShouldCountLowZeroBits(t *testing.T) {
+	ez := ez.New(t)
+
+	ez.AssertAreEqual(uintp.MustFromUint(128, 12).TrailingZeros(), uint64(2))
+	ez.AssertAreEqual(uintp.MustFromHex(128, "0100000000000000000000").TrailingZeros(), uint64(80))
+	ez.AssertAreEqual(uintp.MustNew(128).TrailingZeros(), uint64(128))
 }
@@ -0,0 +1,213 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr/audit"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+	"github.com/titosilva/pdpr-go/pdpr/public"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func Test__Log__Reopen__ShouldDetectTampering(t *testing.T) {
+	ez := ez.New(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	log, err := audit.OpenLog(path)
+	ez.AssertNoError(err)
+	for i, verdict := range []audit.Verdict{audit.VerdictPass, audit.VerdictFail, audit.VerdictPass} {
+		_, err := log.Append(audit.Entry{Time: start.Add(time.Duration(i) * time.Hour), FileID: "a", Verdict: verdict})
+		ez.AssertNoError(err)
+	}
+	head := log.Head()
+	ez.AssertNoError(log.Close())
+
+	log, err = audit.OpenLog(path)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(log.Len(), 3)
+	ez.AssertAreEqual(log.Head(), head)
+	ez.AssertNoError(log.Verify())
+	log.Close()
+
+	// hide the failure
+	bs, _ := os.ReadFile(path)
+	ez.AssertNoError(os.WriteFile(path, []byte(strings.Replace(string(bs), `"fail"`, `"pass"`, 1)), 0600))
+
+	_, err = audit.OpenLog(path)
+	ez.Assert(errors.Is(err, audit.ErrTampered))
+}
+
+func Test__Log__Reopen__ShouldDiscard__TornEntry(t *testing.T) {
+	ez := ez.New(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	log, _ := audit.OpenLog(path)
+	log.Append(audit.Entry{Time: start, FileID: "a", Verdict: audit.VerdictPass})
+	head := log.Head()
+	log.Append(audit.Entry{Time: start, FileID: "a", Verdict: audit.VerdictPass})
+	log.Close()
+
+	stat, _ := os.Stat(path)
+	ez.AssertNoError(os.Truncate(path, stat.Size()-5))
+
+	log, err := audit.OpenLog(path)
+	ez.AssertNoError(err)
+	defer log.Close()
+
+	ez.AssertAreEqual(log.Len(), 1)
+	ez.AssertAreEqual(log.Head(), head)
+
+	e, err := log.Append(audit.Entry{Time: start, FileID: "a", Verdict: audit.VerdictFail})
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(e.Seq, uint64(1))
+	ez.Assert(log.Contains(head))
+}
+
+func Test__Log__Append__ShouldRefuseFurtherEntries__WhenTheFileCannotBeWritten(t *testing.T) {
+	ez := ez.New(t)
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	log, _ := audit.OpenLog(path)
+	log.Append(audit.Entry{Time: start, FileID: "a", Verdict: audit.VerdictPass})
+	head := log.Head()
+	ez.AssertNoError(log.Close())
+
+	// neither the write nor the truncation that would undo it can succeed
+	_, err := log.Append(audit.Entry{Time: start, FileID: "a", Verdict: audit.VerdictFail})
+	ez.AssertErrorIs(err, audit.ErrLogFailed)
+	_, err = log.Append(audit.Entry{Time: start, FileID: "a", Verdict: audit.VerdictFail})
+	ez.AssertErrorIs(err, audit.ErrLogFailed)
+	ez.AssertAreEqual(log.Len(), 1)
+	ez.AssertAreEqual(log.Head(), head)
+
+	log, err = audit.OpenLog(path)
+	ez.AssertNoError(err)
+	defer log.Close()
+	ez.AssertAreEqual(log.Len(), 1)
+}
+
+func Test__Log__Entries__ShouldReturnCopies(t *testing.T) {
+	ez := ez.New(t)
+	log := audit.NewLog()
+
+	appended, _ := log.Append(audit.Entry{Time: start, FileID: "a", Verdict: audit.VerdictFail, Detail: "lost"})
+	appended.Detail = "fine"
+	appended.Hash[0] ^= 1
+
+	for _, e := range log.Failures("a") {
+		e.Verdict = audit.VerdictPass
+		e.Prev[0] ^= 1
+	}
+
+	ez.AssertAreEqual(log.Failures("a")[0].Detail, "lost")
+	ez.AssertNoError(log.Verify())
+}
+
+// fileServer answers fresh challenges for a public file, and can lose data
+type fileServer struct {
+	ciphertext []byte
+	tags       *public.Tags
+	now        *time.Time
+}
+
+func (s *fileServer) respond(ctx context.Context, c *freshness.Challenge) (*freshness.Response, error) {
+	return freshness.Respond(c, *s.now, func(expanded *por.Challenge) ([]byte, error) {
+		proof, err := public.Prove(s.ciphertext, s.tags, expanded)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(proof)
+	})
+}
+
+func Test__Scheduler__ShouldAuditPeriodically__AndRecordFailures(t *testing.T) {
+	ez := ez.New(t)
+	now := start
+	clock := func() time.Time { return now }
+
+	ct := gcrypt.MustNew(64).MustEncrypt([]byte("Hello, World!"), []byte("This is a key"))
+	tags, info, _ := public.NewTags(public.Params{SectorCount: 2}, ct)
+	server := &fileServer{ciphertext: ct, tags: tags, now: &now}
+
+	verifier := freshness.NewVerifier(time.Minute)
+	verifier.Clock = clock
+	auditor := &audit.PublicAuditor{
+		Verifier:    verifier,
+		Infos:       map[string]*public.PublicInfo{"hello": info},
+		SampleCount: 100,
+		Respond:     server.respond,
+	}
+
+	log := audit.NewLog()
+	scheduler := audit.NewScheduler(log, audit.AuditorFunc(func(ctx context.Context, fileID string) (*audit.Result, error) {
+		if fileID == "offline" {
+			return nil, errors.New("server unreachable")
+		}
+
+		return auditor.Audit(ctx, fileID)
+	}))
+	scheduler.Clock = clock
+
+	ez.AssertNoError(scheduler.Schedule("hello", 50*time.Minute, 10*time.Minute))
+	ez.AssertNoError(scheduler.Schedule("offline", 2*time.Hour, 0))
+
+	for hour := 0; hour < 6; hour++ {
+		if hour == 3 {
+			server.ciphertext[0] ^= 1
+		}
+
+		now = start.Add(time.Duration(hour)*time.Hour + 30*time.Minute)
+		_, err := scheduler.RunDue(context.Background())
+		ez.AssertNoError(err)
+	}
+
+	stats := log.Stats("hello")
+	ez.AssertAreEqual(stats.Total, 6)
+	ez.AssertAreEqual(stats.Passed, 3)
+	ez.AssertAreEqual(stats.Failed, 3)
+	ez.AssertAreEqual(stats.Streak, 3)
+	ez.AssertAreEqual(stats.LastFailure.Time, start.Add(5*time.Hour+30*time.Minute))
+
+	ez.AssertAreEqual(len(log.Failures("")), 3)
+	ez.AssertAreEqual(log.Stats("offline").Errors, 3)
+	files := log.Files()
+	sort.Strings(files)
+	ez.AssertAreEqual(files, []string{"hello", "offline"})
+	ez.AssertAreEqual(len(log.Entries(audit.Filter{Since: start.Add(3 * time.Hour)})), 4)
+
+	for _, e := range log.Entries(audit.Filter{FileID: "hello"}) {
+		ez.AssertAreEqual(len(e.Transcript), 32)
+	}
+
+	ez.AssertNoError(log.Verify())
+}
+
+func Test__Scheduler__Run__ShouldStop__WhenContextIsDone(t *testing.T) {
+	ez := ez.New(t)
+	log := audit.NewLog()
+	scheduler := audit.NewScheduler(log, audit.AuditorFunc(func(ctx context.Context, fileID string) (*audit.Result, error) {
+		return &audit.Result{Passed: true}, nil
+	}))
+
+	ez.AssertNoError(scheduler.Schedule("a", 10*time.Millisecond, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := scheduler.Run(ctx)
+	ez.Assert(errors.Is(err, context.DeadlineExceeded))
+	ez.Assert(log.Len() >= 2)
+}
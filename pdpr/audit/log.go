@@ -0,0 +1,282 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// Log is an append-only, hash-chained record of audits. The hash of every entry
+// covers the hash of the previous one, so changing, removing or reordering
+// entries breaks the chain from that point on; publishing the Head from time
+// to time makes truncation evident too.
+//
+// A Log kept in a file has one JSON entry per line. When the file is opened,
+// the chain is verified, and a torn last line, left by a crash, is discarded.
+// A line that fails to be written is truncated away as well; if even that
+// fails, the log refuses further appends until it is opened again
+type Log struct {
+	file *os.File
+	// offset is the end of the last entry written to the file
+	offset  int64
+	failed  error
+	entries []*Entry
+	mutex   sync.RWMutex
+}
+
+type Verdict string
+
+const (
+	VerdictPass  Verdict = "pass"
+	VerdictFail  Verdict = "fail"
+	VerdictError Verdict = "error"
+)
+
+type Entry struct {
+	Seq        uint64    `json:"seq"`
+	Time       time.Time `json:"time"`
+	FileID     string    `json:"file_id"`
+	Verdict    Verdict   `json:"verdict"`
+	Challenge  []byte    `json:"challenge,omitempty"`
+	Transcript []byte    `json:"transcript,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	Prev       []byte    `json:"prev"`
+	Hash       []byte    `json:"hash"`
+}
+
+var ErrTampered = errors.New("audit log was tampered with")
+var ErrLogFailed = errors.New("audit log could not be written, and must be opened again")
+
+var entryDomain = []byte("pdpr-go/audit/entry/v1")
+
+// genesis is the Prev of the first entry
+var genesis = make([]byte, sha256.Size)
+
+// NewLog creates a log kept in memory only
+func NewLog() *Log {
+	return new(Log)
+}
+
+func OpenLog(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Log)
+	r.file = f
+
+	if err := r.load(); err != nil {
+		f.Close()
+		return nil, errorutils.NewfWithInner(err, "could not load %s", path)
+	}
+
+	return r, nil
+}
+
+func (l *Log) load() error {
+	reader := bufio.NewReader(l.file)
+	offset := int64(0)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// a line without its newline is a torn write
+			break
+		}
+
+		e := new(Entry)
+		if err := json.Unmarshal(line, e); err != nil {
+			return errorutils.NewfWithInner(ErrTampered, "entry %d is malformed", len(l.entries))
+		}
+
+		if err := l.check(e); err != nil {
+			return err
+		}
+
+		l.entries = append(l.entries, e)
+		offset += int64(len(line))
+	}
+
+	l.offset = offset
+	return l.truncate()
+}
+
+// truncate discards everything after the last entry written to the file
+func (l *Log) truncate() error {
+	if err := l.file.Truncate(l.offset); err != nil {
+		return err
+	}
+
+	_, err := l.file.Seek(l.offset, io.SeekStart)
+	return err
+}
+
+// check checks that e is the next entry of the chain
+func (l *Log) check(e *Entry) error {
+	if e.Seq != uint64(len(l.entries)) || !bytes.Equal(e.Prev, l.head()) || !bytes.Equal(e.Hash, e.hash()) {
+		return errorutils.NewfWithInner(ErrTampered, "the chain breaks at entry %d", len(l.entries))
+	}
+
+	return nil
+}
+
+func (l *Log) head() []byte {
+	if len(l.entries) == 0 {
+		return genesis
+	}
+
+	return l.entries[len(l.entries)-1].Hash
+}
+
+// Head is the hash of the last entry, which commits to the whole log
+func (l *Log) Head() []byte {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return append([]byte{}, l.head()...)
+}
+
+func (l *Log) Len() int {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return len(l.entries)
+}
+
+// Append chains the entry to the log, setting its Seq, Prev and Hash, and
+// returns a copy of the chained entry
+func (l *Log) Append(e Entry) (*Entry, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.failed != nil {
+		return nil, l.failed
+	}
+
+	e.Seq = uint64(len(l.entries))
+	e.Time = e.Time.UTC()
+	e.Prev = bytes.Clone(l.head())
+	e.Hash = e.hash()
+
+	if l.file != nil {
+		bs, err := json.Marshal(&e)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := l.write(append(bs, '\n')); err != nil {
+			return nil, err
+		}
+	}
+
+	l.entries = append(l.entries, e.clone())
+	return &e, nil
+}
+
+// write writes and syncs a line. On failure, the line is truncated away, as
+// the next entry would otherwise follow a torn one
+func (l *Log) write(line []byte) error {
+	_, err := l.file.Write(line)
+	if err == nil {
+		err = l.file.Sync()
+	}
+
+	if err == nil {
+		l.offset += int64(len(line))
+		return nil
+	}
+
+	if truncateErr := l.truncate(); truncateErr != nil {
+		l.failed = errors.Join(ErrLogFailed, err, truncateErr)
+		return l.failed
+	}
+
+	return err
+}
+
+// Verify rechecks the whole chain, e.g. against a head published earlier
+func (l *Log) Verify() error {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	check := new(Log)
+	for _, e := range l.entries {
+		if err := check.check(e); err != nil {
+			return err
+		}
+
+		check.entries = append(check.entries, e)
+	}
+
+	return nil
+}
+
+// Contains reports whether head is the head of the log at some point, i.e.
+// whether the log extends the log that had that head
+func (l *Log) Contains(head []byte) bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	if bytes.Equal(head, genesis) {
+		return true
+	}
+
+	for _, e := range l.entries {
+		if bytes.Equal(e.Hash, head) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *Log) Close() error {
+	if l.file == nil {
+		return nil
+	}
+
+	return l.file.Close()
+}
+
+// clone copies the entry along with its byte fields, so that entries handed
+// out by the log cannot change the chain
+func (e *Entry) clone() *Entry {
+	r := *e
+	r.Challenge = bytes.Clone(e.Challenge)
+	r.Transcript = bytes.Clone(e.Transcript)
+	r.Prev = bytes.Clone(e.Prev)
+	r.Hash = bytes.Clone(e.Hash)
+
+	return &r
+}
+
+func (e *Entry) hash() []byte {
+	h := sha256.New()
+	h.Write(entryDomain)
+
+	for _, field := range [][]byte{
+		binary.BigEndian.AppendUint64(nil, e.Seq),
+		binary.BigEndian.AppendUint64(nil, uint64(e.Time.UnixNano())),
+		[]byte(e.FileID),
+		[]byte(e.Verdict),
+		e.Challenge,
+		e.Transcript,
+		[]byte(e.Detail),
+		e.Prev,
+	} {
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(field))))
+		h.Write(field)
+	}
+
+	return h.Sum(nil)
+}
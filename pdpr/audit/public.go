@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/public"
+)
+
+// PublicAuditor audits publicly verifiable files with fresh challenges.
+// Respond sends the challenge to the server, which answers with a JSON public.Proof
+type PublicAuditor struct {
+	Verifier    *freshness.Verifier
+	Infos       map[string]*public.PublicInfo
+	SampleCount int
+	Respond     func(ctx context.Context, challenge *freshness.Challenge) (*freshness.Response, error)
+}
+
+var _ Auditor = (*PublicAuditor)(nil)
+
+func (a *PublicAuditor) Audit(ctx context.Context, fileID string) (*Result, error) {
+	info, ok := a.Infos[fileID]
+	if !ok {
+		return nil, errorutils.Newf("no public information for file %s", fileID)
+	}
+
+	samples := int(min(uint64(a.SampleCount), info.BlockCount))
+	challenge, err := a.Verifier.Issue(fileID, info.BlockCount, samples, public.CoefficientBits)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := a.Respond(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Result{Challenge: challenge.Hash(), Transcript: response.Transcript()}
+
+	_, expanded, err := a.Verifier.Accept(response)
+	if err != nil {
+		r.Detail = err.Error()
+		return r, nil
+	}
+
+	proof := new(public.Proof)
+	if err := json.Unmarshal(response.Proof, proof); err != nil {
+		r.Detail = "malformed proof"
+		return r, nil
+	}
+
+	r.Passed = public.Verify(info, expanded, proof)
+	return r, nil
+}
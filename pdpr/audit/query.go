@@ -0,0 +1,98 @@
+package audit
+
+import "time"
+
+// Filter selects entries. Zero fields match everything
+type Filter struct {
+	FileID  string
+	Verdict Verdict
+	Since   time.Time
+	Until   time.Time
+}
+
+func (f *Filter) matches(e *Entry) bool {
+	return (f.FileID == "" || e.FileID == f.FileID) &&
+		(f.Verdict == "" || e.Verdict == f.Verdict) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until))
+}
+
+// Stats summarizes the audits of a file
+type Stats struct {
+	Total       int
+	Passed      int
+	Failed      int
+	Errors      int
+	LastAudit   time.Time
+	LastFailure *Entry
+	// Streak is the number of consecutive audits with the same verdict as the last one
+	Streak int
+}
+
+// Entries returns copies of the entries that match the filter, in order
+func (l *Log) Entries(filter Filter) []*Entry {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	r := make([]*Entry, 0)
+	for _, e := range l.entries {
+		if filter.matches(e) {
+			r = append(r, e.clone())
+		}
+	}
+
+	return r
+}
+
+// Failures returns the failed audits of a file, or of every file if fileID is empty.
+// Errors, such as an unreachable server, are not failures
+func (l *Log) Failures(fileID string) []*Entry {
+	return l.Entries(Filter{FileID: fileID, Verdict: VerdictFail})
+}
+
+func (l *Log) Stats(fileID string) Stats {
+	r := Stats{}
+	var last Verdict
+
+	for _, e := range l.Entries(Filter{FileID: fileID}) {
+		r.Total++
+		switch e.Verdict {
+		case VerdictPass:
+			r.Passed++
+		case VerdictFail:
+			r.Failed++
+			r.LastFailure = e
+		case VerdictError:
+			r.Errors++
+		}
+
+		if e.Verdict == last {
+			r.Streak++
+		} else {
+			r.Streak = 1
+		}
+
+		last = e.Verdict
+		r.LastAudit = e.Time
+	}
+
+	return r
+}
+
+// Files returns the ids of every audited file, in order of first audit
+func (l *Log) Files() []string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	r := make([]string, 0)
+
+	for _, e := range l.entries {
+		if !seen[e.FileID] {
+			seen[e.FileID] = true
+			r = append(r, e.FileID)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,226 @@
+// Package audit runs periodic audits of stored files, and keeps their results
+// in a tamper-evident log that can be queried for the history of failures.
+package audit
+
+import (
+	"context"
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// Auditor runs one audit of a file. Errors mean the audit could not be run,
+// e.g. because the server is unreachable, and are logged as VerdictError
+type Auditor interface {
+	Audit(ctx context.Context, fileID string) (*Result, error)
+}
+
+type AuditorFunc func(ctx context.Context, fileID string) (*Result, error)
+
+func (f AuditorFunc) Audit(ctx context.Context, fileID string) (*Result, error) {
+	return f(ctx, fileID)
+}
+
+type Result struct {
+	Passed     bool
+	Challenge  []byte
+	Transcript []byte
+	Detail     string
+}
+
+// Scheduler audits each file every interval, plus a random delay of up to
+// jitter, so that audits are unpredictable to the server and spread over time.
+// Clock defaults to time.Now, and can be replaced in tests
+type Scheduler struct {
+	Clock func() time.Time
+
+	log     *Log
+	auditor Auditor
+	targets map[string]*target
+	wake    chan struct{}
+	mutex   sync.Mutex
+}
+
+type target struct {
+	interval time.Duration
+	jitter   time.Duration
+	next     time.Time
+}
+
+func NewScheduler(log *Log, auditor Auditor) *Scheduler {
+	r := new(Scheduler)
+	r.Clock = time.Now
+	r.log = log
+	r.auditor = auditor
+	r.targets = make(map[string]*target)
+	r.wake = make(chan struct{}, 1)
+
+	return r
+}
+
+// Schedule audits the file periodically. The first audit runs within jitter
+func (s *Scheduler) Schedule(fileID string, interval time.Duration, jitter time.Duration) error {
+	if interval <= 0 || jitter < 0 {
+		return errorutils.New("the interval must be positive, and the jitter not negative")
+	}
+
+	delay, err := randomDuration(jitter)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.targets[fileID] = &target{interval: interval, jitter: jitter, next: s.Clock().Add(delay)}
+	s.mutex.Unlock()
+
+	s.notify()
+	return nil
+}
+
+func (s *Scheduler) Unschedule(fileID string) {
+	s.mutex.Lock()
+	delete(s.targets, fileID)
+	s.mutex.Unlock()
+
+	s.notify()
+}
+
+// Next returns the file audited next, and when
+func (s *Scheduler) Next() (string, time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	due := s.due(time.Time{})
+	if len(due) == 0 {
+		return "", time.Time{}, false
+	}
+
+	return due[0], s.targets[due[0]].next, true
+}
+
+// RunDue runs every audit that is due, in order, and logs them
+func (s *Scheduler) RunDue(ctx context.Context) ([]*Entry, error) {
+	s.mutex.Lock()
+	now := s.Clock()
+	due := s.due(now)
+	s.mutex.Unlock()
+
+	r := make([]*Entry, 0, len(due))
+	for _, fileID := range due {
+		if err := ctx.Err(); err != nil {
+			return r, err
+		}
+
+		e, err := s.run(ctx, fileID)
+		if err != nil {
+			return r, err
+		}
+
+		r = append(r, e)
+	}
+
+	return r, nil
+}
+
+// Run runs audits as they become due, until the context is done
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		if _, err := s.RunDue(ctx); err != nil {
+			return err
+		}
+
+		wait := time.Hour
+		if _, next, ok := s.Next(); ok {
+			wait = max(0, next.Sub(s.Clock()))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, fileID string) (*Entry, error) {
+	entry := Entry{FileID: fileID}
+
+	result, err := s.auditor.Audit(ctx, fileID)
+	switch {
+	case err != nil:
+		entry.Verdict = VerdictError
+		entry.Detail = err.Error()
+	case result.Passed:
+		entry.Verdict = VerdictPass
+	default:
+		entry.Verdict = VerdictFail
+	}
+
+	if result != nil {
+		entry.Challenge = result.Challenge
+		entry.Transcript = result.Transcript
+		if result.Detail != "" {
+			entry.Detail = result.Detail
+		}
+	}
+
+	s.mutex.Lock()
+	entry.Time = s.Clock()
+	if t, ok := s.targets[fileID]; ok {
+		delay, err := randomDuration(t.jitter)
+		if err != nil {
+			s.mutex.Unlock()
+			return nil, err
+		}
+
+		t.next = entry.Time.Add(t.interval + delay)
+	}
+	s.mutex.Unlock()
+
+	return s.log.Append(entry)
+}
+
+// due returns the files due at now, or every file if now is zero, soonest first
+func (s *Scheduler) due(now time.Time) []string {
+	r := make([]string, 0)
+	for id, t := range s.targets {
+		if now.IsZero() || !t.next.After(now) {
+			r = append(r, id)
+		}
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		ti, tj := s.targets[r[i]].next, s.targets[r[j]].next
+		return ti.Before(tj) || (ti.Equal(tj) && r[i] < r[j])
+	})
+
+	return r
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func randomDuration(limit time.Duration) (time.Duration, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	bs, err := random.GenerateBytes(8)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(binary.BigEndian.Uint64(bs) % uint64(limit)), nil
+}
@@ -0,0 +1,177 @@
+package pdpr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"time"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+)
+
+// Challenge is sent by the owner to the server, which must prove possession of
+// the blocks at Indices with index hashes keyed by Nonce.
+// Over the wire, it is sent as a freshness.Challenge, which adds an expiry and
+// a transcript hash; see IssueChallenge, Respond and VerifyResponse
+type Challenge struct {
+	Nonce   []byte   `json:"nonce"`
+	Indices []uint64 `json:"indices"`
+}
+
+// Token is a challenge computed in advance by the owner, who knew the data at
+// the time. Expected is the SHA-256 of the digest the proof of the challenge
+// must yield once the key is removed, so the server cannot learn it from the tag
+type Token struct {
+	Nonce    []byte `json:"nonce"`
+	Expected []byte `json:"expected"`
+}
+
+// DefaultTokenCount tokens are computed by NewTag, each challenging
+// DefaultSampleCount blocks, or every block of smaller files. That many blocks
+// detect the corruption of 1% of the blocks with probability 0.99
+const (
+	DefaultTokenCount  = 16
+	DefaultSampleCount = 460
+)
+
+var ErrMalformedChallenge = errors.New("malformed challenge")
+var ErrNoTokens = errors.New("every token of the tag was issued, the file must be tagged again")
+
+var tokenDomain = []byte("pdpr-go/pdpr/token/v1")
+
+// NextChallenge issues the next token of the tag as a challenge, and marks it as used
+func (t *Tag) NextChallenge(params Params) (*Challenge, error) {
+	token, blockCount, err := t.nextToken(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Challenge{Nonce: token.Nonce, Indices: SampleIndices(token.Nonce, blockCount, t.SampleCount)}, nil
+}
+
+// IssueChallenge issues the next token of the tag through verifier, as a fresh
+// challenge of the file, and marks it as used
+func (t *Tag) IssueChallenge(params Params, verifier *freshness.Verifier, fileID string) (*freshness.Challenge, error) {
+	token, blockCount, err := t.nextToken(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifier.IssueWithNonce(fileID, token.Nonce, blockCount, sampleCount(blockCount, t.SampleCount), 0)
+}
+
+// NewFreshChallenge is IssueChallenge without a freshness.Verifier, for owners
+// that keep the challenge themselves until the response arrives
+func (t *Tag) NewFreshChallenge(params Params, fileID string, now time.Time, ttl time.Duration) (*freshness.Challenge, error) {
+	token, blockCount, err := t.nextToken(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return freshness.NewChallengeWithNonce(fileID, token.Nonce, blockCount, sampleCount(blockCount, t.SampleCount), 0, now, ttl)
+}
+
+func (t *Tag) nextToken(params Params) (*Token, uint64, error) {
+	if t.Used >= len(t.Tokens) {
+		return nil, 0, ErrNoTokens
+	}
+
+	crypt, err := params.crypt()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	blockCount := uint64(crypt.BlockCount(t.Length))
+	if blockCount == 0 {
+		return nil, 0, errorutils.New("an empty file cannot be challenged")
+	}
+
+	token := &t.Tokens[t.Used]
+	t.Used++
+
+	return token, blockCount, nil
+}
+
+// ChallengeOf returns the challenge a fresh challenge stands for
+func ChallengeOf(challenge *freshness.Challenge) (*Challenge, error) {
+	if challenge.CoefficientBits != 0 {
+		return nil, errorutils.NewWithInner(ErrMalformedChallenge, "PDPr challenges have no coefficients")
+	}
+
+	expanded, err := challenge.Expand()
+	if err != nil {
+		return nil, errors.Join(ErrMalformedChallenge, err)
+	}
+
+	return &Challenge{Nonce: challenge.Nonce, Indices: expanded.Indices}, nil
+}
+
+// Remaining is the number of tokens that can still be issued
+func (t *Tag) Remaining() int {
+	return max(0, len(t.Tokens)-t.Used)
+}
+
+func (t *Tag) token(nonce []byte) *Token {
+	for i := range t.Tokens {
+		if bytes.Equal(t.Tokens[i].Nonce, nonce) {
+			return &t.Tokens[i]
+		}
+	}
+
+	return nil
+}
+
+// tokenNonce derives the nonce of the j-th token from the secret data nonce,
+// so the server cannot predict the nonces of the tokens not yet issued
+func tokenNonce(dataNonce []byte, j int) []byte {
+	h := sha256.New()
+	h.Write(tokenDomain)
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(dataNonce))))
+	h.Write(dataNonce)
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(j)))
+
+	return h.Sum(nil)
+}
+
+// SampleIndices derives sampleCount distinct indices below blockCount from the
+// nonce, or every index if there are no more blocks than that, as the fresh
+// challenges of IssueChallenge expand to
+func SampleIndices(nonce []byte, blockCount uint64, samples int) []uint64 {
+	c := &freshness.Challenge{Nonce: nonce, BlockCount: blockCount, SampleCount: sampleCount(blockCount, samples)}
+
+	expanded, err := c.Expand()
+	if err != nil {
+		// there are no blocks, or no samples
+		return nil
+	}
+
+	return expanded.Indices
+}
+
+func sampleCount(blockCount uint64, samples int) int {
+	return int(min(uint64(max(samples, 0)), blockCount))
+}
+
+// expectedDigest hashes the digest a proof yields, to be stored in a token
+func expectedDigest(digest []byte) []byte {
+	r := sha256.Sum256(digest)
+	return r[:]
+}
+
+// check rejects challenges the server cannot answer
+func (c *Challenge) check(blockCount int) error {
+	if len(c.Nonce) == 0 {
+		return errorutils.NewWithInner(ErrMalformedChallenge, "no nonce")
+	}
+
+	for _, i := range c.Indices {
+		if i >= uint64(blockCount) {
+			return errorutils.NewfWithInner(ErrMalformedChallenge, "block %d of %d", i, blockCount)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/server"
+)
+
+// Client talks to a PDPr server, as documented in package server
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+var ErrNotFound = errors.New("file not found on the server")
+
+// New creates a client for the server at baseURL, e.g. "http://127.0.0.1:7878".
+// A nil httpClient means http.DefaultClient
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	r := new(Client)
+	r.baseURL = baseURL
+	r.http = httpClient
+
+	return r
+}
+
+func (c *Client) Upload(ctx context.Context, id string, record *server.Record) error {
+	return c.do(ctx, http.MethodPut, c.fileURL(id), record, nil)
+}
+
+func (c *Client) Download(ctx context.Context, id string) (*server.Record, error) {
+	r := new(server.Record)
+	if err := c.do(ctx, http.MethodGet, c.fileURL(id), nil, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// DownloadRange returns length bytes of the ciphertext of a file, starting at offset
+func (c *Client) DownloadRange(ctx context.Context, id string, offset int64, length int64) ([]byte, error) {
+	query := url.Values{}
+	query.Set("offset", strconv.FormatInt(offset, 10))
+	query.Set("length", strconv.FormatInt(length, 10))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.fileURL(id)+"/ciphertext?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, responseError(resp)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Client) Delete(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, c.fileURL(id), nil, nil)
+}
+
+func (c *Client) List(ctx context.Context) ([]string, error) {
+	r := new(server.FileList)
+	if err := c.do(ctx, http.MethodGet, c.baseURL+"/v1/files", nil, r); err != nil {
+		return nil, err
+	}
+
+	return r.Files, nil
+}
+
+// Prove asks the server for a proof of possession of a file, answering the
+// challenge, which is issued by pdpr.Tag.IssueChallenge
+func (c *Client) Prove(ctx context.Context, id string, challenge *freshness.Challenge) (*freshness.Response, error) {
+	r := new(freshness.Response)
+	if err := c.do(ctx, http.MethodPost, c.fileURL(id)+"/proof", challenge, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (c *Client) fileURL(id string) string {
+	return c.baseURL + "/v1/files/" + url.PathEscape(id)
+}
+
+// do sends body as JSON, and decodes the response into result if it is not nil
+func (c *Client) do(ctx context.Context, method string, url string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(bs)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return errorutils.NewWithInner(err, "malformed response from the server")
+	}
+
+	return nil
+}
+
+func responseError(resp *http.Response) error {
+	msg := new(server.ErrorResponse)
+	if err := json.NewDecoder(resp.Body).Decode(msg); err != nil || msg.Error == "" {
+		msg.Error = resp.Status
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errorutils.NewWithInner(ErrNotFound, msg.Error)
+	}
+
+	return errorutils.Newf("server answered %d: %s", resp.StatusCode, msg.Error)
+}
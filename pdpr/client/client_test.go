@@ -0,0 +1,142 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/client"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/server"
+	"github.com/titosilva/pdpr-go/pdpr/store"
+)
+
+var params = pdpr.Params{ModulusBits: 64, ChunkCount: 16}
+
+// upload uploads an encrypted file as hello.txt, and returns its tag
+func upload(ez *ez.EzTest, c *client.Client) *pdpr.Tag {
+	key := []byte("This is a key")
+	data := []byte("Hello, World!")
+	ct, err := pdpr.Encrypt(params, key, data)
+	ez.AssertNoError(err)
+
+	tag, state, err := pdpr.NewTag(params, key, ct, len(data))
+	ez.AssertNoError(err)
+
+	record := &server.Record{Params: params, Ciphertext: ct, State: *state}
+	ez.AssertNoError(c.Upload(context.Background(), "hello.txt", record))
+
+	return tag
+}
+
+func Test__Client__EndToEnd__ShouldVerifyProof(t *testing.T) {
+	ez := ez.New(t)
+	ctx := context.Background()
+
+	dir, err := store.NewDir(t.TempDir())
+	ez.AssertNoError(err)
+	log, err := store.OpenLog(filepath.Join(t.TempDir(), "files.log"))
+	ez.AssertNoError(err)
+	defer log.Close()
+
+	for _, s := range []store.Store{store.NewMemory(), dir, log} {
+		srv := httptest.NewServer(server.New(s))
+		defer srv.Close()
+		c := client.New(srv.URL, srv.Client())
+		tag := upload(ez, c)
+
+		verifier := freshness.NewVerifier(time.Minute)
+		challenge, err := tag.IssueChallenge(params, verifier, "hello.txt")
+		ez.AssertNoError(err)
+		response, err := c.Prove(ctx, "hello.txt", challenge)
+		ez.AssertNoError(err)
+		accepted, _, err := verifier.Accept(response)
+		ez.AssertNoError(err)
+		ez.Assert(pdpr.VerifyResponse(params, []byte("This is a key"), tag, accepted, response))
+
+		files, err := c.List(ctx)
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(files, []string{"hello.txt"})
+
+		record, err := c.Download(ctx, "hello.txt")
+		ez.AssertNoError(err)
+		data, err := pdpr.Decrypt(record.Params, []byte("This is a key"), record.Ciphertext, tag.Length)
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(string(data), "Hello, World!")
+
+		part, err := c.DownloadRange(ctx, "hello.txt", 8, 16)
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(part, record.Ciphertext[8:24])
+
+		_, err = c.DownloadRange(ctx, "hello.txt", int64(len(record.Ciphertext))+1, 1)
+		ez.Assert(err != nil)
+
+		ez.AssertNoError(c.Delete(ctx, "hello.txt"))
+		_, err = c.Prove(ctx, "hello.txt", challenge)
+		ez.Assert(errors.Is(err, client.ErrNotFound))
+	}
+}
+
+func Test__Client__EndToEnd__ShouldNotVerify__WhenServerLostData(t *testing.T) {
+	ez := ez.New(t)
+	ctx := context.Background()
+	s := store.NewMemory()
+	srv := httptest.NewServer(server.New(s))
+	defer srv.Close()
+	c := client.New(srv.URL, srv.Client())
+	tag := upload(ez, c)
+
+	key := "files/hello.txt/ciphertext"
+	ct, _ := s.Get(ctx, key)
+	ct[0] ^= 1
+	s.Put(ctx, key, ct)
+
+	challenge, _ := tag.NewFreshChallenge(params, "hello.txt", time.Now(), time.Minute)
+	response, err := c.Prove(ctx, "hello.txt", challenge)
+	ez.AssertNoError(err)
+	ez.AssertFalse(pdpr.VerifyResponse(params, []byte("This is a key"), tag, challenge, response))
+}
+
+func Test__Client__Upload__ShouldFail__WhenRecordIsInvalid(t *testing.T) {
+	ez := ez.New(t)
+	ctx := context.Background()
+	srv := httptest.NewServer(server.New(store.NewMemory()))
+	defer srv.Close()
+	c := client.New(srv.URL, srv.Client())
+	upload(ez, c)
+
+	err := c.Upload(ctx, "bad", &server.Record{Params: params, State: pdpr.State{NonceState: []byte{1}}})
+	ez.Assert(err != nil)
+
+	err = c.Upload(ctx, "..", &server.Record{Params: params})
+	ez.Assert(err != nil)
+
+	state := pdpr.State{NonceState: make([]byte, params.StateSize())}
+	err = c.Upload(ctx, "bad", &server.Record{Params: pdpr.Params{ModulusBits: 64, ChunkCount: 16, BitsPerBlock: 3}, State: state})
+	ez.Assert(err != nil)
+
+	err = c.Upload(ctx, "bad", &server.Record{Params: params, Ciphertext: make([]byte, 12), State: state})
+	ez.Assert(err != nil)
+
+	err = c.Upload(ctx, "good", &server.Record{Params: params, Ciphertext: make([]byte, 16), State: state})
+	ez.AssertNoError(err)
+
+	_, err = c.Download(ctx, "missing")
+	ez.Assert(errors.Is(err, client.ErrNotFound))
+
+	_, err = c.Prove(ctx, "hello.txt", &freshness.Challenge{FileID: "hello.txt", Nonce: []byte("nonce"), ExpiresAt: time.Now().Add(time.Minute), BlockCount: 1 << 40, SampleCount: 1})
+	ez.Assert(err != nil)
+
+	expired, _ := freshness.NewChallengeWithNonce("hello.txt", []byte("nonce"), 104, 8, 0, time.Now().Add(-time.Hour), time.Minute)
+	_, err = c.Prove(ctx, "hello.txt", expired)
+	ez.Assert(err != nil)
+
+	other, _ := freshness.NewChallengeWithNonce("other.txt", []byte("nonce"), 104, 8, 0, time.Now(), time.Minute)
+	_, err = c.Prove(ctx, "hello.txt", other)
+	ez.Assert(err != nil)
+}
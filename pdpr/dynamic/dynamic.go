@@ -0,0 +1,387 @@
+package dynamic
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Dynamic PDPr splits a file in units of a fixed size. Each unit gets an id
+// that is never reused: modifying a unit replaces it by a new one, so that
+// neither a GCrypt key stream nor a tag index is ever used for two contents.
+// Since keys and tags depend on the id and not on the position of the unit,
+// inserting or deleting a unit does not require re-encrypting or re-tagging the rest of the file.
+// Every update increments the version of the file, and proofs must be computed
+// for the current version, so that the server cannot roll the file back.
+
+// Unit is a piece of the file, as stored by the server
+type Unit struct {
+	ID         uint64
+	Ciphertext []byte
+	Tags       []por.Tag
+}
+
+type Op int
+
+const (
+	OpModify Op = iota
+	OpInsert
+	OpAppend
+	OpDelete
+)
+
+// Update is sent by the owner to the server to change the file
+type Update struct {
+	Op       Op
+	Position uint64
+	Unit     *Unit
+	Version  uint64
+}
+
+// Proof is a proof of retrievability for a given version of the file
+type Proof struct {
+	Version uint64
+	por.Proof
+}
+
+var ErrVersion = errors.New("version does not follow the current version of the file")
+var ErrPosition = errors.New("unit position is out of range")
+var ErrUnitSize = errors.New("unit data is larger than the unit size")
+var ErrCorruptedUnit = errors.New("unit does not match its tags")
+
+// unitEntry is what the owner keeps about each unit
+type unitEntry struct {
+	id     uint64
+	length int
+}
+
+// Owner holds the key and the metadata needed to update and audit a file:
+// the id and length of each unit, and the current version
+type Owner struct {
+	tagger   *por.Tagger
+	crypt    *gcrypt.GCrypt
+	params   por.Params
+	key      []byte
+	unitSize int
+	units    []unitEntry
+	nextID   uint64
+	version  uint64
+}
+
+func NewOwner(params por.Params, key []byte, unitSize int) (*Owner, error) {
+	tagger, err := por.NewTagger(params, key)
+	if err != nil {
+		return nil, err
+	}
+
+	crypt, err := gcrypt.New(uint64(params.ModulusBitsize))
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Owner)
+	r.tagger = tagger
+	r.crypt = crypt
+	r.params = params
+	r.key = key
+	r.unitSize = unitSize
+	r.units = make([]unitEntry, 0)
+
+	return r, nil
+}
+
+func (o *Owner) Version() uint64 {
+	return o.version
+}
+
+func (o *Owner) UnitCount() uint64 {
+	return uint64(len(o.units))
+}
+
+// Upload splits data into units and returns the initial file for the server
+func (o *Owner) Upload(data []byte) (*File, error) {
+	if len(o.units) != 0 {
+		return nil, errorutils.New("file was already uploaded")
+	}
+
+	r := NewFile()
+	for offset := 0; offset < len(data); offset += o.unitSize {
+		u, err := o.newUnit(data[offset:min(offset+o.unitSize, len(data))])
+		if err != nil {
+			return nil, err
+		}
+
+		r.Units = append(r.Units, u)
+		o.units = append(o.units, unitEntry{id: u.ID, length: min(o.unitSize, len(data)-offset)})
+	}
+
+	return r, nil
+}
+
+func (o *Owner) Modify(position uint64, data []byte) (*Update, error) {
+	if position >= o.UnitCount() {
+		return nil, ErrPosition
+	}
+
+	return o.update(OpModify, position, data)
+}
+
+func (o *Owner) Insert(position uint64, data []byte) (*Update, error) {
+	if position > o.UnitCount() {
+		return nil, ErrPosition
+	}
+
+	return o.update(OpInsert, position, data)
+}
+
+func (o *Owner) Append(data []byte) (*Update, error) {
+	return o.update(OpAppend, o.UnitCount(), data)
+}
+
+func (o *Owner) Delete(position uint64) (*Update, error) {
+	if position >= o.UnitCount() {
+		return nil, ErrPosition
+	}
+
+	o.units = append(o.units[:position], o.units[position+1:]...)
+	o.version++
+
+	return &Update{Op: OpDelete, Position: position, Version: o.version}, nil
+}
+
+func (o *Owner) update(op Op, position uint64, data []byte) (*Update, error) {
+	u, err := o.newUnit(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := unitEntry{id: u.ID, length: len(data)}
+
+	switch op {
+	case OpModify:
+		o.units[position] = entry
+	case OpInsert:
+		o.units = append(o.units[:position], append([]unitEntry{entry}, o.units[position:]...)...)
+	case OpAppend:
+		o.units = append(o.units, entry)
+	}
+
+	o.version++
+
+	return &Update{Op: op, Position: position, Unit: u, Version: o.version}, nil
+}
+
+// newUnit encrypts and tags data with a fresh id
+func (o *Owner) newUnit(data []byte) (*Unit, error) {
+	if len(data) > o.unitSize {
+		return nil, ErrUnitSize
+	}
+
+	padded := make([]byte, o.unitSize)
+	copy(padded, data)
+
+	ct, err := o.crypt.Encrypt(padded, unitKey(o.key, o.nextID))
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Unit)
+	r.ID = o.nextID
+	r.Ciphertext = ct
+
+	blocks := o.tagger.Blocks(r.Ciphertext)
+	r.Tags = make([]por.Tag, len(blocks))
+	for j := range blocks {
+		if r.Tags[j], err = o.tagger.TagBlock(blocks[j], o.tagIndex(r.ID, uint64(j))); err != nil {
+			return nil, err
+		}
+	}
+
+	o.nextID++
+
+	return r, nil
+}
+
+// Challenge samples blocks of the current version of the file
+func (o *Owner) Challenge(sampleCount int) (*Challenge, error) {
+	ch, err := por.NewChallenge(uint64(o.params.ModulusBitsize), o.UnitCount()*o.blocksPerUnit(), sampleCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Challenge{Version: o.version, BlocksPerUnit: o.blocksPerUnit(), Challenge: *ch}, nil
+}
+
+func (o *Owner) Verify(challenge *Challenge, proof *Proof) bool {
+	if proof == nil || proof.Version != o.version || challenge.Version != o.version {
+		return false
+	}
+
+	translated := &por.Challenge{Coefficients: challenge.Coefficients}
+	for _, idx := range challenge.Indices {
+		position, block := idx/o.blocksPerUnit(), idx%o.blocksPerUnit()
+		if position >= o.UnitCount() {
+			return false
+		}
+
+		translated.Indices = append(translated.Indices, o.tagIndex(o.units[position].id, block))
+	}
+
+	return o.tagger.Verify(translated, &proof.Proof)
+}
+
+// Decrypt recovers the file from the units returned by the server, checking
+// that they are the units of the current version, and that every block of
+// each unit matches its tag.
+// This also prevents rollbacks: ids are never reused and feed the tag indices,
+// so the blocks of an earlier unit, even at the same position, do not match
+// the tags of the current one, and the server cannot tag blocks itself
+func (o *Owner) Decrypt(units []*Unit) ([]byte, error) {
+	if len(units) != len(o.units) {
+		return nil, ErrVersion
+	}
+
+	r := make([]byte, 0)
+	for i := range units {
+		if units[i].ID != o.units[i].id {
+			return nil, errorutils.NewfWithInner(ErrVersion, "unit %d has id %d, expected %d", i, units[i].ID, o.units[i].id)
+		}
+
+		if uint64(len(units[i].Tags)) != o.blocksPerUnit() || !o.tagger.CheckShard(units[i].Ciphertext, units[i].Tags, o.tagIndex(units[i].ID, 0)) {
+			return nil, errorutils.NewfWithInner(ErrCorruptedUnit, "unit %d", i)
+		}
+
+		plain := o.crypt.Decrypt(units[i].Ciphertext, unitKey(o.key, units[i].ID))
+		if len(plain) < o.units[i].length {
+			return nil, errorutils.Newf("unit %d is truncated", i)
+		}
+
+		r = append(r, plain[:o.units[i].length]...)
+	}
+
+	return r, nil
+}
+
+func (o *Owner) blocksPerUnit() uint64 {
+	return uint64(o.unitSize) * 8
+}
+
+func (o *Owner) tagIndex(id uint64, block uint64) uint64 {
+	return id*o.blocksPerUnit() + block
+}
+
+func unitKey(key []byte, id uint64) []byte {
+	info := make([]byte, 8)
+	binary.BigEndian.PutUint64(info, id)
+
+	r := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, []byte("pdpr-go/dynamic/unit-key"), info), r); err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// Challenge is a sampled challenge over the blocks of a given version of the file.
+// Block j of the unit at position p has index p * BlocksPerUnit + j
+type Challenge struct {
+	Version       uint64
+	BlocksPerUnit uint64
+	por.Challenge
+}
+
+// File is the server side of a dynamic file
+type File struct {
+	Version uint64
+	Units   []*Unit
+}
+
+func NewFile() *File {
+	r := new(File)
+	r.Units = make([]*Unit, 0)
+
+	return r
+}
+
+// Apply applies an update from the owner, which must be for the next version
+func (f *File) Apply(u *Update) error {
+	if u.Version != f.Version+1 {
+		return errorutils.NewfWithInner(ErrVersion, "got update for version %d, current version is %d", u.Version, f.Version)
+	}
+
+	if u.Op != OpDelete && u.Unit == nil {
+		return errorutils.New("update has no unit")
+	}
+
+	switch u.Op {
+	case OpModify:
+		if u.Position >= uint64(len(f.Units)) {
+			return ErrPosition
+		}
+
+		f.Units[u.Position] = u.Unit
+	case OpInsert, OpAppend:
+		if u.Position > uint64(len(f.Units)) {
+			return ErrPosition
+		}
+
+		f.Units = append(f.Units[:u.Position], append([]*Unit{u.Unit}, f.Units[u.Position:]...)...)
+	case OpDelete:
+		if u.Position >= uint64(len(f.Units)) {
+			return ErrPosition
+		}
+
+		f.Units = append(f.Units[:u.Position], f.Units[u.Position+1:]...)
+	default:
+		return errorutils.Newf("unknown operation %d", u.Op)
+	}
+
+	f.Version = u.Version
+	return nil
+}
+
+func (f *File) Prove(params por.Params, challenge *Challenge) (*Proof, error) {
+	if challenge.Version != f.Version {
+		return nil, errorutils.NewfWithInner(ErrVersion, "challenge is for version %d, current version is %d", challenge.Version, f.Version)
+	}
+
+	crypt, err := gcrypt.New(uint64(params.ModulusBitsize))
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*uintp.UintP, len(challenge.Indices))
+	tags := make([]por.Tag, len(challenge.Indices))
+	local := &por.Challenge{Coefficients: challenge.Coefficients}
+
+	for i, idx := range challenge.Indices {
+		position, block := idx/challenge.BlocksPerUnit, idx%challenge.BlocksPerUnit
+		if position >= uint64(len(f.Units)) {
+			return nil, ErrPosition
+		}
+
+		unit := f.Units[position]
+		unitBlocks := crypt.FromBytes(unit.Ciphertext)
+		if block >= uint64(len(unitBlocks)) || block >= uint64(len(unit.Tags)) {
+			return nil, por.ErrIndexOutOfRange
+		}
+
+		blocks[i] = unitBlocks[block]
+		tags[i] = unit.Tags[block]
+		local.Indices = append(local.Indices, uint64(i))
+	}
+
+	proof, err := por.Prove(params, blocks, tags, local)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Proof{Version: f.Version, Proof: *proof}, nil
+}
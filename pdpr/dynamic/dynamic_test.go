@@ -0,0 +1,136 @@
+package dynamic_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr/dynamic"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+// uploaded returns an owner of 4-block units, and the file it uploaded
+func uploaded(ez *ez.EzTest) (*dynamic.Owner, *dynamic.File) {
+	owner, err := dynamic.NewOwner(por.DefaultParams, []byte("This is a key"), 4)
+	ez.AssertNoError(err)
+
+	file, err := owner.Upload([]byte("Hello, World!"))
+	ez.AssertNoError(err)
+
+	return owner, file
+}
+
+func audit(ez *ez.EzTest, owner *dynamic.Owner, file *dynamic.File) bool {
+	challenge, err := owner.Challenge(int(owner.UnitCount() * 4 * 8))
+	ez.AssertNoError(err)
+
+	proof, err := file.Prove(por.DefaultParams, challenge)
+	ez.AssertNoError(err)
+
+	return owner.Verify(challenge, proof)
+}
+
+func Test__Dynamic__Updates__ShouldKeepProofsValid(t *testing.T) {
+	ez := ez.New(t)
+	owner, file := uploaded(ez)
+	ez.Assert(audit(ez, owner, file))
+
+	updates := []func() (*dynamic.Update, error){
+		func() (*dynamic.Update, error) { return owner.Modify(1, []byte("o, w")) },
+		func() (*dynamic.Update, error) { return owner.Insert(0, []byte(">> ")) },
+		func() (*dynamic.Update, error) { return owner.Append([]byte(" :)")) },
+		func() (*dynamic.Update, error) { return owner.Delete(2) },
+	}
+
+	for _, update := range updates {
+		u, err := update()
+		ez.AssertNoError(err)
+		ez.AssertNoError(file.Apply(u))
+		ez.Assert(audit(ez, owner, file))
+	}
+
+	data, err := owner.Decrypt(file.Units)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(string(data), ">> Hellorld! :)")
+}
+
+func Test__Dynamic__Decrypt__ShouldReturnOriginalValue(t *testing.T) {
+	ez := ez.New(t)
+	owner, file := uploaded(ez)
+
+	u, _ := owner.Modify(0, []byte("Jell"))
+	ez.AssertNoError(file.Apply(u))
+
+	data, err := owner.Decrypt(file.Units)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(string(data), "Jello, World!")
+}
+
+func Test__Dynamic__Rollback__ShouldNotVerify(t *testing.T) {
+	ez := ez.New(t)
+	owner, file := uploaded(ez)
+	old := *file
+	old.Units = append([]*dynamic.Unit{}, file.Units...)
+
+	u, _ := owner.Modify(0, []byte("Jell"))
+	ez.AssertNoError(file.Apply(u))
+
+	// a server that kept the old version cannot prove the new one...
+	challenge, _ := owner.Challenge(16)
+	_, err := old.Prove(por.DefaultParams, challenge)
+	ez.Assert(errors.Is(err, dynamic.ErrVersion))
+
+	// ...even if it lies about the version
+	old.Version = file.Version
+	challenge, _ = owner.Challenge(int(owner.UnitCount() * 4 * 8))
+	proof, err := old.Prove(por.DefaultParams, challenge)
+	ez.AssertNoError(err)
+	ez.AssertFalse(owner.Verify(challenge, proof))
+
+	_, err = owner.Decrypt(old.Units)
+	ez.Assert(errors.Is(err, dynamic.ErrVersion))
+}
+
+func Test__Dynamic__Decrypt__ShouldFail__WhenUnitDoesNotMatchItsTags(t *testing.T) {
+	ez := ez.New(t)
+	owner, file := uploaded(ez)
+	old := *file.Units[0]
+
+	u, _ := owner.Modify(0, []byte("Jell"))
+	ez.AssertNoError(file.Apply(u))
+
+	// the old content of the unit, under the id of the new one
+	file.Units[0] = &dynamic.Unit{ID: u.Unit.ID, Ciphertext: old.Ciphertext, Tags: u.Unit.Tags}
+	_, err := owner.Decrypt(file.Units)
+	ez.Assert(errors.Is(err, dynamic.ErrCorruptedUnit))
+
+	file.Units[0] = &dynamic.Unit{ID: u.Unit.ID, Ciphertext: old.Ciphertext, Tags: old.Tags}
+	_, err = owner.Decrypt(file.Units)
+	ez.Assert(errors.Is(err, dynamic.ErrCorruptedUnit))
+
+	file.Units[0] = &dynamic.Unit{ID: u.Unit.ID, Ciphertext: u.Unit.Ciphertext[:8], Tags: u.Unit.Tags[:1]}
+	_, err = owner.Decrypt(file.Units)
+	ez.Assert(errors.Is(err, dynamic.ErrCorruptedUnit))
+}
+
+func Test__Dynamic__Apply__ShouldFail__WhenUpdateIsReplayed(t *testing.T) {
+	ez := ez.New(t)
+	owner, file := uploaded(ez)
+
+	u, _ := owner.Append([]byte("!"))
+	ez.AssertNoError(file.Apply(u))
+
+	err := file.Apply(u)
+	ez.Assert(errors.Is(err, dynamic.ErrVersion))
+}
+
+func Test__Dynamic__Modify__ShouldFail__WhenPositionIsOutOfRange(t *testing.T) {
+	ez := ez.New(t)
+	owner, _ := uploaded(ez)
+
+	_, err := owner.Modify(owner.UnitCount(), []byte("x"))
+	ez.Assert(errors.Is(err, dynamic.ErrPosition))
+
+	_, err = owner.Append([]byte("too large"))
+	ez.Assert(errors.Is(err, dynamic.ErrUnitSize))
+}
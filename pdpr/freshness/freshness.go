@@ -0,0 +1,204 @@
+// Package freshness binds proofs to fresh challenges, so that proofs computed
+// in advance, or replayed from earlier audits, are rejected.
+//
+// The verifier issues a Challenge with a random nonce and an expiry. The blocks
+// and coefficients of the underlying por.Challenge are derived from the nonce,
+// so they cannot be predicted before the challenge is issued, and a proof for
+// one nonce does not verify for another. The server answers with a Response
+// carrying the transcript hash of the challenge, and the Verifier accepts each
+// challenge once, before it expires.
+//
+// Schemes whose challenges are computed in advance, as the tokens of package
+// pdpr, issue them with their own nonce: the expansion only depends on the
+// nonce and the number of blocks, so it is the same whenever it is issued.
+// Challenges without coefficients only sample blocks.
+package freshness
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	"github.com/titosilva/pdpr-go/crypto/random/drbg/sha256drbg"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+type Challenge struct {
+	FileID          string    `json:"file_id"`
+	Nonce           []byte    `json:"nonce"`
+	IssuedAt        time.Time `json:"issued_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	BlockCount      uint64    `json:"block_count"`
+	SampleCount     int       `json:"sample_count"`
+	CoefficientBits uint64    `json:"coefficient_bits"`
+}
+
+// Response is the answer of the server. Proof is the encoded proof of the
+// scheme being audited, e.g. a JSON public.Proof
+type Response struct {
+	ChallengeHash []byte `json:"challenge_hash"`
+	Proof         []byte `json:"proof"`
+}
+
+const NonceSize = 32
+
+var (
+	ErrExpired          = errors.New("challenge expired")
+	ErrUnknownChallenge = errors.New("challenge was not issued, or was already answered")
+)
+
+var (
+	challengeDomain  = []byte("pdpr-go/freshness/challenge/v1")
+	transcriptDomain = []byte("pdpr-go/freshness/transcript/v1")
+	expandDomain     = []byte("pdpr-go/freshness/expand/v2")
+)
+
+// NewChallenge creates a challenge of sampleCount blocks out of blockCount,
+// valid from now until now + ttl. coefficientBits is 0 for challenges without
+// coefficients
+func NewChallenge(fileID string, blockCount uint64, sampleCount int, coefficientBits uint64, now time.Time, ttl time.Duration) (*Challenge, error) {
+	nonce, err := random.GenerateBytes(NonceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewChallengeWithNonce(fileID, nonce, blockCount, sampleCount, coefficientBits, now, ttl)
+}
+
+// NewChallengeWithNonce is NewChallenge with the given nonce, which must be
+// unpredictable by the server and never issued twice
+func NewChallengeWithNonce(fileID string, nonce []byte, blockCount uint64, sampleCount int, coefficientBits uint64, now time.Time, ttl time.Duration) (*Challenge, error) {
+	if len(nonce) == 0 {
+		return nil, errorutils.New("empty nonce")
+	}
+
+	if sampleCount <= 0 || uint64(sampleCount) > blockCount {
+		return nil, errorutils.Newf("cannot sample %d blocks out of %d", sampleCount, blockCount)
+	}
+
+	if coefficientBits%64 != 0 {
+		return nil, errorutils.New("coefficient size must be a multiple of 64 bits")
+	}
+
+	r := new(Challenge)
+	r.FileID = fileID
+	r.Nonce = nonce
+	r.IssuedAt = now.UTC()
+	r.ExpiresAt = now.Add(ttl).UTC()
+	r.BlockCount = blockCount
+	r.SampleCount = sampleCount
+	r.CoefficientBits = coefficientBits
+
+	return r, nil
+}
+
+// Hash is the transcript hash of the challenge, which covers every field
+func (c *Challenge) Hash() []byte {
+	h := sha256.New()
+	h.Write(challengeDomain)
+	writeField(h, []byte(c.FileID))
+	writeField(h, c.Nonce)
+	writeField(h, binary.BigEndian.AppendUint64(nil, uint64(c.IssuedAt.UnixNano())))
+	writeField(h, binary.BigEndian.AppendUint64(nil, uint64(c.ExpiresAt.UnixNano())))
+	writeField(h, binary.BigEndian.AppendUint64(nil, c.BlockCount))
+	writeField(h, binary.BigEndian.AppendUint64(nil, uint64(c.SampleCount)))
+	writeField(h, binary.BigEndian.AppendUint64(nil, c.CoefficientBits))
+
+	return h.Sum(nil)
+}
+
+func (c *Challenge) Expired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// Expand derives the blocks and coefficients of the challenge from its nonce
+// and shape. The file and the times are left out, so that challenges computed
+// in advance expand the same whenever they are issued; they are covered by the
+// transcript hash instead
+func (c *Challenge) Expand() (*por.Challenge, error) {
+	if len(c.Nonce) == 0 || c.SampleCount <= 0 || uint64(c.SampleCount) > c.BlockCount || c.CoefficientBits%64 != 0 {
+		return nil, por.ErrMalformedChallenge
+	}
+
+	h := sha256.New()
+	h.Write(expandDomain)
+	writeField(h, c.Nonce)
+	writeField(h, binary.BigEndian.AppendUint64(nil, c.BlockCount))
+	writeField(h, binary.BigEndian.AppendUint64(nil, uint64(c.SampleCount)))
+	writeField(h, binary.BigEndian.AppendUint64(nil, c.CoefficientBits))
+
+	drbg := sha256drbg.New()
+	drbg.Seed(h.Sum(nil))
+
+	r := new(por.Challenge)
+	chosen := make(map[uint64]bool, c.SampleCount)
+	// rejection sampling avoids the modulo bias
+	limit := ^uint64(0) - ^uint64(0)%c.BlockCount
+
+	for len(r.Indices) < c.SampleCount {
+		bs, _ := drbg.Generate(8)
+		v := binary.BigEndian.Uint64(bs)
+		if v >= limit || chosen[v%c.BlockCount] {
+			continue
+		}
+
+		chosen[v%c.BlockCount] = true
+		r.Indices = append(r.Indices, v%c.BlockCount)
+	}
+
+	for range r.Indices {
+		if c.CoefficientBits == 0 {
+			break
+		}
+
+		bs, _ := drbg.Generate(int(c.CoefficientBits / 8))
+		coefficient, err := uintp.FromBytes(c.CoefficientBits, bs)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Coefficients = append(r.Coefficients, coefficient)
+	}
+
+	return r, nil
+}
+
+// Respond is run by the server: it refuses expired challenges, and proves the
+// expanded challenge with prove
+func Respond(challenge *Challenge, now time.Time, prove func(*por.Challenge) ([]byte, error)) (*Response, error) {
+	if challenge.Expired(now) {
+		return nil, ErrExpired
+	}
+
+	expanded, err := challenge.Expand()
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := prove(expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{ChallengeHash: challenge.Hash(), Proof: proof}, nil
+}
+
+// Transcript hashes the challenge together with the proof, identifying the whole exchange
+func (r *Response) Transcript() []byte {
+	h := sha256.New()
+	h.Write(transcriptDomain)
+	writeField(h, r.ChallengeHash)
+	writeField(h, r.Proof)
+
+	return h.Sum(nil)
+}
+
+// writeField writes a length-prefixed field, so that fields cannot be shifted into each other
+func writeField(h interface{ Write([]byte) (int, error) }, field []byte) {
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(field))))
+	h.Write(field)
+}
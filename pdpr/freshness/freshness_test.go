@@ -0,0 +1,143 @@
+package freshness_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+	"github.com/titosilva/pdpr-go/pdpr/public"
+)
+
+// prover tags a file for public audits, and returns the prove function of
+// its server
+func prover(ez *ez.EzTest) (func(*por.Challenge) ([]byte, error), *public.PublicInfo) {
+	ct := gcrypt.MustNew(64).MustEncrypt([]byte("Hello, World! This file is audited often."), []byte("This is a key"))
+
+	tags, info, err := public.NewTags(public.Params{SectorCount: 2}, ct)
+	ez.AssertNoError(err)
+
+	prove := func(c *por.Challenge) ([]byte, error) {
+		proof, err := public.Prove(ct, tags, c)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(proof)
+	}
+
+	return prove, info
+}
+
+func verify(info *public.PublicInfo, expanded *por.Challenge, response *freshness.Response) bool {
+	proof := new(public.Proof)
+	if err := json.Unmarshal(response.Proof, proof); err != nil {
+		return false
+	}
+
+	return public.Verify(info, expanded, proof)
+}
+
+func Test__Freshness__Challenge__ShouldExpandDeterministically(t *testing.T) {
+	ez := ez.New(t)
+	c, err := freshness.NewChallenge("file", 100, 10, 128, time.Now(), time.Minute)
+	ez.AssertNoError(err)
+
+	first, err := c.Expand()
+	ez.AssertNoError(err)
+	second, _ := c.Expand()
+	ez.AssertAreEqual(first, second)
+	ez.AssertAreEqual(len(first.Indices), 10)
+
+	// the challenge survives encoding, as the server gets it over the wire
+	bs, _ := json.Marshal(c)
+	decoded := new(freshness.Challenge)
+	ez.AssertNoError(json.Unmarshal(bs, decoded))
+	ez.AssertAreEqual(decoded.Hash(), c.Hash())
+
+	other, _ := freshness.NewChallenge("file", 100, 10, 128, time.Now(), time.Minute)
+	expanded, _ := other.Expand()
+	ez.AssertFalse(expanded.Coefficients[0].Equals(first.Coefficients[0]))
+}
+
+func Test__Freshness__ChallengeWithNonce__ShouldExpandTheSame__WhenIssuedAtAnotherTime(t *testing.T) {
+	ez := ez.New(t)
+	nonce := []byte("This is a nonce computed in advance")
+
+	c, err := freshness.NewChallengeWithNonce("file", nonce, 100, 10, 0, time.Now(), time.Minute)
+	ez.AssertNoError(err)
+	later, err := freshness.NewChallengeWithNonce("file", nonce, 100, 10, 0, time.Now().Add(time.Hour), time.Minute)
+	ez.AssertNoError(err)
+	ez.AssertFalse(bytes.Equal(c.Hash(), later.Hash()))
+
+	expanded, err := c.Expand()
+	ez.AssertNoError(err)
+	expandedLater, _ := later.Expand()
+	ez.AssertAreEqual(expanded.Indices, expandedLater.Indices)
+	ez.AssertAreEqual(len(expanded.Indices), 10)
+	ez.AssertAreEqual(len(expanded.Coefficients), 0)
+
+	_, err = freshness.NewChallengeWithNonce("file", nil, 100, 10, 0, time.Now(), time.Minute)
+	ez.Assert(err != nil)
+}
+
+func Test__Freshness__Verifier__ShouldAcceptResponseOnce(t *testing.T) {
+	ez := ez.New(t)
+	prove, info := prover(ez)
+	verifier := freshness.NewVerifier(time.Minute)
+
+	c, err := verifier.Issue("file", info.BlockCount, 2, public.CoefficientBits)
+	ez.AssertNoError(err)
+
+	response, err := freshness.Respond(c, time.Now(), prove)
+	ez.AssertNoError(err)
+
+	_, expanded, err := verifier.Accept(response)
+	ez.AssertNoError(err)
+	ez.Assert(verify(info, expanded, response))
+	ez.AssertAreEqual(verifier.Outstanding(), 0)
+
+	_, _, err = verifier.Accept(response)
+	ez.Assert(errors.Is(err, freshness.ErrUnknownChallenge))
+}
+
+func Test__Freshness__Verifier__ShouldReject__ReplayedProof(t *testing.T) {
+	ez := ez.New(t)
+	prove, info := prover(ez)
+	verifier := freshness.NewVerifier(time.Minute)
+
+	old, _ := verifier.Issue("file", info.BlockCount, 2, public.CoefficientBits)
+	oldResponse, _ := freshness.Respond(old, time.Now(), prove)
+
+	// the server replays the old proof for a new challenge
+	c, _ := verifier.Issue("file", info.BlockCount, 2, public.CoefficientBits)
+	replayed := &freshness.Response{ChallengeHash: c.Hash(), Proof: oldResponse.Proof}
+
+	_, expanded, err := verifier.Accept(replayed)
+	ez.AssertNoError(err)
+	ez.AssertFalse(verify(info, expanded, replayed))
+}
+
+func Test__Freshness__Verifier__ShouldReject__ExpiredChallenge(t *testing.T) {
+	ez := ez.New(t)
+	prove, info := prover(ez)
+	now := time.Now()
+
+	verifier := freshness.NewVerifier(time.Minute)
+	verifier.Clock = func() time.Time { return now }
+
+	c, _ := verifier.Issue("file", info.BlockCount, 2, public.CoefficientBits)
+	_, err := freshness.Respond(c, now.Add(2*time.Minute), prove)
+	ez.Assert(errors.Is(err, freshness.ErrExpired))
+
+	response, _ := freshness.Respond(c, now, prove)
+	now = now.Add(2 * time.Minute)
+
+	_, _, err = verifier.Accept(response)
+	ez.Assert(errors.Is(err, freshness.ErrExpired))
+}
@@ -0,0 +1,98 @@
+package freshness
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+// Verifier issues challenges and accepts each response once, before the
+// challenge expires. Clock defaults to time.Now, and can be replaced in tests
+type Verifier struct {
+	Clock func() time.Time
+
+	ttl         time.Duration
+	outstanding map[string]*Challenge
+	mutex       sync.Mutex
+}
+
+func NewVerifier(ttl time.Duration) *Verifier {
+	r := new(Verifier)
+	r.Clock = time.Now
+	r.ttl = ttl
+	r.outstanding = make(map[string]*Challenge)
+
+	return r
+}
+
+func (v *Verifier) Issue(fileID string, blockCount uint64, sampleCount int, coefficientBits uint64) (*Challenge, error) {
+	nonce, err := random.GenerateBytes(NonceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.IssueWithNonce(fileID, nonce, blockCount, sampleCount, coefficientBits)
+}
+
+// IssueWithNonce is Issue with the nonce of a challenge computed in advance
+func (v *Verifier) IssueWithNonce(fileID string, nonce []byte, blockCount uint64, sampleCount int, coefficientBits uint64) (*Challenge, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	now := v.Clock()
+	v.purge(now)
+
+	r, err := NewChallengeWithNonce(fileID, nonce, blockCount, sampleCount, coefficientBits, now, v.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	v.outstanding[hex.EncodeToString(r.Hash())] = r
+	return r, nil
+}
+
+// Accept checks that the response answers an outstanding challenge in time, and
+// returns the challenge, expanded, for the proof to be verified against.
+// The challenge is consumed even if the proof later fails to verify
+func (v *Verifier) Accept(response *Response) (*Challenge, *por.Challenge, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	key := hex.EncodeToString(response.ChallengeHash)
+	challenge, ok := v.outstanding[key]
+	if !ok {
+		return nil, nil, ErrUnknownChallenge
+	}
+	delete(v.outstanding, key)
+
+	if challenge.Expired(v.Clock()) {
+		return nil, nil, errorutils.NewfWithInner(ErrExpired, "challenge for %s expired at %s", challenge.FileID, challenge.ExpiresAt)
+	}
+
+	expanded, err := challenge.Expand()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return challenge, expanded, nil
+}
+
+// Outstanding is the number of challenges waiting for a response
+func (v *Verifier) Outstanding() int {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return len(v.outstanding)
+}
+
+func (v *Verifier) purge(now time.Time) {
+	for key, c := range v.outstanding {
+		if c.Expired(now) {
+			delete(v.outstanding, key)
+		}
+	}
+}
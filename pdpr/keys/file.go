@@ -0,0 +1,282 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+)
+
+// Key files are either PEM or JSON. In PEM files, everything but the material
+// goes in the headers; a wrapped key is an "ENCRYPTED PDPR KEY" block:
+//
+//	-----BEGIN ENCRYPTED PDPR KEY-----
+//	Key-Id: 3f1c0e9d2a7b5c48
+//	Algorithm: pdpr-gcrypt-ghash
+//	Modulus-Bits: 128
+//	Chunk-Count: 500
+//	Bits-Per-Block: 8
+//	Kdf: argon2id,t=3,m=65536,p=4
+//	Salt: 6b3e...
+//	Nonce: 91c2...
+//
+//	base64 of the wrapped material
+//	-----END ENCRYPTED PDPR KEY-----
+
+type Format int
+
+const (
+	FormatPEM Format = iota
+	FormatJSON
+)
+
+const (
+	pemType        = "PDPR KEY"
+	pemTypeWrapped = "ENCRYPTED PDPR KEY"
+)
+
+// Encoder is implemented by Key and WrappedKey
+type Encoder interface {
+	Encode(format Format) ([]byte, error)
+}
+
+var _ Encoder = (*Key)(nil)
+var _ Encoder = (*WrappedKey)(nil)
+
+func (k *Key) Encode(format Format) ([]byte, error) {
+	if format == FormatJSON {
+		return encodeJSON(k)
+	}
+
+	block := &pem.Block{Type: pemType, Headers: headers(k.ID, k.Algorithm, k.Params), Bytes: k.Material}
+	return pem.EncodeToMemory(block), nil
+}
+
+func (w *WrappedKey) Encode(format Format) ([]byte, error) {
+	if format == FormatJSON {
+		return encodeJSON(w)
+	}
+
+	h := headers(w.ID, w.Algorithm, w.Params)
+	h["Kdf"] = w.KDF.String()
+	h["Salt"] = hex.EncodeToString(w.KDF.Salt)
+	h["Nonce"] = hex.EncodeToString(w.Nonce)
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeWrapped, Headers: h, Bytes: w.Ciphertext}), nil
+}
+
+// WriteFile writes an encoded key readable only by its owner
+func WriteFile(path string, key Encoder, format Format) error {
+	bs, err := key.Encode(format)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, bs, 0600)
+}
+
+// ReadFile reads a key file of any format. The passphrase is only used for wrapped keys
+func ReadFile(path string, passphrase []byte) (*Key, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := Decode(bs, passphrase)
+	if err != nil {
+		return nil, errorutils.NewfWithInner(err, "could not read key %s", path)
+	}
+
+	return r, nil
+}
+
+// Decode decodes a key of any format, unwrapping it if needed
+func Decode(bs []byte, passphrase []byte) (*Key, error) {
+	key, wrapped, err := decode(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	if wrapped != nil {
+		if passphrase == nil {
+			return nil, errorutils.NewWithInner(ErrPassphrase, "the key is wrapped, and no passphrase was given")
+		}
+
+		return wrapped.Unwrap(passphrase)
+	}
+
+	if err := key.check(); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// DecodeWrapped decodes a wrapped key without unwrapping it, e.g. to rewrap it
+func DecodeWrapped(bs []byte) (*WrappedKey, error) {
+	_, wrapped, err := decode(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	if wrapped == nil {
+		return nil, errorutils.NewWithInner(ErrInvalidKey, "the key is not wrapped")
+	}
+
+	return wrapped, nil
+}
+
+func decode(bs []byte) (*Key, *WrappedKey, error) {
+	trimmed := bytes.TrimSpace(bs)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return decodeJSON(trimmed)
+	}
+
+	block, _ := pem.Decode(trimmed)
+	if block == nil {
+		return nil, nil, errorutils.NewWithInner(ErrInvalidKey, "neither PEM nor JSON")
+	}
+
+	id, algorithm, params, err := parseHeaders(block.Headers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch block.Type {
+	case pemType:
+		return &Key{ID: id, Algorithm: algorithm, Params: params, Material: block.Bytes}, nil, nil
+	case pemTypeWrapped:
+		w := &WrappedKey{ID: id, Algorithm: algorithm, Params: params, Ciphertext: block.Bytes}
+		if w.KDF, err = parseKDF(block.Headers["Kdf"]); err != nil {
+			return nil, nil, err
+		}
+
+		w.KDF.Salt, err = hex.DecodeString(block.Headers["Salt"])
+		if err != nil {
+			return nil, nil, errorutils.NewWithInner(ErrInvalidKey, "malformed salt")
+		}
+
+		w.Nonce, err = hex.DecodeString(block.Headers["Nonce"])
+		if err != nil {
+			return nil, nil, errorutils.NewWithInner(ErrInvalidKey, "malformed nonce")
+		}
+
+		return nil, w, nil
+	default:
+		return nil, nil, errorutils.NewfWithInner(ErrInvalidKey, "unexpected PEM block %q", block.Type)
+	}
+}
+
+// jsonKey holds either kind of key; wrapped keys are told apart by their KDF
+type jsonKey struct {
+	WrappedKey
+	Material []byte `json:"material"`
+}
+
+func encodeJSON(key any) ([]byte, error) {
+	bs, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bs, '\n'), nil
+}
+
+func decodeJSON(bs []byte) (*Key, *WrappedKey, error) {
+	r := new(jsonKey)
+	if err := json.Unmarshal(bs, r); err != nil {
+		return nil, nil, errorutils.NewWithInner(ErrInvalidKey, "malformed JSON key: "+err.Error())
+	}
+
+	if r.KDF.Name != "" {
+		return nil, &r.WrappedKey, nil
+	}
+
+	return &Key{ID: r.ID, Algorithm: r.Algorithm, Params: r.Params, Material: r.Material}, nil, nil
+}
+
+func headers(id ID, algorithm Algorithm, params pdpr.Params) map[string]string {
+	r := map[string]string{
+		"Key-Id":       string(id),
+		"Algorithm":    string(algorithm),
+		"Modulus-Bits": strconv.FormatUint(uint64(params.ModulusBits), 10),
+		"Chunk-Count":  strconv.FormatUint(uint64(params.ChunkCount), 10),
+	}
+
+	// only keys with a compact encoding have one
+	if params.BitsPerBlock != 0 {
+		r["Bits-Per-Block"] = strconv.FormatUint(uint64(params.BitsPerBlock), 10)
+	}
+
+	return r
+}
+
+func parseHeaders(h map[string]string) (ID, Algorithm, pdpr.Params, error) {
+	modulus, err := strconv.ParseUint(h["Modulus-Bits"], 10, 32)
+	if err != nil {
+		return "", "", pdpr.Params{}, errorutils.NewWithInner(ErrInvalidKey, "malformed Modulus-Bits")
+	}
+
+	chunks, err := strconv.ParseUint(h["Chunk-Count"], 10, 32)
+	if err != nil {
+		return "", "", pdpr.Params{}, errorutils.NewWithInner(ErrInvalidKey, "malformed Chunk-Count")
+	}
+
+	params := pdpr.Params{ModulusBits: uint(modulus), ChunkCount: uint(chunks)}
+	if s, ok := h["Bits-Per-Block"]; ok {
+		bits, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return "", "", pdpr.Params{}, errorutils.NewWithInner(ErrInvalidKey, "malformed Bits-Per-Block")
+		}
+		params.BitsPerBlock = uint(bits)
+	}
+
+	return ID(h["Key-Id"]), Algorithm(h["Algorithm"]), params, nil
+}
+
+// String formats the costs of the KDF, e.g. "scrypt,n=32768,r=8,p=1"
+func (kdf KDF) String() string {
+	switch kdf.Name {
+	case "argon2id":
+		return fmt.Sprintf("%s,t=%d,m=%d,p=%d", kdf.Name, kdf.Time, kdf.Memory, kdf.Threads)
+	case "scrypt":
+		return fmt.Sprintf("%s,n=%d,r=%d,p=%d", kdf.Name, kdf.N, kdf.R, kdf.P)
+	default:
+		return kdf.Name
+	}
+}
+
+func parseKDF(s string) (KDF, error) {
+	name, rest, _ := strings.Cut(s, ",")
+	costs := make(map[string]uint64)
+
+	for _, field := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(field, "=")
+		n, err := strconv.ParseUint(v, 10, 32)
+		if !ok || err != nil {
+			return KDF{}, errorutils.NewfWithInner(ErrInvalidKey, "malformed kdf %q", s)
+		}
+
+		costs[k] = n
+	}
+
+	switch name {
+	case "argon2id":
+		if costs["p"] > 255 {
+			return KDF{}, errorutils.NewWithInner(ErrInvalidKey, "too many argon2id threads")
+		}
+
+		return KDF{Name: name, Time: uint32(costs["t"]), Memory: uint32(costs["m"]), Threads: uint8(costs["p"])}, nil
+	case "scrypt":
+		return KDF{Name: name, N: int(costs["n"]), R: int(costs["r"]), P: int(costs["p"])}, nil
+	default:
+		return KDF{}, errorutils.NewfWithInner(ErrInvalidKey, "unknown kdf %q", name)
+	}
+}
@@ -0,0 +1,155 @@
+// Package keys manages the secret keys of PDPr owners: generation, key IDs,
+// key files and passphrase wrapping, and keyrings to look keys up by ID.
+package keys
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Algorithm names the scheme a key is used with
+type Algorithm string
+
+// AlgorithmPDPr is PDPr over GCrypt and GHash, as implemented by package pdpr
+const AlgorithmPDPr Algorithm = "pdpr-gcrypt-ghash"
+
+// AlgorithmDLHH is the discrete logarithm backend of cmd/pdpr, whose keys have
+// no parameters. Only AlgorithmPDPr keys can be used with the methods of Key
+const AlgorithmDLHH Algorithm = "pdpr-dlhh"
+
+// ID identifies a key without revealing it
+type ID string
+
+// Key is a secret key, along with the parameters it is used with
+type Key struct {
+	ID        ID          `json:"id"`
+	Algorithm Algorithm   `json:"algorithm"`
+	Params    pdpr.Params `json:"params"`
+	Material  []byte      `json:"material"`
+}
+
+// DefaultSize is the size in bytes of generated keys
+const DefaultSize = 32
+
+const idSize = 8
+
+var idSalt = []byte("pdpr-go/keys/id/v1")
+
+var ErrInvalidKey = errors.New("invalid key")
+
+// Generate creates a key of DefaultSize random bytes
+func Generate(params pdpr.Params) (*Key, error) {
+	material, err := random.GenerateBytes(DefaultSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(params, material)
+}
+
+// New creates a key from existing material, e.g. to migrate raw keys
+func New(params pdpr.Params, material []byte) (*Key, error) {
+	return NewWithAlgorithm(AlgorithmPDPr, params, material)
+}
+
+// NewWithAlgorithm is New for keys of any algorithm
+func NewWithAlgorithm(algorithm Algorithm, params pdpr.Params, material []byte) (*Key, error) {
+	switch algorithm {
+	case AlgorithmPDPr:
+		if params.ModulusBits == 0 || params.ModulusBits%64 != 0 || params.ChunkCount == 0 || params.Encoding().Check() != nil {
+			return nil, errorutils.NewWithInner(ErrInvalidKey, "invalid parameters")
+		}
+	case AlgorithmDLHH:
+		if params != (pdpr.Params{}) {
+			return nil, errorutils.NewWithInner(ErrInvalidKey, "dlhh keys have no parameters")
+		}
+	default:
+		return nil, errorutils.NewfWithInner(ErrInvalidKey, "unknown algorithm %q", algorithm)
+	}
+
+	if len(material) == 0 {
+		return nil, errorutils.NewWithInner(ErrInvalidKey, "empty key material")
+	}
+
+	r := new(Key)
+	r.Algorithm = algorithm
+	r.Params = params
+	r.Material = append([]byte{}, material...)
+	r.ID = computeID(r.Algorithm, r.Params, r.Material)
+
+	return r, nil
+}
+
+// computeID binds the ID to the parameters, so the same material used with
+// different parameters yields different IDs
+func computeID(algorithm Algorithm, params pdpr.Params, material []byte) ID {
+	info := []byte(algorithm)
+	info = binary.BigEndian.AppendUint64(info, uint64(params.ModulusBits))
+	info = binary.BigEndian.AppendUint64(info, uint64(params.ChunkCount))
+	info = appendEncoding(info, params)
+
+	kdf := hkdf.New(sha256.New, material, idSalt, info)
+
+	r := make([]byte, idSize)
+	if _, err := io.ReadFull(kdf, r); err != nil {
+		// hkdf only fails when more than 255 hash blocks are requested
+		panic(err)
+	}
+
+	return ID(hex.EncodeToString(r))
+}
+
+// check validates a key read from elsewhere, e.g. from a key file
+func (k *Key) check() error {
+	expected, err := NewWithAlgorithm(k.Algorithm, k.Params, k.Material)
+	if err != nil {
+		return err
+	}
+
+	if expected.ID != k.ID {
+		return errorutils.NewfWithInner(ErrInvalidKey, "key %s does not match its ID", k.ID)
+	}
+
+	return nil
+}
+
+func (k *Key) Encrypt(data []byte) ([]byte, error) {
+	return pdpr.Encrypt(k.Params, k.Material, data)
+}
+
+func (k *Key) Decrypt(ciphertext []byte, length int) ([]byte, error) {
+	return pdpr.Decrypt(k.Params, k.Material, ciphertext, length)
+}
+
+// Tag is pdpr.NewTag, and records the ID of the key in the tag
+func (k *Key) Tag(ciphertext []byte, length int) (*pdpr.Tag, *pdpr.State, error) {
+	tag, state, err := pdpr.NewTag(k.Params, k.Material, ciphertext, length)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tag.KeyID = string(k.ID)
+	return tag, state, nil
+}
+
+func (k *Key) Verify(tag *pdpr.Tag, challenge *pdpr.Challenge, proof []byte) bool {
+	return pdpr.Verify(k.Params, k.Material, tag, challenge, proof)
+}
+
+// appendEncoding appends a compact encoding, and nothing for the bit encoding,
+// so that the IDs and wrappings of earlier keys do not change
+func appendEncoding(bs []byte, params pdpr.Params) []byte {
+	if params.BitsPerBlock == 0 {
+		return bs
+	}
+
+	return binary.BigEndian.AppendUint64(bs, uint64(params.BitsPerBlock))
+}
@@ -0,0 +1,115 @@
+package keys
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+)
+
+// Keyring holds the keys of an owner or verifier, indexed by ID
+type Keyring struct {
+	keys  map[ID]*Key
+	mutex sync.RWMutex
+}
+
+var ErrUnknownKey = errors.New("unknown key")
+
+func NewKeyring(keys ...*Key) *Keyring {
+	r := new(Keyring)
+	r.keys = make(map[ID]*Key)
+
+	for _, k := range keys {
+		r.keys[k.ID] = k
+	}
+
+	return r
+}
+
+// LoadKeyring reads every key file in dir, unwrapping them with the passphrase
+func LoadKeyring(dir string, passphrase []byte) (*Keyring, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewKeyring()
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		k, err := ReadFile(filepath.Join(dir, e.Name()), passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Add(k)
+	}
+
+	return r, nil
+}
+
+func (kr *Keyring) Add(key *Key) {
+	kr.mutex.Lock()
+	defer kr.mutex.Unlock()
+
+	kr.keys[key.ID] = key
+}
+
+func (kr *Keyring) Get(id ID) (*Key, error) {
+	kr.mutex.RLock()
+	defer kr.mutex.RUnlock()
+
+	k, ok := kr.keys[id]
+	if !ok {
+		return nil, errorutils.NewfWithInner(ErrUnknownKey, "key %s is not in the keyring", id)
+	}
+
+	return k, nil
+}
+
+func (kr *Keyring) Remove(id ID) {
+	kr.mutex.Lock()
+	defer kr.mutex.Unlock()
+
+	delete(kr.keys, id)
+}
+
+// IDs returns the IDs of the keys in the keyring, sorted
+func (kr *Keyring) IDs() []ID {
+	kr.mutex.RLock()
+	defer kr.mutex.RUnlock()
+
+	r := make([]ID, 0, len(kr.keys))
+	for id := range kr.keys {
+		r = append(r, id)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i] < r[j] })
+
+	return r
+}
+
+// Verify verifies a proof of a challenge with the key that computed the tag
+func (kr *Keyring) Verify(tag *pdpr.Tag, challenge *pdpr.Challenge, proof []byte) (bool, error) {
+	k, err := kr.Get(ID(tag.KeyID))
+	if err != nil {
+		return false, err
+	}
+
+	return k.Verify(tag, challenge, proof), nil
+}
+
+// Decrypt decrypts a ciphertext with the key that computed its tag
+func (kr *Keyring) Decrypt(tag *pdpr.Tag, ciphertext []byte) ([]byte, error) {
+	k, err := kr.Get(ID(tag.KeyID))
+	if err != nil {
+		return nil, err
+	}
+
+	return k.Decrypt(ciphertext, tag.Length)
+}
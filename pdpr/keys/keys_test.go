@@ -0,0 +1,218 @@
+package keys_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/keys"
+)
+
+var params = pdpr.Params{ModulusBits: 64, ChunkCount: 16}
+
+// cheap costs, to keep the tests fast
+var fastKDFs = []keys.KDF{
+	{Name: "argon2id", Time: 1, Memory: 64, Threads: 1},
+	{Name: "scrypt", N: 1 << 10, R: 8, P: 1},
+}
+
+func Test__Key__ID__ShouldDependOn__MaterialAndParams(t *testing.T) {
+	ez := ez.New(t)
+
+	a, err := keys.New(params, []byte("This is a key"))
+	ez.AssertNoError(err)
+	b, _ := keys.New(params, []byte("This is a key"))
+	c, _ := keys.New(params, []byte("This is another key"))
+	d, _ := keys.New(pdpr.Params{ModulusBits: 128, ChunkCount: 16}, []byte("This is a key"))
+	e, _ := keys.New(pdpr.Params{ModulusBits: 64, ChunkCount: 16, BitsPerBlock: 8}, []byte("This is a key"))
+
+	ez.AssertAreEqual(a.ID, b.ID)
+	ez.Assert(a.ID != c.ID)
+	ez.Assert(a.ID != d.ID)
+	ez.Assert(a.ID != e.ID)
+	ez.AssertAreEqual(len(a.ID), 16)
+
+	_, err = keys.New(pdpr.Params{ModulusBits: 100, ChunkCount: 16}, []byte("This is a key"))
+	ez.Assert(errors.Is(err, keys.ErrInvalidKey))
+
+	f, err := keys.NewWithAlgorithm(keys.AlgorithmDLHH, pdpr.Params{}, []byte("This is a key"))
+	ez.AssertNoError(err)
+	ez.Assert(a.ID != f.ID)
+
+	_, err = keys.NewWithAlgorithm(keys.AlgorithmDLHH, params, []byte("This is a key"))
+	ez.Assert(errors.Is(err, keys.ErrInvalidKey))
+	_, err = keys.NewWithAlgorithm("unknown", params, []byte("This is a key"))
+	ez.Assert(errors.Is(err, keys.ErrInvalidKey))
+}
+
+func Test__Key__EncodeThenDecode__ShouldReturnOriginalKey(t *testing.T) {
+	ez := ez.New(t)
+
+	for _, p := range []pdpr.Params{params, {ModulusBits: 64, ChunkCount: 16, BitsPerBlock: 16}} {
+		key, err := keys.Generate(p)
+		ez.AssertNoError(err)
+
+		for _, format := range []keys.Format{keys.FormatPEM, keys.FormatJSON} {
+			bs, err := key.Encode(format)
+			ez.AssertNoError(err)
+
+			decoded, err := keys.Decode(bs, nil)
+			ez.AssertNoError(err)
+			ez.AssertAreEqual(decoded, key)
+		}
+	}
+}
+
+func Test__Key__Decode__ShouldFail__WhenIDDoesNotMatch(t *testing.T) {
+	ez := ez.New(t)
+	key, _ := keys.Generate(params)
+	key.Material[0] ^= 1
+
+	bs, _ := key.Encode(keys.FormatPEM)
+	_, err := keys.Decode(bs, nil)
+	ez.Assert(errors.Is(err, keys.ErrInvalidKey))
+}
+
+func Test__WrappedKey__EncodeThenUnwrap__ShouldReturnOriginalKey(t *testing.T) {
+	ez := ez.New(t)
+	key, _ := keys.Generate(params)
+	passphrase := []byte("correct horse battery staple")
+
+	for _, kdf := range fastKDFs {
+		wrapped, err := keys.Wrap(key, passphrase, kdf)
+		ez.AssertNoError(err)
+
+		for _, format := range []keys.Format{keys.FormatPEM, keys.FormatJSON} {
+			bs, err := wrapped.Encode(format)
+			ez.AssertNoError(err)
+
+			decoded, err := keys.Decode(bs, passphrase)
+			ez.AssertNoError(err)
+			ez.AssertAreEqual(decoded, key)
+
+			_, err = keys.Decode(bs, []byte("wrong"))
+			ez.Assert(errors.Is(err, keys.ErrPassphrase))
+
+			_, err = keys.Decode(bs, nil)
+			ez.Assert(errors.Is(err, keys.ErrPassphrase))
+		}
+	}
+}
+
+func Test__WrappedKey__Unwrap__ShouldFail__WhenHeaderIsTampered(t *testing.T) {
+	ez := ez.New(t)
+	key, _ := keys.Generate(params)
+	passphrase := []byte("correct horse battery staple")
+
+	wrapped, _ := keys.Wrap(key, passphrase, fastKDFs[0])
+	wrapped.Params.ChunkCount = 32
+
+	_, err := wrapped.Unwrap(passphrase)
+	ez.Assert(errors.Is(err, keys.ErrPassphrase))
+
+	wrapped, _ = keys.Wrap(key, passphrase, fastKDFs[0])
+	wrapped.KDF.Memory = 1 << 30
+	_, err = wrapped.Unwrap(passphrase)
+	ez.Assert(errors.Is(err, keys.ErrInvalidKey))
+}
+
+func Test__WrappedKey__Unwrap__ShouldFail__WhenKDFCostsAreTooHigh(t *testing.T) {
+	ez := ez.New(t)
+	key, _ := keys.Generate(params)
+	passphrase := []byte("correct horse battery staple")
+
+	for _, kdf := range []keys.KDF{
+		{Name: "argon2id", Time: 1, Memory: 1<<20 + 1, Threads: 1},
+		{Name: "argon2id", Time: 1 << 20, Memory: 64, Threads: 1},
+		{Name: "argon2id", Time: 1, Memory: 64, Threads: 255},
+		{Name: "scrypt", N: 1 << 20, R: 8, P: 1},
+		{Name: "scrypt", N: 1 << 10, R: 1 << 20, P: 1},
+		{Name: "scrypt", N: 1 << 10, R: 8, P: 1 << 10},
+		{Name: "scrypt", N: 1000, R: 8, P: 1},
+		{Name: "scrypt", N: 1, R: 8, P: 1},
+		{Name: "scrypt", N: 1 << 62, R: 1 << 62, P: 1},
+	} {
+		wrapped, _ := keys.Wrap(key, passphrase, fastKDFs[0])
+		wrapped.KDF = kdf
+		wrapped.KDF.Salt = make([]byte, 16)
+
+		_, err := wrapped.Unwrap(passphrase)
+		ez.Assert(errors.Is(err, keys.ErrInvalidKey))
+
+		_, err = keys.Wrap(key, passphrase, kdf)
+		ez.Assert(errors.Is(err, keys.ErrInvalidKey))
+	}
+}
+
+func Test__WrappedKey__DefaultKDFs__ShouldUnwrap(t *testing.T) {
+	ez := ez.New(t)
+	key, _ := keys.Generate(params)
+
+	for _, kdf := range []keys.KDF{keys.Argon2id, keys.Scrypt} {
+		wrapped, err := keys.Wrap(key, []byte("passphrase"), kdf)
+		ez.AssertNoError(err)
+
+		unwrapped, err := wrapped.Unwrap([]byte("passphrase"))
+		ez.AssertNoError(err)
+		ez.AssertAreEqual(unwrapped, key)
+	}
+}
+
+func Test__Keyring__ShouldVerifyProofs__ByKeyID(t *testing.T) {
+	ez := ez.New(t)
+	dir := t.TempDir()
+	passphrase := []byte("passphrase")
+	data := []byte("Hello, World!")
+
+	first, _ := keys.Generate(params)
+	second, _ := keys.Generate(params)
+	ez.AssertNoError(keys.WriteFile(filepath.Join(dir, "first.pem"), first, keys.FormatPEM))
+
+	wrapped, _ := keys.Wrap(second, passphrase, fastKDFs[1])
+	ez.AssertNoError(keys.WriteFile(filepath.Join(dir, "second.json"), wrapped, keys.FormatJSON))
+
+	keyring, err := keys.LoadKeyring(dir, passphrase)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(len(keyring.IDs()), 2)
+
+	ct, err := second.Encrypt(data)
+	ez.AssertNoError(err)
+	tag, state, err := second.Tag(ct, len(data))
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(keys.ID(tag.KeyID), second.ID)
+
+	challenge, _ := tag.NextChallenge(params)
+	proof, _ := pdpr.Prove(params, ct, state, challenge)
+	ok, err := keyring.Verify(tag, challenge, proof)
+	ez.AssertNoError(err)
+	ez.Assert(ok)
+
+	decrypted, err := keyring.Decrypt(tag, ct)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(decrypted, data)
+
+	keyring.Remove(second.ID)
+	_, err = keyring.Verify(tag, challenge, proof)
+	ez.Assert(errors.Is(err, keys.ErrUnknownKey))
+}
+
+func Test__KeyShares__ThresholdShares__ShouldRebuildKey(t *testing.T) {
+	ez := ez.New(t)
+	key, _ := keys.Generate(params)
+
+	shares, commitment, err := keys.SplitKey(key, 3, 5)
+	ez.AssertNoError(err)
+
+	for _, s := range shares {
+		ez.Assert(keys.VerifyKeyShare(s, commitment))
+	}
+
+	rebuilt, err := keys.CombineKeyShares([]*keys.KeyShare{shares[4], shares[1], shares[2]})
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(rebuilt, key)
+
+	_, err = keys.CombineKeyShares(shares[:2])
+	ez.Assert(err != nil)
+}
@@ -0,0 +1,77 @@
+package keys
+
+import (
+	"github.com/titosilva/pdpr-go/crypto/sharing"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/dl"
+	"github.com/titosilva/pdpr-go/pdpr"
+)
+
+// KeyShare is a Feldman share of the material of a key, along with what is
+// needed to rebuild the key
+type KeyShare struct {
+	ID        ID             `json:"id"`
+	Algorithm Algorithm      `json:"algorithm"`
+	Params    pdpr.Params    `json:"params"`
+	Share     *sharing.Share `json:"share"`
+}
+
+func feldman() *sharing.Feldman {
+	r, err := sharing.NewFeldman(dl.NewOakley2Group())
+	if err != nil {
+		// the order of the Oakley group 2 is prime
+		panic(err)
+	}
+
+	return r
+}
+
+// SplitKey splits a key into count shares, any threshold of which rebuild it.
+// The commitment is given to every shareholder, to verify their shares
+func SplitKey(key *Key, threshold int, count int) ([]*KeyShare, *sharing.Commitment, error) {
+	shares, commitment, err := feldman().Split(key.Material, threshold, count)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := make([]*KeyShare, len(shares))
+	for i, s := range shares {
+		r[i] = &KeyShare{ID: key.ID, Algorithm: key.Algorithm, Params: key.Params, Share: s}
+	}
+
+	return r, commitment, nil
+}
+
+func VerifyKeyShare(share *KeyShare, commitment *sharing.Commitment) bool {
+	return share.Share != nil && feldman().Verify(share.Share, commitment)
+}
+
+// CombineKeyShares rebuilds a key, and checks it against the ID in the shares,
+// which fails when fewer than threshold shares are given
+func CombineKeyShares(shares []*KeyShare) (*Key, error) {
+	if len(shares) == 0 {
+		return nil, errorutils.NewWithInner(sharing.ErrInvalidShare, "no shares")
+	}
+
+	first := shares[0]
+	raw := make([]*sharing.Share, len(shares))
+	for i, s := range shares {
+		if s.ID != first.ID || s.Algorithm != first.Algorithm || s.Params != first.Params || s.Share == nil {
+			return nil, errorutils.NewWithInner(sharing.ErrInvalidShare, "shares of different keys")
+		}
+
+		raw[i] = s.Share
+	}
+
+	material, err := feldman().Combine(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Key{ID: first.ID, Algorithm: first.Algorithm, Params: first.Params, Material: material}
+	if err := r.check(); err != nil {
+		return nil, errorutils.NewWithInner(err, "not enough valid shares")
+	}
+
+	return r, nil
+}
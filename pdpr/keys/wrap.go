@@ -0,0 +1,181 @@
+package keys
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF derives the wrapping key from a passphrase.
+// Memory is in KiB, as in argon2.IDKey
+type KDF struct {
+	Name    string `json:"name"`
+	Salt    []byte `json:"salt,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+}
+
+// Argon2id follows the second recommendation of RFC 9106
+var Argon2id = KDF{Name: "argon2id", Time: 3, Memory: 64 * 1024, Threads: 4}
+
+var Scrypt = KDF{Name: "scrypt", N: 1 << 15, R: 8, P: 1}
+
+// WrappedKey is a key encrypted under a passphrase with XChaCha20-Poly1305.
+// Everything but the material is kept in the clear and authenticated
+type WrappedKey struct {
+	ID         ID          `json:"id"`
+	Algorithm  Algorithm   `json:"algorithm"`
+	Params     pdpr.Params `json:"params"`
+	KDF        KDF         `json:"kdf"`
+	Nonce      []byte      `json:"nonce"`
+	Ciphertext []byte      `json:"ciphertext"`
+}
+
+const saltSize = 16
+
+// bounds on the KDF costs of key files, which may come from untrusted sources:
+// argon2id may take 1 GiB and scrypt 128·N·r bytes, 256 MiB at most
+const (
+	maxArgon2Memory  = 1024 * 1024
+	maxArgon2Time    = 16
+	maxArgon2Threads = 16
+	maxScryptMemory  = 256 * 1024 * 1024
+	maxScryptP       = 16
+)
+
+var ErrPassphrase = errors.New("wrong passphrase or corrupted key")
+
+// Wrap encrypts the key under the passphrase, with a fresh salt for kdf
+func Wrap(key *Key, passphrase []byte, kdf KDF) (*WrappedKey, error) {
+	salt, err := random.GenerateBytes(saltSize)
+	if err != nil {
+		return nil, err
+	}
+	kdf.Salt = salt
+
+	wrapping, err := kdf.derive(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(wrapping)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := random.GenerateBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(WrappedKey)
+	r.ID = key.ID
+	r.Algorithm = key.Algorithm
+	r.Params = key.Params
+	r.KDF = kdf
+	r.Nonce = nonce
+	r.Ciphertext = aead.Seal(nil, nonce, key.Material, r.additionalData())
+
+	return r, nil
+}
+
+// Unwrap decrypts the key, failing with ErrPassphrase if the passphrase is wrong
+func (w *WrappedKey) Unwrap(passphrase []byte) (*Key, error) {
+	wrapping, err := w.KDF.derive(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(wrapping)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(w.Nonce) != aead.NonceSize() {
+		return nil, errorutils.NewWithInner(ErrInvalidKey, "nonce has the wrong size")
+	}
+
+	material, err := aead.Open(nil, w.Nonce, w.Ciphertext, w.additionalData())
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+
+	r := &Key{ID: w.ID, Algorithm: w.Algorithm, Params: w.Params, Material: material}
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (w *WrappedKey) additionalData() []byte {
+	kdf, err := json.Marshal(&w.KDF)
+	if err != nil {
+		panic(err)
+	}
+
+	r := []byte(w.ID)
+	r = append(r, 0)
+	r = append(r, w.Algorithm...)
+	r = append(r, 0)
+	r = binary.BigEndian.AppendUint64(r, uint64(w.Params.ModulusBits))
+	r = binary.BigEndian.AppendUint64(r, uint64(w.Params.ChunkCount))
+	r = appendEncoding(r, w.Params)
+	return append(r, kdf...)
+}
+
+func (kdf *KDF) derive(passphrase []byte) ([]byte, error) {
+	if err := kdf.check(); err != nil {
+		return nil, err
+	}
+
+	switch kdf.Name {
+	case "argon2id":
+		return argon2.IDKey(passphrase, kdf.Salt, kdf.Time, kdf.Memory, kdf.Threads, chacha20poly1305.KeySize), nil
+	default:
+		r, err := scrypt.Key(passphrase, kdf.Salt, kdf.N, kdf.R, kdf.P, chacha20poly1305.KeySize)
+		if err != nil {
+			return nil, errorutils.NewWithInner(ErrInvalidKey, err.Error())
+		}
+
+		return r, nil
+	}
+}
+
+// check rejects unknown KDFs and costs beyond the bounds above, before any
+// memory is allocated for them
+func (kdf *KDF) check() error {
+	if len(kdf.Salt) < saltSize {
+		return errorutils.NewWithInner(ErrInvalidKey, "salt is too short")
+	}
+
+	switch kdf.Name {
+	case "argon2id":
+		if kdf.Time == 0 || kdf.Time > maxArgon2Time || kdf.Memory == 0 || kdf.Memory > maxArgon2Memory ||
+			kdf.Threads == 0 || kdf.Threads > maxArgon2Threads {
+			return errorutils.NewWithInner(ErrInvalidKey, "invalid argon2id parameters")
+		}
+	case "scrypt":
+		// N is a power of two greater than one, and the products are checked
+		// by division, as they may overflow
+		if kdf.N <= 1 || kdf.N&(kdf.N-1) != 0 || kdf.R <= 0 || kdf.P <= 0 || kdf.P > maxScryptP ||
+			kdf.R > maxScryptMemory/128/kdf.N {
+			return errorutils.NewWithInner(ErrInvalidKey, "invalid scrypt parameters")
+		}
+	default:
+		return errorutils.NewfWithInner(ErrInvalidKey, "unknown kdf %q", kdf.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,359 @@
+package pdpr
+
+import (
+	"crypto/subtle"
+	"slices"
+	"time"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/crypto/hash/ghash"
+	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+)
+
+// PDPr over GCrypt and GHash, as in pdpr_bench_test.go.
+// The owner keeps a key nonce and the tokens of a tag, and the server keeps
+// the ciphertext and the sum of the data and key nonce states.
+// A proof answers a Challenge: it is a GHash over that sum of the challenged
+// blocks of the ciphertext, with index hashes keyed by the nonce of the challenge,
+// so it cannot be computed before the challenge is issued. The owner verifies it by
+// removing the GHash of the expanded key and the key nonce, which yields the
+// digest of the challenged blocks of the encoded data. As only the owner's data
+// can produce that digest, the tag holds the hashes of the digests of challenges
+// computed in advance, see Token
+
+type Params struct {
+	ModulusBits uint `json:"modulus_bits"`
+	ChunkCount  uint `json:"chunk_count"`
+	// BitsPerBlock packs as many plaintext bits in each GCrypt block, which
+	// shrinks the ciphertext; 0 is the bit encoding of one bit per block
+	BitsPerBlock uint `json:"bits_per_block,omitempty"`
+}
+
+// Presets for each security level, matching the LtHash presets
+var (
+	Level128 = Params{ModulusBits: 128, ChunkCount: 1024}
+	Level192 = Params{ModulusBits: 192, ChunkCount: 768}
+	Level256 = Params{ModulusBits: 256, ChunkCount: 768}
+)
+
+var DefaultParams = Level128
+
+// Tag is kept by the owner to verify proofs. Used counts the tokens already
+// issued by NextChallenge, so the tag must be saved again after each challenge.
+// KeyID is only set when the tag is computed through package keys
+type Tag struct {
+	Length      int     `json:"length"`
+	KeyNonce    []byte  `json:"key_nonce"`
+	SampleCount int     `json:"sample_count"`
+	Tokens      []Token `json:"tokens"`
+	Used        int     `json:"used"`
+	KeyID       string  `json:"key_id,omitempty"`
+}
+
+// State is given to the server along with the ciphertext, to compute proofs
+type State struct {
+	NonceState []byte `json:"nonce_state"`
+}
+
+const nonceSize = 32
+
+func (p Params) StateSize() int {
+	return int(p.ChunkCount * p.ModulusBits / 8)
+}
+
+// HashParams returns the parameters of the underlying LtHash
+func (p Params) HashParams() lthash.Params {
+	return lthash.Params{ChunkCount: p.ChunkCount, ChunkSizeBits: p.ModulusBits, BlockSizeBytes: int(p.ModulusBits / 8)}
+}
+
+// Encoding returns the GCrypt encoding of the plaintext
+func (p Params) Encoding() gcrypt.Encoding {
+	if p.BitsPerBlock == 0 {
+		return gcrypt.BitEncoding
+	}
+
+	return gcrypt.Encoding{BitsPerBlock: uint64(p.BitsPerBlock)}
+}
+
+// Check rejects parameters that GCrypt and GHash cannot work with
+func (p Params) Check() error {
+	if err := p.Encoding().Check(); err != nil {
+		return err
+	}
+
+	return p.HashParams().Check()
+}
+
+// Validate rejects inconsistent parameters, and parameters below 128 bits of estimated security
+func (p Params) Validate() error {
+	if err := p.Encoding().Check(); err != nil {
+		return err
+	}
+
+	return p.HashParams().Validate()
+}
+
+// SecurityBits returns the estimated security of the parameters, see lthash.Params.SecurityBits
+func (p Params) SecurityBits() int {
+	return p.HashParams().SecurityBits()
+}
+
+func (p Params) newHash() (*ghash.GHash, error) {
+	return ghash.NewWithParams(p.ChunkCount, p.ModulusBits, int(p.ModulusBits/8), nil)
+}
+
+// newChallengeHash returns a GHash whose index hashes are keyed by the nonce of
+// a challenge. Its nonce hashes are not used, as nonce states are always
+// computed by the unkeyed hash of newHash
+func (p Params) newChallengeHash(nonce []byte) (*ghash.GHash, error) {
+	return ghash.NewWithParams(p.ChunkCount, p.ModulusBits, int(p.ModulusBits/8), nonce)
+}
+
+func (p Params) crypt() (*gcrypt.GCrypt, error) {
+	return gcrypt.NewWithEncoding(uint64(p.ModulusBits), p.Encoding())
+}
+
+func Encrypt(params Params, key []byte, data []byte) ([]byte, error) {
+	crypt, err := params.crypt()
+	if err != nil {
+		return nil, err
+	}
+
+	return crypt.Encrypt(data, key)
+}
+
+// Decrypt decrypts a ciphertext of a plaintext of length bytes
+func Decrypt(params Params, key []byte, ciphertext []byte, length int) ([]byte, error) {
+	crypt, err := params.crypt()
+	if err != nil {
+		return nil, err
+	}
+
+	r := crypt.Decrypt(ciphertext, key)
+	if len(r) < length {
+		return nil, errorutils.New("ciphertext is truncated")
+	}
+
+	return r[:length], nil
+}
+
+// NewTag computes the tag of the owner and the state of the server for the
+// ciphertext of a plaintext of length bytes, with fresh random nonces and the
+// default tokens
+func NewTag(params Params, key []byte, ciphertext []byte, length int) (*Tag, *State, error) {
+	return NewTagWithTokens(params, key, ciphertext, length, DefaultTokenCount, DefaultSampleCount)
+}
+
+// NewTagWithTokens is NewTag with tokenCount tokens, each challenging up to
+// sampleCount blocks
+func NewTagWithTokens(params Params, key []byte, ciphertext []byte, length int, tokenCount int, sampleCount int) (*Tag, *State, error) {
+	dataNonce, err := random.GenerateBytes(nonceSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyNonce, err := random.GenerateBytes(nonceSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewTagWithNonces(params, key, ciphertext, length, tokenCount, sampleCount, dataNonce, keyNonce)
+}
+
+// NewTagWithNonces is NewTagWithTokens with the given nonces, which must be
+// fresh and secret. The nonces of the tokens are derived from the data nonce.
+// It is meant for test vectors
+func NewTagWithNonces(params Params, key []byte, ciphertext []byte, length int, tokenCount int, sampleCount int, dataNonce []byte, keyNonce []byte) (*Tag, *State, error) {
+	if tokenCount <= 0 || sampleCount <= 0 {
+		return nil, nil, errorutils.Newf("cannot precompute %d tokens of %d blocks", tokenCount, sampleCount)
+	}
+
+	crypt, err := params.crypt()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blockCount := crypt.BlockCount(length)
+	if len(ciphertext) != blockCount*int(params.ModulusBits/8) {
+		return nil, nil, errorutils.Newf("ciphertext of %d bytes for a plaintext of %d", len(ciphertext), length)
+	}
+
+	dataHash, err := params.newHash()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := dataHash.SetNonce(dataNonce); err != nil {
+		return nil, nil, err
+	}
+	dataNonceState := dataHash.GetNonceState()
+
+	// The encoded blocks are the blocks of the ciphertext minus the ones of the key
+	blocks := crypt.FromBytes(ciphertext)
+	keyBlocks := crypt.ExpandKey(key, blockCount)
+
+	tokens := make([]Token, tokenCount)
+	for j := range tokens {
+		nonce := tokenNonce(dataNonce, j)
+		indices := SampleIndices(nonce, uint64(blockCount), sampleCount)
+
+		hash, err := params.newChallengeHash(nonce)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		hash.SetNonceState(dataNonceState)
+		for _, i := range indices {
+			if err := hash.AddBlockWithIndex(uintp.Clone(blocks[i]).Sub(keyBlocks[i]), uint(i)); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		tokens[j] = Token{Nonce: nonce, Expected: expectedDigest(hash.GetDigest())}
+	}
+
+	keyHash, err := params.newHash()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := keyHash.SetNonce(keyNonce); err != nil {
+		return nil, nil, err
+	}
+	keyNonceState := keyHash.GetNonceState()
+
+	for i := range dataNonceState {
+		dataNonceState[i].Add(keyNonceState[i])
+	}
+
+	stateHash, err := params.newHash()
+	if err != nil {
+		return nil, nil, err
+	}
+	stateHash.SetNonceState(dataNonceState)
+
+	tag := &Tag{Length: length, KeyNonce: keyNonce, SampleCount: sampleCount, Tokens: tokens}
+	state := &State{NonceState: stateHash.GetNonceHash()}
+
+	return tag, state, nil
+}
+
+// Prove is run by the server, and needs no key
+func Prove(params Params, ciphertext []byte, state *State, challenge *Challenge) ([]byte, error) {
+	if len(state.NonceState) != params.StateSize() {
+		return nil, errorutils.New("nonce state has the wrong size")
+	}
+
+	crypt, err := params.crypt()
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := crypt.FromBytes(ciphertext)
+	if err := challenge.check(len(blocks)); err != nil {
+		return nil, err
+	}
+
+	hash, err := params.newChallengeHash(challenge.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	hash.SetNonceHash(state.NonceState)
+	for _, i := range challenge.Indices {
+		if err := hash.AddBlockWithIndex(blocks[i], uint(i)); err != nil {
+			return nil, err
+		}
+	}
+
+	return hash.GetDigest(), nil
+}
+
+// Respond is run by the server: it refuses expired challenges, and answers the
+// challenge with its proof and transcript hash
+func Respond(params Params, ciphertext []byte, state *State, challenge *freshness.Challenge, now time.Time) (*freshness.Response, error) {
+	if challenge.Expired(now) {
+		return nil, freshness.ErrExpired
+	}
+
+	c, err := ChallengeOf(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := Prove(params, ciphertext, state, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &freshness.Response{ChallengeHash: challenge.Hash(), Proof: proof}, nil
+}
+
+// VerifyResponse verifies a response to a challenge of IssueChallenge or
+// NewFreshChallenge. It does not check that the challenge is unexpired and
+// answered once, which freshness.Verifier.Accept does
+func VerifyResponse(params Params, key []byte, tag *Tag, challenge *freshness.Challenge, response *freshness.Response) bool {
+	if subtle.ConstantTimeCompare(response.ChallengeHash, challenge.Hash()) != 1 {
+		return false
+	}
+
+	c, err := ChallengeOf(challenge)
+	if err != nil {
+		return false
+	}
+
+	return Verify(params, key, tag, c, response.Proof)
+}
+
+// Verify checks a proof against the token of the challenge it answers, and
+// that the challenge samples the blocks derived from its nonce
+func Verify(params Params, key []byte, tag *Tag, challenge *Challenge, proof []byte) bool {
+	if len(proof) != params.StateSize() {
+		return false
+	}
+
+	token := tag.token(challenge.Nonce)
+	if token == nil {
+		return false
+	}
+
+	crypt, err := params.crypt()
+	if err != nil {
+		return false
+	}
+
+	blockCount := crypt.BlockCount(tag.Length)
+	if !slices.Equal(challenge.Indices, SampleIndices(token.Nonce, uint64(blockCount), tag.SampleCount)) {
+		return false
+	}
+
+	hash, err := params.newChallengeHash(challenge.Nonce)
+	if err != nil {
+		return false
+	}
+
+	keyHash, err := params.newHash()
+	if err != nil {
+		return false
+	}
+
+	if err := keyHash.SetNonce(tag.KeyNonce); err != nil {
+		return false
+	}
+
+	keyBlocks := crypt.ExpandKey(key, blockCount)
+
+	hash.SetNonceHash(proof)
+	for _, i := range challenge.Indices {
+		if err := hash.RemoveBlockWithIndex(keyBlocks[i], uint(i)); err != nil {
+			return false
+		}
+	}
+	hash.RemoveNonceState(keyHash.GetNonceState())
+
+	return subtle.ConstantTimeCompare(expectedDigest(hash.GetDigest()), token.Expected) == 1
+}
@@ -0,0 +1,161 @@
+package pdpr_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+)
+
+var params = pdpr.Params{ModulusBits: 64, ChunkCount: 16}
+
+func Test__Pdpr__HonestProof__ShouldVerify(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("This is a key")
+	data := []byte("Hello, World!")
+
+	ct, err := pdpr.Encrypt(params, key, data)
+	ez.AssertNoError(err)
+	tag, state, err := pdpr.NewTag(params, key, ct, len(data))
+	ez.AssertNoError(err)
+
+	challenge, err := tag.NextChallenge(params)
+	ez.AssertNoError(err)
+	proof, err := pdpr.Prove(params, ct, state, challenge)
+	ez.AssertNoError(err)
+	ez.Assert(pdpr.Verify(params, key, tag, challenge, proof))
+
+	decrypted, err := pdpr.Decrypt(params, key, ct, len(data))
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(decrypted, data)
+}
+
+func Test__Pdpr__Proof__ShouldNotVerify__WhenCiphertextIsCorrupted(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("This is a key")
+	data := []byte("Hello, World!")
+
+	ct, _ := pdpr.Encrypt(params, key, data)
+	tag, state, _ := pdpr.NewTag(params, key, ct, len(data))
+	ct[3] ^= 1
+
+	challenge, _ := tag.NextChallenge(params)
+	proof, err := pdpr.Prove(params, ct, state, challenge)
+	ez.AssertNoError(err)
+	ez.AssertFalse(pdpr.Verify(params, key, tag, challenge, proof))
+	ez.AssertFalse(pdpr.Verify(params, []byte("This is another key"), tag, challenge, proof))
+}
+
+func Test__Pdpr__Proof__ShouldNotVerify__ForAnotherChallenge(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("This is a key")
+	data := []byte("Hello, World!")
+
+	ct, _ := pdpr.Encrypt(params, key, data)
+	tag, state, err := pdpr.NewTagWithTokens(params, key, ct, len(data), 2, 8)
+	ez.AssertNoError(err)
+
+	first, _ := tag.NextChallenge(params)
+	second, _ := tag.NextChallenge(params)
+	ez.AssertAreEqual(len(first.Indices), 8)
+	ez.AssertFalse(slices.Equal(first.Indices, second.Indices))
+
+	proof, err := pdpr.Prove(params, ct, state, first)
+	ez.AssertNoError(err)
+	ez.Assert(pdpr.Verify(params, key, tag, first, proof))
+	// a proof computed for, or replayed from, an earlier challenge
+	ez.AssertFalse(pdpr.Verify(params, key, tag, second, proof))
+
+	// the server cannot choose which blocks to prove
+	moved := &pdpr.Challenge{Nonce: first.Nonce, Indices: append([]uint64{}, first.Indices...)}
+	moved.Indices[0] = (moved.Indices[0] + 1) % 104
+	proof, err = pdpr.Prove(params, ct, state, moved)
+	ez.AssertNoError(err)
+	ez.AssertFalse(pdpr.Verify(params, key, tag, moved, proof))
+
+	_, err = tag.NextChallenge(params)
+	ez.AssertErrorIs(err, pdpr.ErrNoTokens)
+	ez.AssertAreEqual(tag.Remaining(), 0)
+}
+
+func Test__Pdpr__FreshChallenges__ShouldVerifyOnce__AndRejectReplayedResponses(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("This is a key")
+	data := []byte("Hello, World!")
+	now := time.Now()
+
+	verifier := freshness.NewVerifier(time.Minute)
+	verifier.Clock = func() time.Time { return now }
+
+	ct, _ := pdpr.Encrypt(params, key, data)
+	tag, state, err := pdpr.NewTagWithTokens(params, key, ct, len(data), 3, 8)
+	ez.AssertNoError(err)
+
+	challenge, err := tag.IssueChallenge(params, verifier, "hello.txt")
+	ez.AssertNoError(err)
+	response, err := pdpr.Respond(params, ct, state, challenge, now)
+	ez.AssertNoError(err)
+
+	accepted, _, err := verifier.Accept(response)
+	ez.AssertNoError(err)
+	ez.Assert(pdpr.VerifyResponse(params, key, tag, accepted, response))
+	_, _, err = verifier.Accept(response)
+	ez.AssertErrorIs(err, freshness.ErrUnknownChallenge)
+
+	// the server replays the old proof for a new challenge
+	next, _ := tag.IssueChallenge(params, verifier, "hello.txt")
+	replayed := &freshness.Response{ChallengeHash: next.Hash(), Proof: response.Proof}
+	accepted, _, err = verifier.Accept(replayed)
+	ez.AssertNoError(err)
+	ez.AssertFalse(pdpr.VerifyResponse(params, key, tag, accepted, replayed))
+
+	expired, _ := tag.NewFreshChallenge(params, "hello.txt", now, time.Minute)
+	_, err = pdpr.Respond(params, ct, state, expired, now.Add(time.Hour))
+	ez.AssertErrorIs(err, freshness.ErrExpired)
+}
+
+func Test__Pdpr__Prove__ShouldReject__ChallengesOutOfRange(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("This is a key")
+	data := []byte("Hello, World!")
+
+	ct, _ := pdpr.Encrypt(params, key, data)
+	_, state, _ := pdpr.NewTagWithTokens(params, key, ct, len(data), 1, 8)
+
+	_, err := pdpr.Prove(params, ct, state, &pdpr.Challenge{Nonce: []byte("nonce"), Indices: []uint64{104}})
+	ez.AssertErrorIs(err, pdpr.ErrMalformedChallenge)
+
+	_, err = pdpr.Prove(params, ct, state, &pdpr.Challenge{Indices: []uint64{0}})
+	ez.AssertErrorIs(err, pdpr.ErrMalformedChallenge)
+}
+
+func Test__Pdpr__CompactEncoding__ShouldVerify__WithSmallerCiphertext(t *testing.T) {
+	ez := ez.New(t)
+	key := []byte("This is a key")
+	data := []byte("Hello, World!")
+	compact := pdpr.Params{ModulusBits: 64, ChunkCount: 16, BitsPerBlock: 16}
+
+	ct, err := pdpr.Encrypt(compact, key, data)
+	ez.AssertNoError(err)
+	bitCt, _ := pdpr.Encrypt(params, key, data)
+	// 7 blocks of 16 bits instead of 104 blocks of 1 bit, of 8 bytes each
+	ez.AssertAreEqual(len(ct), 7*8)
+	ez.AssertAreEqual(len(bitCt), 104*8)
+
+	tag, state, err := pdpr.NewTag(compact, key, ct, len(data))
+	ez.AssertNoError(err)
+	challenge, err := tag.NextChallenge(compact)
+	ez.AssertNoError(err)
+	proof, err := pdpr.Prove(compact, ct, state, challenge)
+	ez.AssertNoError(err)
+	ez.Assert(pdpr.Verify(compact, key, tag, challenge, proof))
+
+	decrypted, err := pdpr.Decrypt(compact, key, ct, len(data))
+	ez.AssertNoError(err)
+	ez.AssertBytesEqual(decrypted, data)
+
+	ez.AssertFalse(pdpr.Params{ModulusBits: 64, ChunkCount: 16, BitsPerBlock: 3}.Check() == nil)
+}
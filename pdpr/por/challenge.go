@@ -0,0 +1,99 @@
+package por
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/titosilva/pdpr-go/crypto/random"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+)
+
+// Challenge asks the server for the combination of the blocks at Indices,
+// each multiplied by the coefficient with the same position
+type Challenge struct {
+	Indices      []uint64
+	Coefficients []*uintp.UintP
+}
+
+// NewChallenge samples sampleCount distinct block indices out of blockCount,
+// with uniformly random coefficients
+func NewChallenge(modulusBitsize uint64, blockCount uint64, sampleCount int) (*Challenge, error) {
+	if sampleCount <= 0 || uint64(sampleCount) > blockCount {
+		return nil, errorutils.Newf("cannot sample %d blocks out of %d", sampleCount, blockCount)
+	}
+
+	chosen := make(map[uint64]bool, sampleCount)
+	for len(chosen) < sampleCount {
+		idx, err := randomIndex(blockCount)
+		if err != nil {
+			return nil, errorutils.NewWithInner(err, "could not sample a block index")
+		}
+
+		chosen[idx] = true
+	}
+
+	r := new(Challenge)
+	for idx := range chosen {
+		r.Indices = append(r.Indices, idx)
+	}
+	sort.Slice(r.Indices, func(i, j int) bool { return r.Indices[i] < r.Indices[j] })
+
+	for range r.Indices {
+		c, err := random.GenerateUintp(modulusBitsize)
+		if err != nil {
+			return nil, errorutils.NewWithInner(err, "could not sample a coefficient")
+		}
+
+		r.Coefficients = append(r.Coefficients, c)
+	}
+
+	return r, nil
+}
+
+func randomIndex(blockCount uint64) (uint64, error) {
+	// rejection sampling avoids the modulo bias
+	limit := ^uint64(0) - ^uint64(0)%blockCount
+
+	for {
+		bs, err := random.GenerateBytes(8)
+		if err != nil {
+			return 0, err
+		}
+
+		v := binary.BigEndian.Uint64(bs)
+		if v < limit {
+			return v % blockCount, nil
+		}
+	}
+}
+
+// DetectionProbability is the probability that a challenge over sampleCount
+// blocks hits at least one of corruptedCount corrupted blocks out of blockCount
+func DetectionProbability(blockCount uint64, corruptedCount uint64, sampleCount int) float64 {
+	if corruptedCount == 0 {
+		return 0
+	}
+
+	// there are no more corrupted blocks than blocks, and any sample hits one of them
+	corruptedCount = min(corruptedCount, blockCount)
+
+	missAll := 1.0
+	for j := uint64(0); j < uint64(sampleCount) && j < blockCount; j++ {
+		missAll *= float64(blockCount-corruptedCount-min(j, blockCount-corruptedCount)) / float64(blockCount-j)
+	}
+
+	return 1 - missAll
+}
+
+// SamplesFor returns the smallest sample count that detects the corruption of
+// corruptedCount out of blockCount blocks with at least the given probability
+func SamplesFor(blockCount uint64, corruptedCount uint64, probability float64) int {
+	for samples := 1; uint64(samples) <= blockCount; samples++ {
+		if DetectionProbability(blockCount, corruptedCount, samples) >= probability {
+			return samples
+		}
+	}
+
+	return int(blockCount)
+}
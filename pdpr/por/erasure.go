@@ -0,0 +1,165 @@
+package por
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/titosilva/pdpr-go/coding/reedsolomon"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+	"golang.org/x/crypto/hkdf"
+)
+
+// ShardedFile is a file erasure-coded into shards, each of them encrypted
+// with GCrypt and tagged. Blocks are numbered across shards, so that a tag
+// is only valid for its own position in its own shard.
+// A nil shard is a lost one
+type ShardedFile struct {
+	Size   int
+	Shards [][]byte
+	Tags   [][]Tag
+}
+
+var ErrUnrecoverable = errors.New("not enough intact shards to recover the file")
+
+// EncodeFile splits data into shards with codec, then encrypts and tags every shard
+func (t *Tagger) EncodeFile(codec *reedsolomon.Encoder, data []byte, key []byte) (*ShardedFile, error) {
+	shards := codec.Split(data)
+	if err := codec.Encode(shards); err != nil {
+		return nil, errorutils.NewWithInner(err, "could not erasure-code the file")
+	}
+
+	r := new(ShardedFile)
+	r.Size = len(data)
+	r.Shards = make([][]byte, len(shards))
+	r.Tags = make([][]Tag, len(shards))
+
+	for i := range shards {
+		ct, err := t.crypt.Encrypt(shards[i], shardKey(key, i))
+		if err != nil {
+			return nil, err
+		}
+
+		tags, err := t.tagFrom(t.Blocks(ct), uint64(i)*t.blockCount(ct))
+		if err != nil {
+			return nil, err
+		}
+
+		r.Shards[i] = ct
+		r.Tags[i] = tags
+	}
+
+	return r, nil
+}
+
+// Flatten returns the blocks and tags of every shard, in the order expected by Prove.
+// Lost shards are replaced by zeroed blocks, which will fail verification
+func (f *ShardedFile) Flatten(t *Tagger) ([]*uintp.UintP, []Tag) {
+	perShard := f.blocksPerShard(t)
+	blocks := make([]*uintp.UintP, 0, perShard*uint64(len(f.Shards)))
+	tags := make([]Tag, 0, cap(blocks))
+
+	for i := range f.Shards {
+		shardBlocks := t.Blocks(f.Shards[i])
+		for j := uint64(0); j < perShard; j++ {
+			if j < uint64(len(shardBlocks)) && j < uint64(len(f.Tags[i])) {
+				blocks = append(blocks, shardBlocks[j])
+				tags = append(tags, f.Tags[i][j])
+			} else {
+				blocks = append(blocks, uintp.MustNew(uint64(t.params.ModulusBitsize)))
+				tags = append(tags, zeroState(t.params))
+			}
+		}
+	}
+
+	return blocks, tags
+}
+
+// BlockCount is the number of blocks that can be challenged in the file
+func (f *ShardedFile) BlockCount(t *Tagger) uint64 {
+	return f.blocksPerShard(t) * uint64(len(f.Shards))
+}
+
+// ShardOf returns the shard that holds a challenged block, to localise corruption,
+// or -1 if no shard holds it, e.g. when every shard is lost
+func (f *ShardedFile) ShardOf(t *Tagger, blockIndex uint64) int {
+	perShard := f.blocksPerShard(t)
+	if perShard == 0 || blockIndex >= f.BlockCount(t) {
+		return -1
+	}
+
+	return int(blockIndex / perShard)
+}
+
+// RecoverFile checks every shard against its tags, discards the corrupted
+// ones and recovers the file from the intact shards
+func (t *Tagger) RecoverFile(codec *reedsolomon.Encoder, file *ShardedFile, key []byte) ([]byte, error) {
+	if len(file.Shards) != codec.TotalShards() || len(file.Tags) != codec.TotalShards() {
+		return nil, reedsolomon.ErrShardCount
+	}
+
+	perShard := file.blocksPerShard(t)
+	shards := make([][]byte, len(file.Shards))
+
+	for i := range file.Shards {
+		if file.Shards[i] == nil || !t.CheckShard(file.Shards[i], file.Tags[i], uint64(i)*perShard) {
+			continue
+		}
+
+		shards[i] = t.crypt.Decrypt(file.Shards[i], shardKey(key, i))
+	}
+
+	if err := codec.Reconstruct(shards); err != nil {
+		return nil, errorutils.NewWithInner(ErrUnrecoverable, err.Error())
+	}
+
+	return codec.Join(shards, file.Size)
+}
+
+// CheckShard checks every block of a single shard, whose first block has
+// index offset, against its tag. It is meant for the owner, when retrieving the file
+func (t *Tagger) CheckShard(ciphertext []byte, tags []Tag, offset uint64) bool {
+	blocks := t.Blocks(ciphertext)
+	if len(blocks) != len(tags) || len(blocks) == 0 {
+		return false
+	}
+
+	for j := range blocks {
+		tag, err := t.TagBlock(blocks[j], offset+uint64(j))
+		if err != nil || !statesEqual(tag, tags[j]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// blockCount is the number of GCrypt blocks in a ciphertext
+func (t *Tagger) blockCount(ciphertext []byte) uint64 {
+	return uint64(len(ciphertext)) * 8 / uint64(t.params.ModulusBitsize)
+}
+
+// blocksPerShard is the number of blocks of each shard, which are all equally sized
+func (f *ShardedFile) blocksPerShard(t *Tagger) uint64 {
+	for i := range f.Shards {
+		if f.Shards[i] != nil {
+			return t.blockCount(f.Shards[i])
+		}
+	}
+
+	return 0
+}
+
+func shardKey(key []byte, index int) []byte {
+	info := make([]byte, 8)
+	binary.BigEndian.PutUint64(info, uint64(index))
+
+	r := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, []byte("pdpr-go/por/shard-key"), info), r); err != nil {
+		panic(err)
+	}
+
+	return r
+}
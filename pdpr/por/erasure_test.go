@@ -0,0 +1,94 @@
+package por_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/coding/reedsolomon"
+	"github.com/titosilva/pdpr-go/crypto/random"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/math/gf"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+// sharded encodes random bytes in 4 data and 2 parity shards, and tags them
+func sharded(ez *ez.EzTest) (*por.Tagger, *reedsolomon.Encoder, *por.ShardedFile, []byte) {
+	data, err := random.GenerateBytes(40)
+	ez.AssertNoError(err)
+	key := []byte("This is a key")
+
+	codec, err := reedsolomon.New(gf.GF8(), 4, 2)
+	ez.AssertNoError(err)
+
+	tagger, err := por.NewTagger(por.DefaultParams, key)
+	ez.AssertNoError(err)
+
+	file, err := tagger.EncodeFile(codec, data, key)
+	ez.AssertNoError(err)
+
+	return tagger, codec, file, data
+}
+
+func Test__ShardedFile__HonestProof__ShouldVerify(t *testing.T) {
+	ez := ez.New(t)
+	tagger, _, file, _ := sharded(ez)
+
+	blocks, tags := file.Flatten(tagger)
+	challenge, err := por.NewChallenge(128, file.BlockCount(tagger), 32)
+	ez.AssertNoError(err)
+
+	proof, err := por.Prove(por.DefaultParams, blocks, tags, challenge)
+	ez.AssertNoError(err)
+	ez.Assert(tagger.Verify(challenge, proof))
+}
+
+func Test__ShardedFile__Recover__ShouldReturnOriginalValue__WhenShardsAreCorrupted(t *testing.T) {
+	ez := ez.New(t)
+	tagger, codec, file, data := sharded(ez)
+
+	file.Shards[1][5] ^= 0xff
+	file.Shards[4] = nil
+
+	blocks, tags := file.Flatten(tagger)
+	challenge, _ := por.NewChallenge(128, file.BlockCount(tagger), int(file.BlockCount(tagger)))
+	proof, _ := por.Prove(por.DefaultParams, blocks, tags, challenge)
+	ez.AssertFalse(tagger.Verify(challenge, proof))
+
+	recovered, err := tagger.RecoverFile(codec, file, []byte("This is a key"))
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(recovered, data)
+}
+
+func Test__ShardedFile__Recover__ShouldFail__WhenTooManyShardsAreCorrupted(t *testing.T) {
+	ez := ez.New(t)
+	tagger, codec, file, _ := sharded(ez)
+
+	file.Shards[0][0] ^= 1
+	file.Shards[2][0] ^= 1
+	file.Shards[5] = nil
+
+	_, err := tagger.RecoverFile(codec, file, []byte("This is a key"))
+	ez.Assert(errors.Is(err, por.ErrUnrecoverable))
+}
+
+func Test__ShardedFile__CheckShard__ShouldLocalise__CorruptedShard(t *testing.T) {
+	ez := ez.New(t)
+	tagger, _, file, _ := sharded(ez)
+	perShard := uint64(len(file.Tags[0]))
+
+	file.Shards[3][0] ^= 1
+
+	for s := range file.Shards {
+		ok := tagger.CheckShard(file.Shards[s], file.Tags[s], uint64(s)*perShard)
+		ez.AssertAreEqual(ok, s != 3)
+	}
+
+	ez.AssertAreEqual(file.ShardOf(tagger, 3*perShard), 3)
+	ez.AssertAreEqual(file.ShardOf(tagger, 4*perShard-1), 3)
+	ez.AssertAreEqual(file.ShardOf(tagger, uint64(len(file.Shards))*perShard), -1)
+
+	for s := range file.Shards {
+		file.Shards[s] = nil
+	}
+	ez.AssertAreEqual(file.ShardOf(tagger, 0), -1)
+}
@@ -0,0 +1,223 @@
+package por
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/crypto/hash/ghash"
+	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/uintp"
+)
+
+// Proofs of retrievability with sampled challenges.
+// Each block m_i gets a tag t_i = f(i) + m_i * a, computed with a keyed GHash:
+// f(i) is the nonce state of the index i, and a is the GHash of index 0.
+// For a challenge {(i, c_i)} the server answers mu = sum(c_i * m_i) and
+// sigma = sum(c_i * t_i), and the verifier checks that
+// sigma = sum(c_i * f(i)) + mu * a, so that the cost of proving and verifying
+// depends on the size of the challenge only, and not on the size of the file.
+// The verifier computes mu * a with GHash.AddBlockWithIndex; the server cannot,
+// as a is keyed, so it sums with plain arithmetic modulo 2^ModulusBitsize,
+// which is the arithmetic of GHash states.
+
+// Tag authenticates a single block. It is stored by the server along with the data
+type Tag []*uintp.UintP
+
+// Proof is the answer of the server to a Challenge
+type Proof struct {
+	Mu    *uintp.UintP
+	Sigma []*uintp.UintP
+}
+
+type Params struct {
+	ModulusBitsize uint
+	ChunkCount     uint
+}
+
+// DefaultParams are the parameters of the lthash.Level128 preset
+var DefaultParams = Params{ModulusBitsize: lthash.Level128.ChunkSizeBits, ChunkCount: lthash.Level128.ChunkCount}
+
+// HashParams returns the parameters of the underlying LtHash
+func (p Params) HashParams() lthash.Params {
+	return lthash.Params{ChunkCount: p.ChunkCount, ChunkSizeBits: p.ModulusBitsize, BlockSizeBytes: int(p.ModulusBitsize / 8)}
+}
+
+// Validate rejects inconsistent parameters, and parameters below 128 bits of
+// estimated security. NewTagger accepts them, for benchmarks
+func (p Params) Validate() error {
+	return p.HashParams().Validate()
+}
+
+var ErrIndexOutOfRange = errors.New("challenged block index is out of range")
+var ErrMalformedChallenge = errors.New("challenge has different numbers of indices and coefficients")
+
+// Tagger tags blocks and verifies proofs. It holds the owner's secret key
+type Tagger struct {
+	params Params
+	hash   *ghash.GHash
+	crypt  *gcrypt.GCrypt
+}
+
+func NewTagger(params Params, key []byte) (*Tagger, error) {
+	hash, err := ghash.NewWithParams(params.ChunkCount, params.ModulusBitsize, int(params.ModulusBitsize/8), key)
+	if err != nil {
+		return nil, err
+	}
+
+	crypt, err := gcrypt.New(uint64(params.ModulusBitsize))
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Tagger)
+	r.params = params
+	r.hash = hash
+	r.crypt = crypt
+
+	return r, nil
+}
+
+// Blocks splits a GCrypt ciphertext into the blocks that are tagged and challenged
+func (t *Tagger) Blocks(ciphertext []byte) []*uintp.UintP {
+	return t.crypt.FromBytes(ciphertext)
+}
+
+func (t *Tagger) Tag(blocks []*uintp.UintP) ([]Tag, error) {
+	return t.tagFrom(blocks, 0)
+}
+
+// tagFrom tags blocks, the first of which has index offset
+func (t *Tagger) tagFrom(blocks []*uintp.UintP, offset uint64) ([]Tag, error) {
+	r := make([]Tag, len(blocks))
+
+	for i := range blocks {
+		tag, err := t.TagBlock(blocks[i], offset+uint64(i))
+		if err != nil {
+			return nil, err
+		}
+
+		r[i] = tag
+	}
+
+	return r, nil
+}
+
+func (t *Tagger) TagBlock(block *uintp.UintP, index uint64) (Tag, error) {
+	if err := t.hash.SetNonce(indexNonce(index)); err != nil {
+		return nil, err
+	}
+
+	if err := t.hash.AddBlockWithIndex(block, 0); err != nil {
+		return nil, err
+	}
+
+	return t.hash.GetState(), nil
+}
+
+func (t *Tagger) TagCiphertext(ciphertext []byte) ([]Tag, error) {
+	return t.Tag(t.Blocks(ciphertext))
+}
+
+// Verify returns false for malformed proofs, e.g. with missing values, as
+// proofs come from the server
+func (t *Tagger) Verify(challenge *Challenge, proof *Proof) bool {
+	if challenge == nil || len(challenge.Indices) != len(challenge.Coefficients) || proof == nil || proof.Mu == nil {
+		return false
+	}
+
+	if len(proof.Sigma) != int(t.params.ChunkCount) || slices.Contains(proof.Sigma, nil) || proof.Mu.ModulusBitsize != uint64(t.params.ModulusBitsize) {
+		return false
+	}
+
+	expected := zeroState(t.params)
+	for i, idx := range challenge.Indices {
+		if err := t.hash.SetNonce(indexNonce(idx)); err != nil {
+			return false
+		}
+
+		addScaled(expected, t.hash.GetNonceState(), challenge.Coefficients[i])
+	}
+
+	t.hash.SetNonceState(expected)
+	if err := t.hash.AddBlockWithIndex(proof.Mu, 0); err != nil {
+		return false
+	}
+
+	return statesEqual(t.hash.GetState(), proof.Sigma)
+}
+
+// Prove computes the answer to a challenge. It is run by the server and needs no key.
+// Tags are GHash states, whose chunks are added modulo 2^ModulusBitsize, so
+// sigma is summed chunk by chunk with the same arithmetic, and mu, which Verify
+// adds with GHash.AddBlockWithIndex, is a block of the same modulus
+func Prove(params Params, blocks []*uintp.UintP, tags []Tag, challenge *Challenge) (*Proof, error) {
+	if len(challenge.Indices) != len(challenge.Coefficients) {
+		return nil, ErrMalformedChallenge
+	}
+
+	mu, err := uintp.New(uint64(params.ModulusBitsize))
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Proof)
+	r.Mu = mu
+	r.Sigma = zeroState(params)
+
+	for i, idx := range challenge.Indices {
+		if idx >= uint64(len(blocks)) || idx >= uint64(len(tags)) {
+			return nil, errorutils.NewfWithInner(ErrIndexOutOfRange, "block %d was challenged, but there are %d blocks", idx, len(blocks))
+		}
+
+		if len(tags[idx]) != len(r.Sigma) {
+			return nil, errorutils.Newf("tag of block %d is malformed", idx)
+		}
+
+		c := challenge.Coefficients[i]
+		r.Mu.Add(uintp.Clone(blocks[idx]).Mul(c))
+		addScaled(r.Sigma, tags[idx], c)
+	}
+
+	return r, nil
+}
+
+func indexNonce(index uint64) []byte {
+	r := make([]byte, 8)
+	binary.BigEndian.PutUint64(r, index)
+
+	return r
+}
+
+// zeroState is only called once the modulus of params was checked
+func zeroState(params Params) []*uintp.UintP {
+	r := make([]*uintp.UintP, params.ChunkCount)
+
+	for i := range r {
+		r[i] = uintp.MustNew(uint64(params.ModulusBitsize))
+	}
+
+	return r
+}
+
+func addScaled(acc []*uintp.UintP, state []*uintp.UintP, scalar *uintp.UintP) {
+	for i := range acc {
+		acc[i].Add(uintp.Clone(state[i]).Mul(scalar))
+	}
+}
+
+func statesEqual(s1 []*uintp.UintP, s2 []*uintp.UintP) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+
+	for i := range s1 {
+		if s1[i].ModulusBitsize != s2[i].ModulusBitsize || !s1[i].Equals(s2[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,56 @@
+package por_test
+
+import (
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/crypto/random"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+func runSampledPor(b *testing.B, size int, samples int) {
+	data, _ := random.GenerateBytes(size)
+	key, _ := random.GenerateBytes(32)
+	crypt := gcrypt.MustNew(uint64(por.DefaultParams.ModulusBitsize))
+	tagger, err := por.NewTagger(por.DefaultParams, key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	blocks := tagger.Blocks(crypt.MustEncrypt(data, key))
+	tags, err := tagger.Tag(blocks)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		challenge, err := por.NewChallenge(uint64(por.DefaultParams.ModulusBitsize), uint64(len(blocks)), samples)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		proof, err := por.Prove(por.DefaultParams, blocks, tags, challenge)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if !tagger.Verify(challenge, proof) {
+			panic("proof not verified")
+		}
+	}
+
+	b.ReportMetric(float64(b.Elapsed().Milliseconds())/float64(b.N), "ms/pdpr")
+}
+
+func Benchmark__SampledPor__128bit__128m__64s(b *testing.B) {
+	runSampledPor(b, 16, 64)
+}
+
+func Benchmark__SampledPor__1KiB__128m__64s(b *testing.B) {
+	runSampledPor(b, 1024, 64)
+}
+
+func Benchmark__SampledPor__8KiB__128m__64s(b *testing.B) {
+	runSampledPor(b, 8*1024, 64)
+}
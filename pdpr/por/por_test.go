@@ -0,0 +1,110 @@
+package por_test
+
+import (
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
+	"github.com/titosilva/pdpr-go/crypto/random"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/math/uintp"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+// tagged tags size random bytes, encrypted under a fixed key
+func tagged(ez *ez.EzTest, size int) (*por.Tagger, []*uintp.UintP, []por.Tag) {
+	data, err := random.GenerateBytes(size)
+	ez.AssertNoError(err)
+
+	key := []byte("This is a key")
+	encrypted := gcrypt.MustNew(uint64(por.DefaultParams.ModulusBitsize)).MustEncrypt(data, key)
+
+	tagger, err := por.NewTagger(por.DefaultParams, key)
+	ez.AssertNoError(err)
+
+	blocks := tagger.Blocks(encrypted)
+	tags, err := tagger.Tag(blocks)
+	ez.AssertNoError(err)
+
+	return tagger, blocks, tags
+}
+
+func Test__Por__HonestProof__ShouldVerify(t *testing.T) {
+	ez := ez.New(t)
+	tagger, blocks, tags := tagged(ez, 8)
+
+	challenge, err := por.NewChallenge(128, uint64(len(blocks)), 10)
+	ez.AssertNoError(err)
+
+	proof, err := por.Prove(por.DefaultParams, blocks, tags, challenge)
+	ez.AssertNoError(err)
+	ez.Assert(tagger.Verify(challenge, proof))
+}
+
+func Test__Por__CorruptedBlock__ShouldNotVerify__WhenChallenged(t *testing.T) {
+	ez := ez.New(t)
+	tagger, blocks, tags := tagged(ez, 8)
+
+	blocks[3] = uintp.Clone(blocks[3]).AddUint(1)
+
+	challenge, err := por.NewChallenge(128, uint64(len(blocks)), len(blocks))
+	ez.AssertNoError(err)
+
+	proof, err := por.Prove(por.DefaultParams, blocks, tags, challenge)
+	ez.AssertNoError(err)
+	ez.AssertFalse(tagger.Verify(challenge, proof))
+}
+
+func Test__Por__ProofForOtherChallenge__ShouldNotVerify(t *testing.T) {
+	ez := ez.New(t)
+	tagger, blocks, tags := tagged(ez, 8)
+
+	challenge, _ := por.NewChallenge(128, uint64(len(blocks)), 5)
+	other, _ := por.NewChallenge(128, uint64(len(blocks)), 5)
+
+	proof, err := por.Prove(por.DefaultParams, blocks, tags, other)
+	ez.AssertNoError(err)
+	ez.AssertFalse(tagger.Verify(challenge, proof))
+}
+
+func Test__Por__MalformedProof__ShouldNotVerify(t *testing.T) {
+	ez := ez.New(t)
+	tagger, blocks, tags := tagged(ez, 8)
+
+	challenge, _ := por.NewChallenge(128, uint64(len(blocks)), 5)
+	proof, err := por.Prove(por.DefaultParams, blocks, tags, challenge)
+	ez.AssertNoError(err)
+
+	ez.AssertFalse(tagger.Verify(challenge, &por.Proof{Sigma: proof.Sigma}))
+	ez.AssertFalse(tagger.Verify(challenge, &por.Proof{Mu: proof.Mu, Sigma: make([]*uintp.UintP, len(proof.Sigma))}))
+	ez.AssertFalse(tagger.Verify(nil, proof))
+	ez.Assert(tagger.Verify(challenge, proof))
+}
+
+func Test__Por__Prove__ShouldFail__WhenIndexIsOutOfRange(t *testing.T) {
+	ez := ez.New(t)
+	_, blocks, tags := tagged(ez, 1)
+
+	challenge, _ := por.NewChallenge(128, uint64(len(blocks))+1, len(blocks)+1)
+
+	_, err := por.Prove(por.DefaultParams, blocks, tags, challenge)
+	ez.Assert(err != nil)
+}
+
+func Test__DetectionProbability__ShouldMatch__KnownValues(t *testing.T) {
+	ez := ez.New(t)
+
+	ez.AssertAreEqual(por.DetectionProbability(100, 0, 10), 0.0)
+	ez.AssertAreEqual(por.DetectionProbability(100, 1, 100), 1.0)
+	ez.Assert(por.DetectionProbability(10000, 100, 460) > 0.99)
+	ez.AssertAreEqual(por.SamplesFor(100, 100, 0.99), 1)
+	ez.AssertAreEqual(por.DetectionProbability(100, 1000, 1), 1.0)
+	ez.AssertAreEqual(por.DetectionProbability(100, 100, 10), 1.0)
+}
+
+func Test__Por__DefaultParams__ShouldBeValid(t *testing.T) {
+	ez := ez.New(t)
+
+	ez.AssertNoError(por.DefaultParams.Validate())
+	ez.AssertErrorIs(por.Params{ModulusBitsize: 128, ChunkCount: 4}.Validate(), lthash.ErrInsecureParams)
+}
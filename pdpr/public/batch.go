@@ -0,0 +1,160 @@
+package public
+
+import (
+	"bytes"
+	"sort"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/dl"
+	"github.com/titosilva/pdpr-go/math/nmod"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+// Batch audits challenge many files, possibly of different owners, in one round.
+// The generators are the same for every file, so the server sums the mu of all
+// files into a single one, and the auditor checks it against the product of
+// the combined tags of every file. When a batch fails, Audit bisects it to find
+// the files that fail on their own.
+
+type FileChallenge struct {
+	ID        string         `json:"id"`
+	Challenge *por.Challenge `json:"challenge"`
+}
+
+type BatchChallenge struct {
+	Files []FileChallenge `json:"files"`
+}
+
+type FileProof struct {
+	Tags  [][]byte   `json:"tags"`
+	Paths [][][]byte `json:"paths"`
+}
+
+// BatchProof has one FileProof for each file of the challenge, in the same order
+type BatchProof struct {
+	Mu    [][]byte    `json:"mu"`
+	Files []FileProof `json:"files"`
+}
+
+// Source gives the server the ciphertext and tags of a file
+type Source func(id string) ([]byte, *Tags, error)
+
+// Prover sends a batch challenge to the server, and returns its answer
+type Prover func(challenge *BatchChallenge) (*BatchProof, error)
+
+// NewBatchChallenge challenges sampleCount blocks of each file, or all of its
+// blocks if it has fewer
+func NewBatchChallenge(infos map[string]*PublicInfo, sampleCount int) (*BatchChallenge, error) {
+	ids := make([]string, 0, len(infos))
+	for id := range infos {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	r := new(BatchChallenge)
+	for _, id := range ids {
+		samples := int(min(uint64(sampleCount), infos[id].BlockCount))
+
+		c, err := NewChallenge(infos[id], samples)
+		if err != nil {
+			return nil, errorutils.NewfWithInner(err, "could not challenge file %s", id)
+		}
+
+		r.Files = append(r.Files, FileChallenge{ID: id, Challenge: c})
+	}
+
+	return r, nil
+}
+
+func ProveBatch(source Source, challenge *BatchChallenge) (*BatchProof, error) {
+	group := dl.NewOakley2Group()
+	var mu []*nmod.NatMod
+	r := new(BatchProof)
+
+	for _, file := range challenge.Files {
+		ciphertext, tags, err := source(file.ID)
+		if err != nil {
+			return nil, errorutils.NewfWithInner(err, "could not read file %s", file.ID)
+		}
+
+		fileMu, proof, err := aggregate(ciphertext, tags, file.Challenge)
+		if err != nil {
+			return nil, errorutils.NewfWithInner(err, "could not prove file %s", file.ID)
+		}
+
+		for len(mu) < len(fileMu) {
+			mu = append(mu, nmod.NewFromUint(0, group.Order))
+		}
+
+		for j := range fileMu {
+			mu[j] = mu[j].MustAdd(fileMu[j])
+		}
+
+		r.Files = append(r.Files, FileProof{Tags: proof.Tags, Paths: proof.Paths})
+	}
+
+	for _, m := range mu {
+		r.Mu = append(r.Mu, m.Bytes())
+	}
+
+	return r, nil
+}
+
+// VerifyBatch checks a batch proof. It fails if any file fails, or is unknown
+func VerifyBatch(infos map[string]*PublicInfo, challenge *BatchChallenge, proof *BatchProof) bool {
+	if proof == nil || len(proof.Files) != len(challenge.Files) || len(challenge.Files) == 0 {
+		return false
+	}
+
+	sectorCount := 0
+	var expected []byte
+	seen := make(map[string]bool)
+
+	for i, file := range challenge.Files {
+		info, ok := infos[file.ID]
+		if !ok || seen[file.ID] {
+			return false
+		}
+		seen[file.ID] = true
+
+		hiders, combined, ok := combineTags(info, file.Challenge, proof.Files[i].Tags, proof.Files[i].Paths)
+		if !ok {
+			return false
+		}
+
+		if expected == nil {
+			expected = combined
+		} else {
+			expected = hiders[0].CombineHidden(expected, combined)
+		}
+
+		sectorCount = max(sectorCount, info.Params.SectorCount)
+	}
+
+	actual, ok := hideMu(hiders(sectorCount), proof.Mu)
+	return ok && bytes.Equal(actual, expected)
+}
+
+// Audit runs a batch audit, and returns the files that failed. A failed batch
+// is split in halves, each of which is audited again, so that k failures out of
+// n files take about 2k log(n) more rounds. Errors of the prover count as failures
+func Audit(infos map[string]*PublicInfo, challenge *BatchChallenge, prover Prover) []string {
+	if len(challenge.Files) == 0 {
+		return nil
+	}
+
+	proof, err := prover(challenge)
+	if err == nil && VerifyBatch(infos, challenge, proof) {
+		return nil
+	}
+
+	if len(challenge.Files) == 1 {
+		return []string{challenge.Files[0].ID}
+	}
+
+	half := len(challenge.Files) / 2
+	left := &BatchChallenge{Files: challenge.Files[:half]}
+	right := &BatchChallenge{Files: challenge.Files[half:]}
+
+	return append(Audit(infos, left, prover), Audit(infos, right, prover)...)
+}
@@ -0,0 +1,74 @@
+package public_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr/public"
+)
+
+type server struct {
+	ciphertexts map[string][]byte
+	tags        map[string]*public.Tags
+	rounds      int
+}
+
+func (s *server) source(id string) ([]byte, *public.Tags, error) {
+	return s.ciphertexts[id], s.tags[id], nil
+}
+
+func (s *server) prove(challenge *public.BatchChallenge) (*public.BatchProof, error) {
+	s.rounds++
+	return public.ProveBatch(s.source, challenge)
+}
+
+// upload uploads files of different owners, with different keys and parameters
+func upload(ez *ez.EzTest, count int) (*server, map[string]*public.PublicInfo) {
+	s := &server{ciphertexts: map[string][]byte{}, tags: map[string]*public.Tags{}}
+	infos := map[string]*public.PublicInfo{}
+
+	for i := 0; i < count; i++ {
+		id := fmt.Sprintf("file-%d", i)
+		ct := gcrypt.MustNew(64).MustEncrypt([]byte(fmt.Sprintf("Contents of file %d", i)), []byte(fmt.Sprintf("Key of owner %d", i)))
+
+		tags, info, err := public.NewTags(public.Params{SectorCount: 2 + i%3}, ct)
+		ez.AssertNoError(err)
+
+		s.ciphertexts[id], s.tags[id], infos[id] = ct, tags, info
+	}
+
+	return s, infos
+}
+
+func Test__Batch__HonestServer__ShouldPassInOneRound(t *testing.T) {
+	ez := ez.New(t)
+	s, infos := upload(ez, 6)
+
+	challenge, err := public.NewBatchChallenge(infos, 2)
+	ez.AssertNoError(err)
+
+	proof, err := public.ProveBatch(s.source, challenge)
+	ez.AssertNoError(err)
+	ez.Assert(public.VerifyBatch(infos, challenge, proof))
+	ez.AssertAreEqual(len(proof.Mu), 4)
+
+	ez.AssertAreEqual(len(public.Audit(infos, challenge, s.prove)), 0)
+	ez.AssertAreEqual(s.rounds, 1)
+}
+
+func Test__Batch__Audit__ShouldIsolate__CorruptedFiles(t *testing.T) {
+	ez := ez.New(t)
+	s, infos := upload(ez, 8)
+
+	s.ciphertexts["file-2"][0] ^= 1
+	s.ciphertexts["file-5"][len(s.ciphertexts["file-5"])-1] ^= 1
+	delete(s.ciphertexts, "file-7")
+
+	// every block is challenged, so that the corruption is always hit
+	challenge, _ := public.NewBatchChallenge(infos, 1000)
+	failed := public.Audit(infos, challenge, s.prove)
+
+	ez.AssertAreEqual(failed, []string{"file-2", "file-5", "file-7"})
+}
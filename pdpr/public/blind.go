@@ -0,0 +1,107 @@
+package public
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/titosilva/pdpr-go/math/dl"
+	"github.com/titosilva/pdpr-go/math/nmod"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+)
+
+// Blinded responses are a Schnorr proof of knowledge of mu. The server draws
+// random masks r_j, commits to them with R = prod(g_j^r_j), and answers
+// mu'_j = r_j + gamma * mu_j, where gamma hashes R with the challenge and the
+// tags. The auditor checks that prod(g_j^mu'_j) = R * prod(T_i^c_i)^gamma.
+// As r_j is uniform, mu'_j is uniform too, whatever the blocks are.
+
+type BlindedProof struct {
+	Commitment []byte     `json:"commitment"`
+	Mu         [][]byte   `json:"mu"`
+	Tags       [][]byte   `json:"tags"`
+	Paths      [][][]byte `json:"paths"`
+}
+
+var blindingDomain = []byte("pdpr-go/pdpr/public/blinding/v1")
+
+func ProveBlinded(ciphertext []byte, tags *Tags, challenge *por.Challenge) (*BlindedProof, error) {
+	mu, proof, err := aggregate(ciphertext, tags, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	group := dl.NewOakley2Group()
+	hiders := tags.Params.hiders()
+
+	masks := make([]*nmod.NatMod, len(mu))
+	commitment := hiders[0].Hide(nil)
+	for j := range masks {
+		masks[j], err = randomExponent(group)
+		if err != nil {
+			return nil, err
+		}
+
+		commitment = hiders[j].CombineHidden(commitment, hiders[j].Hide(masks[j].Bytes()))
+	}
+
+	gamma := blindingChallenge(group, commitment, challenge, proof.Tags)
+
+	r := &BlindedProof{Commitment: commitment, Tags: proof.Tags, Paths: proof.Paths}
+	for j := range mu {
+		r.Mu = append(r.Mu, masks[j].MustAdd(gamma.MustMul(mu[j])).Bytes())
+	}
+
+	return r, nil
+}
+
+func VerifyBlinded(info *PublicInfo, challenge *por.Challenge, proof *BlindedProof) bool {
+	if proof == nil || len(proof.Commitment) != ElementSize {
+		return false
+	}
+
+	hiders, combined, ok := combineTags(info, challenge, proof.Tags, proof.Paths)
+	if !ok {
+		return false
+	}
+
+	actual, ok := hideMu(hiders, proof.Mu)
+	if !ok {
+		return false
+	}
+
+	gamma := blindingChallenge(dl.NewOakley2Group(), proof.Commitment, challenge, proof.Tags)
+	expected := hiders[0].CombineHidden(proof.Commitment, hiders[0].ScaleHidden(combined, gamma.Bytes()))
+
+	return bytes.Equal(actual, expected)
+}
+
+// blindingChallenge is the Fiat-Shamir challenge of the proof of knowledge
+func blindingChallenge(group *dl.DiscreteLogGroup, commitment []byte, challenge *por.Challenge, tags [][]byte) *nmod.NatMod {
+	h := sha256.New()
+	h.Write(blindingDomain)
+	h.Write(commitment)
+
+	for i, idx := range challenge.Indices {
+		h.Write(binary.BigEndian.AppendUint64(nil, idx))
+		h.Write(challenge.Coefficients[i].Bytes())
+		h.Write(tags[i])
+	}
+
+	return nmod.NewFromBigEndianBytes(h.Sum(nil), group.Order)
+}
+
+func randomExponent(group *dl.DiscreteLogGroup) (*nmod.NatMod, error) {
+	// 64 extra bits make the bias of the reduction negligible
+	bs := make([]byte, len(group.OrderBytes)+8)
+	if _, err := rand.Read(bs); err != nil {
+		return nil, err
+	}
+
+	v := new(big.Int).SetBytes(bs)
+	v.Mod(v, new(big.Int).SetBytes(group.OrderBytes))
+
+	return nmod.NewFromBigEndianBytes(v.FillBytes(make([]byte, len(group.OrderBytes))), group.Order), nil
+}
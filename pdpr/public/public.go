@@ -0,0 +1,283 @@
+// Package public implements publicly verifiable PDPr: proofs of possession of a
+// GCrypt ciphertext that any auditor can verify, with no secret key.
+//
+// The ciphertext is split into blocks of SectorCount sectors b_ij, and each block
+// gets the tag T_i = prod(g_j^b_ij), a dlhh hiding over the Oakley group 2 with one
+// generator per sector. The owner publishes a PublicInfo with the Merkle root of
+// the tags, and gives the tags to the server along with the ciphertext. For a
+// challenge {(i, c_i)} the server answers mu_j = sum(c_i * b_ij), along with the
+// challenged tags and their inclusion proofs, and the auditor checks the proofs
+// against the root and that prod(g_j^mu_j) = prod(T_i^c_i).
+//
+// Each mu_j is a linear combination of sectors, so an auditor that collects
+// enough proofs can solve for the ciphertext. ProveBlinded and VerifyBlinded
+// answer with blinded combinations instead, which reveal nothing about the blocks.
+// Many files, of any owners, can be audited in a single round, see ProveBatch.
+package public
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/titosilva/pdpr-go/crypto/hash/merkle"
+	"github.com/titosilva/pdpr-go/crypto/homomorphic_hiding/dlhh"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/math/dl"
+	"github.com/titosilva/pdpr-go/math/nmod"
+	"github.com/titosilva/pdpr-go/pdpr/por"
+	"golang.org/x/crypto/hkdf"
+)
+
+type Params struct {
+	SectorCount int `json:"sector_count"`
+}
+
+// DefaultParams makes the tags about 1/8 of the size of the ciphertext
+var DefaultParams = Params{SectorCount: 8}
+
+// SectorSize is the size in bytes of a sector, which is always smaller than the
+// order of the Oakley group 2. Tags are elements of the group, of ElementSize bytes
+const (
+	SectorSize  = 127
+	ElementSize = 128
+)
+
+// CoefficientBits is the size of the coefficients of challenges
+const CoefficientBits = 128
+
+// maxSectorCount bounds the number of generators derived for a single file
+const maxSectorCount = 1 << 12
+
+// Tags are stored by the server along with the ciphertext
+type Tags struct {
+	Params Params   `json:"params"`
+	Values [][]byte `json:"values"`
+}
+
+// PublicInfo is published by the owner, and is all an auditor needs
+type PublicInfo struct {
+	Params     Params `json:"params"`
+	BlockCount uint64 `json:"block_count"`
+	Root       []byte `json:"root"`
+}
+
+type Proof struct {
+	Mu    [][]byte   `json:"mu"`
+	Tags  [][]byte   `json:"tags"`
+	Paths [][][]byte `json:"paths"`
+}
+
+var ErrInvalidParams = errors.New("invalid parameters")
+
+var generatorSalt = []byte("pdpr-go/pdpr/public/generator/v1")
+
+func (p Params) validate() error {
+	if p.SectorCount < 1 || p.SectorCount > maxSectorCount {
+		return errorutils.NewfWithInner(ErrInvalidParams, "sector count must be between 1 and %d", maxSectorCount)
+	}
+
+	return nil
+}
+
+func (p Params) blockSize() int {
+	return p.SectorCount * SectorSize
+}
+
+// BlockCount is the number of tagged blocks of a ciphertext of length bytes
+func (p Params) BlockCount(length int) uint64 {
+	return uint64(max(1, (length+p.blockSize()-1)/p.blockSize()))
+}
+
+// hiders returns one hider per sector. The generators are squares of hashes, so
+// they are in the subgroup of prime order, and nobody knows their relative logs
+func (p Params) hiders() []*dlhh.DLHider {
+	return hiders(p.SectorCount)
+}
+
+// hiders returns the hiders of the first count sectors, which are the same for every file
+func hiders(count int) []*dlhh.DLHider {
+	group := dl.NewOakley2Group()
+	r := make([]*dlhh.DLHider, count)
+
+	for j := range r {
+		info := binary.BigEndian.AppendUint64(nil, uint64(j))
+		kdf := hkdf.New(sha256.New, group.Gen.Bytes(), generatorSalt, info)
+
+		bs := make([]byte, ElementSize)
+		if _, err := io.ReadFull(kdf, bs); err != nil {
+			panic(err)
+		}
+		bs[0] &= 0x3f
+
+		h := nmod.NewFromBigEndianBytes(bs, group.Mod)
+		sectorGroup := *group
+		sectorGroup.Gen = h.MustMul(h)
+		r[j] = dlhh.New(&sectorGroup)
+	}
+
+	return r
+}
+
+// sectors returns the sectors of a block, padding the last block with zeros
+func (p Params) sectors(ciphertext []byte, block uint64) [][]byte {
+	r := make([][]byte, p.SectorCount)
+	start := int(block) * p.blockSize()
+
+	for j := range r {
+		from := min(start+j*SectorSize, len(ciphertext))
+		to := min(from+SectorSize, len(ciphertext))
+		r[j] = ciphertext[from:to]
+	}
+
+	return r
+}
+
+// NewTags tags the ciphertext. Tags need no key, but the auditor only trusts
+// the PublicInfo computed by the owner before giving the ciphertext away
+func NewTags(params Params, ciphertext []byte) (*Tags, *PublicInfo, error) {
+	if err := params.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	hiders := params.hiders()
+	count := params.BlockCount(len(ciphertext))
+	tags := &Tags{Params: params, Values: make([][]byte, count)}
+
+	for i := range tags.Values {
+		tag := hiders[0].Hide(nil)
+		for j, sector := range params.sectors(ciphertext, uint64(i)) {
+			tag = hiders[j].CombineHidden(tag, hiders[j].Hide(sector))
+		}
+
+		tags.Values[i] = tag
+	}
+
+	info := &PublicInfo{Params: params, BlockCount: count, Root: merkle.NewFrom(merkle.SHA256, tags.Values).Root()}
+	return tags, info, nil
+}
+
+func NewChallenge(info *PublicInfo, sampleCount int) (*por.Challenge, error) {
+	return por.NewChallenge(CoefficientBits, info.BlockCount, sampleCount)
+}
+
+// Prove computes the answer to a challenge. It is run by the server
+func Prove(ciphertext []byte, tags *Tags, challenge *por.Challenge) (*Proof, error) {
+	mu, r, err := aggregate(ciphertext, tags, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range mu {
+		r.Mu = append(r.Mu, m.Bytes())
+	}
+
+	return r, nil
+}
+
+// aggregate computes mu, and the challenged tags with their inclusion proofs
+func aggregate(ciphertext []byte, tags *Tags, challenge *por.Challenge) ([]*nmod.NatMod, *Proof, error) {
+	params := tags.Params
+	if err := params.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(challenge.Indices) != len(challenge.Coefficients) {
+		return nil, nil, por.ErrMalformedChallenge
+	}
+
+	if uint64(len(tags.Values)) != params.BlockCount(len(ciphertext)) {
+		return nil, nil, errorutils.New("the tags do not match the ciphertext")
+	}
+
+	group := dl.NewOakley2Group()
+	mu := make([]*nmod.NatMod, params.SectorCount)
+	for j := range mu {
+		mu[j] = nmod.NewFromUint(0, group.Order)
+	}
+
+	tree := merkle.NewFrom(merkle.SHA256, tags.Values)
+	r := new(Proof)
+
+	for i, idx := range challenge.Indices {
+		if idx >= uint64(len(tags.Values)) {
+			return nil, nil, errorutils.NewfWithInner(por.ErrIndexOutOfRange, "block %d was challenged, but there are %d blocks", idx, len(tags.Values))
+		}
+
+		c := nmod.NewFromBigEndianBytes(challenge.Coefficients[i].Bytes(), group.Order)
+		for j, sector := range params.sectors(ciphertext, idx) {
+			mu[j] = mu[j].MustAdd(nmod.NewFromBigEndianBytes(sector, group.Order).MustMul(c))
+		}
+
+		path, err := tree.InclusionProof(idx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r.Tags = append(r.Tags, tags.Values[idx])
+		r.Paths = append(r.Paths, path)
+	}
+
+	return mu, r, nil
+}
+
+// Verify checks a proof with public information only
+func Verify(info *PublicInfo, challenge *por.Challenge, proof *Proof) bool {
+	if proof == nil {
+		return false
+	}
+
+	hiders, expected, ok := combineTags(info, challenge, proof.Tags, proof.Paths)
+	if !ok {
+		return false
+	}
+
+	actual, ok := hideMu(hiders, proof.Mu)
+	return ok && bytes.Equal(actual, expected)
+}
+
+// combineTags checks the challenged tags against the root, and returns prod(T_i^c_i)
+func combineTags(info *PublicInfo, challenge *por.Challenge, tags [][]byte, paths [][][]byte) ([]*dlhh.DLHider, []byte, bool) {
+	params := info.Params
+	if params.validate() != nil || len(challenge.Indices) != len(challenge.Coefficients) {
+		return nil, nil, false
+	}
+
+	if len(tags) != len(challenge.Indices) || len(paths) != len(challenge.Indices) {
+		return nil, nil, false
+	}
+
+	hiders := params.hiders()
+	r := hiders[0].Hide(nil)
+
+	for i, idx := range challenge.Indices {
+		tag := tags[i]
+		if len(tag) != ElementSize || !merkle.VerifyInclusion(merkle.SHA256, idx, info.BlockCount, tag, paths[i], info.Root) {
+			return nil, nil, false
+		}
+
+		r = hiders[0].CombineHidden(r, hiders[0].ScaleHidden(tag, challenge.Coefficients[i].Bytes()))
+	}
+
+	return hiders, r, true
+}
+
+// hideMu returns prod(g_j^mu_j)
+func hideMu(hiders []*dlhh.DLHider, mu [][]byte) ([]byte, bool) {
+	if len(mu) != len(hiders) {
+		return nil, false
+	}
+
+	r := hiders[0].Hide(nil)
+	for j, m := range mu {
+		if len(m) != ElementSize {
+			return nil, false
+		}
+
+		r = hiders[j].CombineHidden(r, hiders[j].Hide(m))
+	}
+
+	return r, true
+}
@@ -0,0 +1,104 @@
+package public_test
+
+import (
+	"testing"
+
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr/public"
+)
+
+var params = public.Params{SectorCount: 4}
+
+func ciphertext() []byte {
+	return gcrypt.MustNew(64).MustEncrypt([]byte("Hello, World! This file is audited by anyone."), []byte("This is a key"))
+}
+
+func Test__Public__HonestProof__ShouldVerify__WithPublicInfoOnly(t *testing.T) {
+	ez := ez.New(t)
+	ct := ciphertext()
+
+	tags, info, err := public.NewTags(params, ct)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(info.BlockCount, uint64((len(ct)+4*public.SectorSize-1)/(4*public.SectorSize)))
+
+	challenge, err := public.NewChallenge(info, 2)
+	ez.AssertNoError(err)
+
+	proof, err := public.Prove(ct, tags, challenge)
+	ez.AssertNoError(err)
+	ez.Assert(public.Verify(info, challenge, proof))
+
+	other, _ := public.NewChallenge(info, 2)
+	ez.AssertFalse(public.Verify(info, other, proof))
+}
+
+func Test__Public__Proof__ShouldNotVerify__WhenCiphertextIsCorrupted(t *testing.T) {
+	ez := ez.New(t)
+	ct := ciphertext()
+	tags, info, _ := public.NewTags(params, ct)
+
+	ct[len(ct)-1] ^= 1
+
+	challenge, _ := public.NewChallenge(info, int(info.BlockCount))
+	proof, err := public.Prove(ct, tags, challenge)
+	ez.AssertNoError(err)
+	ez.AssertFalse(public.Verify(info, challenge, proof))
+}
+
+func Test__Public__Proof__ShouldNotVerify__WhenTagsAreForged(t *testing.T) {
+	ez := ez.New(t)
+	ct := ciphertext()
+	_, info, _ := public.NewTags(params, ct)
+
+	// the server retags corrupted data, but cannot change the published root
+	ct[0] ^= 1
+	forged, _, _ := public.NewTags(params, ct)
+
+	challenge, _ := public.NewChallenge(info, int(info.BlockCount))
+	proof, err := public.Prove(ct, forged, challenge)
+	ez.AssertNoError(err)
+	ez.AssertFalse(public.Verify(info, challenge, proof))
+}
+
+func Test__Public__BlindedProof__ShouldVerify__WithoutRevealingMu(t *testing.T) {
+	ez := ez.New(t)
+	ct := ciphertext()
+	tags, info, _ := public.NewTags(params, ct)
+	challenge, _ := public.NewChallenge(info, 3)
+
+	plain, err := public.Prove(ct, tags, challenge)
+	ez.AssertNoError(err)
+
+	first, err := public.ProveBlinded(ct, tags, challenge)
+	ez.AssertNoError(err)
+	ez.Assert(public.VerifyBlinded(info, challenge, first))
+
+	second, _ := public.ProveBlinded(ct, tags, challenge)
+	ez.Assert(public.VerifyBlinded(info, challenge, second))
+
+	// the same challenge yields unrelated answers, none of which is mu
+	for j := range plain.Mu {
+		ez.AssertFalse(string(first.Mu[j]) == string(second.Mu[j]))
+		ez.AssertFalse(string(first.Mu[j]) == string(plain.Mu[j]))
+	}
+
+	first.Mu[0][len(first.Mu[0])-1] ^= 1
+	ez.AssertFalse(public.VerifyBlinded(info, challenge, first))
+
+	second.Commitment = first.Commitment
+	ez.AssertFalse(public.VerifyBlinded(info, challenge, second))
+}
+
+func Test__Public__BlindedProof__ShouldNotVerify__WhenCiphertextIsCorrupted(t *testing.T) {
+	ez := ez.New(t)
+	ct := ciphertext()
+	tags, info, _ := public.NewTags(params, ct)
+
+	ct[len(ct)/2] ^= 1
+
+	challenge, _ := public.NewChallenge(info, int(info.BlockCount))
+	proof, err := public.ProveBlinded(ct, tags, challenge)
+	ez.AssertNoError(err)
+	ez.AssertFalse(public.VerifyBlinded(info, challenge, proof))
+}
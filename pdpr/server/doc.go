@@ -0,0 +1,24 @@
+// Package server implements the storage side of PDPr as an HTTP service.
+//
+// The server stores, for each file, the GCrypt ciphertext and the GHash nonce
+// state given by the owner (see package pdpr), and computes proofs of possession
+// for the challenges of the owner, issued by pdpr.Tag.IssueChallenge. It never
+// sees keys or tags. Files are kept in any store.Store.
+// Every body is JSON, and byte fields are base64 encoded, except for raw
+// ciphertext ranges.
+//
+//	PUT    /v1/files/{id}        stores a Record; answers 204
+//	GET    /v1/files/{id}        returns the Record
+//	GET    /v1/files/{id}/ciphertext?offset=&length=
+//	                             returns the raw bytes of a ciphertext range
+//	DELETE /v1/files/{id}        deletes the file; answers 204
+//	GET    /v1/files             returns a FileList
+//	POST   /v1/files/{id}/proof  answers the freshness.Challenge of the file in
+//	                             the body with a freshness.Response
+//
+// File ids are made of letters, digits, '.', '_' and '-', up to 128 characters.
+// Errors are answered with an ErrorResponse and a 4xx or 5xx status:
+// 400 for malformed requests and for challenges that are malformed, expired or
+// for another file, 404 for unknown files, 416 for ranges past the end
+// of the ciphertext and 500 for storage failures.
+package server
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"regexp"
+
+	"github.com/titosilva/pdpr-go/pdpr"
+)
+
+// Record is everything the server stores about a file
+type Record struct {
+	Params     pdpr.Params `json:"params"`
+	Ciphertext []byte      `json:"ciphertext"`
+	State      pdpr.State  `json:"state"`
+}
+
+type FileList struct {
+	Files []string `json:"files"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// ValidID reports whether id can be used as a file id
+func ValidID(id string) bool {
+	return validID.MatchString(id) && id != "." && id != ".."
+}
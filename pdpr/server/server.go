@@ -0,0 +1,221 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/freshness"
+	"github.com/titosilva/pdpr-go/pdpr/store"
+)
+
+// maxBodySize bounds uploads, as GCrypt ciphertexts are much larger than their plaintexts
+const maxBodySize = 1 << 30
+
+// maxChallengeSize bounds challenges, whose indices are derived from their nonce
+const maxChallengeSize = 1 << 16
+
+// Server answers challenges until they expire. Clock defaults to time.Now,
+// and can be replaced in tests
+type Server struct {
+	Clock func() time.Time
+
+	store store.Store
+	mux   *http.ServeMux
+}
+
+var _ http.Handler = (*Server)(nil)
+
+func New(s store.Store) *Server {
+	r := new(Server)
+	r.Clock = time.Now
+	r.store = s
+	r.mux = http.NewServeMux()
+
+	r.mux.HandleFunc("PUT /v1/files/{id}", r.withID(r.put))
+	r.mux.HandleFunc("GET /v1/files/{id}", r.withID(r.get))
+	r.mux.HandleFunc("GET /v1/files/{id}/ciphertext", r.withID(r.getRange))
+	r.mux.HandleFunc("DELETE /v1/files/{id}", r.withID(r.delete))
+	r.mux.HandleFunc("GET /v1/files", r.list)
+	r.mux.HandleFunc("POST /v1/files/{id}/proof", r.withID(r.prove))
+
+	return r
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(w, req)
+}
+
+func (s *Server) withID(handler func(http.ResponseWriter, *http.Request, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id := req.PathValue("id")
+		if !ValidID(id) {
+			writeError(w, http.StatusBadRequest, "invalid file id")
+			return
+		}
+
+		handler(w, req, id)
+	}
+}
+
+func (s *Server) put(w http.ResponseWriter, req *http.Request, id string) {
+	record := new(Record)
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBodySize)).Decode(record); err != nil {
+		writeError(w, http.StatusBadRequest, "malformed record: "+err.Error())
+		return
+	}
+
+	if err := record.Params.Check(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid parameters: "+err.Error())
+		return
+	}
+
+	if blockSize := int(record.Params.ModulusBits / 8); len(record.Ciphertext)%blockSize != 0 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("ciphertext of %d bytes is not a whole number of %d-byte blocks", len(record.Ciphertext), blockSize))
+		return
+	}
+
+	if len(record.State.NonceState) != record.Params.StateSize() {
+		writeError(w, http.StatusBadRequest, "nonce state has the wrong size")
+		return
+	}
+
+	if err := saveRecord(req.Context(), s.store, id, record); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) get(w http.ResponseWriter, req *http.Request, id string) {
+	record, err := loadRecord(req.Context(), s.store, id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) getRange(w http.ResponseWriter, req *http.Request, id string) {
+	offset, err := queryInt(req, "offset", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	length, err := queryInt(req, "length", maxBodySize)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// the meta is checked so that partially deleted files are not served
+	if _, err := s.store.Get(req.Context(), filesPrefix+id+metaKey); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	bs, err := s.store.GetRange(req.Context(), filesPrefix+id+ciphertextKey, offset, length)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.Write(bs)
+}
+
+func queryInt(req *http.Request, name string, fallback int64) (int64, error) {
+	v := req.URL.Query().Get(name)
+	if v == "" {
+		return fallback, nil
+	}
+
+	r, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || r < 0 {
+		return 0, errors.New("invalid " + name)
+	}
+
+	return r, nil
+}
+
+func (s *Server) delete(w http.ResponseWriter, req *http.Request, id string) {
+	if err := deleteRecord(req.Context(), s.store, id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) list(w http.ResponseWriter, req *http.Request) {
+	ids, err := listRecords(req.Context(), s.store)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &FileList{Files: ids})
+}
+
+func (s *Server) prove(w http.ResponseWriter, req *http.Request, id string) {
+	challenge := new(freshness.Challenge)
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxChallengeSize)).Decode(challenge); err != nil {
+		writeError(w, http.StatusBadRequest, "malformed challenge: "+err.Error())
+		return
+	}
+
+	if challenge.FileID != id {
+		writeError(w, http.StatusBadRequest, "challenge is for another file")
+		return
+	}
+
+	record, err := loadRecord(req.Context(), s.store, id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	response, err := pdpr.Respond(record.Params, record.Ciphertext, &record.State, challenge, s.Clock())
+	if errors.Is(err, pdpr.ErrMalformedChallenge) || errors.Is(err, freshness.ErrExpired) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, &ErrorResponse{Error: msg})
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if errors.Is(err, store.ErrInvalidRange) {
+		writeError(w, http.StatusRequestedRangeNotSatisfiable, err.Error())
+		return
+	}
+
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+	"github.com/titosilva/pdpr-go/pdpr/store"
+)
+
+// Each file is kept as two objects of the store: the raw ciphertext, so that
+// it can be read by ranges, and a small JSON object with everything else.
+// The ciphertext is written first, so a file is only listed once it is complete
+const (
+	filesPrefix   = "files/"
+	ciphertextKey = "/ciphertext"
+	metaKey       = "/meta"
+)
+
+type meta struct {
+	Params pdpr.Params `json:"params"`
+	State  pdpr.State  `json:"state"`
+}
+
+func saveRecord(ctx context.Context, s store.Store, id string, record *Record) error {
+	bs, err := json.Marshal(&meta{record.Params, record.State})
+	if err != nil {
+		return err
+	}
+
+	if err := s.Put(ctx, filesPrefix+id+ciphertextKey, record.Ciphertext); err != nil {
+		return err
+	}
+
+	return s.Put(ctx, filesPrefix+id+metaKey, bs)
+}
+
+func loadRecord(ctx context.Context, s store.Store, id string) (*Record, error) {
+	bs, err := s.Get(ctx, filesPrefix+id+metaKey)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(meta)
+	if err := json.Unmarshal(bs, m); err != nil {
+		return nil, errorutils.NewfWithInner(err, "record %s is corrupted", id)
+	}
+
+	ct, err := s.Get(ctx, filesPrefix+id+ciphertextKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Record{Params: m.Params, Ciphertext: ct, State: m.State}, nil
+}
+
+func deleteRecord(ctx context.Context, s store.Store, id string) error {
+	// the meta goes first, so a failure never leaves a listed file without ciphertext
+	if err := s.Delete(ctx, filesPrefix+id+metaKey); err != nil {
+		return err
+	}
+
+	return s.Delete(ctx, filesPrefix+id+ciphertextKey)
+}
+
+func listRecords(ctx context.Context, s store.Store) ([]string, error) {
+	keys, err := s.List(ctx, filesPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]string, 0, len(keys)/2)
+	for _, key := range keys {
+		if strings.HasSuffix(key, metaKey) {
+			r = append(r, strings.TrimSuffix(strings.TrimPrefix(key, filesPrefix), metaKey))
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// dirStore keeps each value in a file, with key segments mapped to directories
+type dirStore struct {
+	dir string
+}
+
+var _ Store = (*dirStore)(nil)
+
+const tmpPrefix = ".tmp-"
+
+func NewDir(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errorutils.NewWithInner(err, "could not create the store directory")
+	}
+
+	return &dirStore{dir}, nil
+}
+
+func (s *dirStore) path(key string) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if strings.HasPrefix(segment, tmpPrefix) {
+			return "", errorutils.NewfWithInner(ErrInvalidKey, "key %q uses a reserved prefix", key)
+		}
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func (s *dirStore) Put(ctx context.Context, key string, value []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// write then rename, so that a crash never leaves a partial value
+	tmp, err := os.CreateTemp(filepath.Dir(path), tmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *dirStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return r, err
+}
+
+func (s *dirStore) GetRange(ctx context.Context, key string, offset int64, length int64) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if offset < 0 || length < 0 || offset > stat.Size() {
+		return nil, ErrInvalidRange
+	}
+
+	r := make([]byte, min(length, stat.Size()-offset))
+	if _, err := f.ReadAt(r, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s *dirStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (s *dirStore) List(ctx context.Context, prefix string) ([]string, error) {
+	r := make([]string, 0)
+
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || strings.HasPrefix(d.Name(), tmpPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			r = append(r, key)
+		}
+
+		return nil
+	})
+	sort.Strings(r)
+
+	return r, err
+}
@@ -0,0 +1,346 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// LogStore is an embedded store kept in a single append-only file, in the
+// spirit of bbolt or SQLite but much simpler: every Put or Delete appends a
+// record, and an in-memory index maps each key to its latest value.
+// The index is rebuilt when the file is opened; a torn record at the end of
+// the file, left by a crash, is discarded, but a bad record followed by more
+// records fails with ErrCorrupted. Compact rewrites the file without
+// overwritten or deleted values.
+//
+// A record is: op (1 byte), key length and value length (uvarints), key,
+// value, and the CRC-32 of everything before it (4 bytes, big-endian)
+type LogStore struct {
+	path  string
+	file  *os.File
+	size  int64
+	index map[string]logEntry
+	mutex sync.RWMutex
+}
+
+type logEntry struct {
+	offset int64
+	length int64
+}
+
+const (
+	opPut    byte = 1
+	opDelete byte = 2
+)
+
+var _ Store = (*LogStore)(nil)
+
+var ErrCorrupted = errors.New("log store file is corrupted")
+
+// errTornRecord is a record cut short by the end of the file, and
+// errBadRecord one that does not check out
+var errTornRecord = errors.New("torn record")
+var errBadRecord = errors.New("bad record")
+
+func OpenLog(path string) (*LogStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(LogStore)
+	r.path = path
+	r.file = f
+	r.index = make(map[string]logEntry)
+
+	if err := r.load(); err != nil {
+		f.Close()
+		return nil, errorutils.NewfWithInner(err, "could not load %s", path)
+	}
+
+	return r, nil
+}
+
+// load rebuilds the index. A torn record at the end of the file, either cut
+// short or ending at the end of the file without checking out, is truncated
+// away; any other bad record fails with ErrCorrupted, leaving the file as is
+func (s *LogStore) load() error {
+	stat, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(s.file, 0, stat.Size()))
+	offset := int64(0)
+
+	for {
+		record, err := readRecord(reader, offset)
+		if errors.Is(err, io.EOF) || errors.Is(err, errTornRecord) {
+			break
+		}
+
+		if errors.Is(err, errBadRecord) && record != nil && offset+record.size == stat.Size() {
+			// the last write was not fully synced
+			break
+		}
+
+		if errors.Is(err, errBadRecord) {
+			return errorutils.NewfWithInner(ErrCorrupted, "bad record at offset %d of %d", offset, stat.Size())
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if record.op == opPut {
+			s.index[record.key] = record.value
+		} else {
+			delete(s.index, record.key)
+		}
+
+		offset += record.size
+	}
+
+	s.size = offset
+	return s.file.Truncate(offset)
+}
+
+type logRecord struct {
+	op    byte
+	key   string
+	value logEntry
+	size  int64
+}
+
+const maxKeyLength = 1 << 16
+
+// readRecord reads the record at offset, skipping over its value. A record
+// that does not check out is returned with errBadRecord, for its size
+func readRecord(reader *bufio.Reader, offset int64) (*logRecord, error) {
+	op, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	keyLength, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, recordError(err)
+	}
+
+	if keyLength > maxKeyLength {
+		return nil, errorutils.NewfWithInner(errBadRecord, "key of %d bytes", keyLength)
+	}
+
+	valueLength, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, recordError(err)
+	}
+
+	header := binary.AppendUvarint(binary.AppendUvarint([]byte{op}, keyLength), valueLength)
+	crc := crc32.NewIEEE()
+	crc.Write(header)
+
+	key := make([]byte, keyLength)
+	if _, err := io.ReadFull(reader, key); err != nil {
+		return nil, recordError(err)
+	}
+	crc.Write(key)
+
+	if _, err := io.CopyN(crc, reader, int64(valueLength)); err != nil {
+		return nil, recordError(err)
+	}
+
+	stored := make([]byte, 4)
+	if _, err := io.ReadFull(reader, stored); err != nil {
+		return nil, recordError(err)
+	}
+
+	valueOffset := offset + int64(len(header)) + int64(keyLength)
+	r := &logRecord{
+		op:    op,
+		key:   string(key),
+		value: logEntry{valueOffset, int64(valueLength)},
+		size:  int64(len(header)) + int64(keyLength) + int64(valueLength) + 4,
+	}
+
+	if binary.BigEndian.Uint32(stored) != crc.Sum32() || (op != opPut && op != opDelete) {
+		return r, errBadRecord
+	}
+
+	return r, nil
+}
+
+// recordError tells a record cut short by the end of the file from one that
+// cannot be read, such as one whose lengths overflow
+func recordError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errTornRecord
+	}
+
+	return errors.Join(errBadRecord, err)
+}
+
+func encodeRecord(op byte, key string, value []byte) []byte {
+	r := []byte{op}
+	r = binary.AppendUvarint(r, uint64(len(key)))
+	r = binary.AppendUvarint(r, uint64(len(value)))
+	r = append(r, key...)
+	r = append(r, value...)
+
+	return binary.BigEndian.AppendUint32(r, crc32.ChecksumIEEE(r))
+}
+
+// append writes a record at the end of the file, and returns the offset of its value
+func (s *LogStore) append(op byte, key string, value []byte) (int64, error) {
+	record := encodeRecord(op, key, value)
+	if _, err := s.file.WriteAt(record, s.size); err != nil {
+		return 0, err
+	}
+
+	if err := s.file.Sync(); err != nil {
+		return 0, err
+	}
+
+	valueOffset := s.size + int64(len(record)-len(value)-4)
+	s.size += int64(len(record))
+
+	return valueOffset, nil
+}
+
+func (s *LogStore) Put(ctx context.Context, key string, value []byte) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	offset, err := s.append(opPut, key, value)
+	if err != nil {
+		return err
+	}
+
+	s.index[key] = logEntry{offset, int64(len(value))}
+	return nil
+}
+
+func (s *LogStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.GetRange(ctx, key, 0, 1<<62)
+}
+
+func (s *LogStore) GetRange(ctx context.Context, key string, offset int64, length int64) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, ok := s.index[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if offset < 0 || length < 0 || offset > entry.length {
+		return nil, ErrInvalidRange
+	}
+
+	r := make([]byte, min(length, entry.length-offset))
+	if _, err := s.file.ReadAt(r, entry.offset+offset); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s *LogStore) Delete(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.index[key]; !ok {
+		return ErrNotFound
+	}
+
+	if _, err := s.append(opDelete, key, nil); err != nil {
+		return err
+	}
+
+	delete(s.index, key)
+	return nil
+}
+
+func (s *LogStore) List(ctx context.Context, prefix string) ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	r := make([]string, 0)
+	for key := range s.index {
+		if strings.HasPrefix(key, prefix) {
+			r = append(r, key)
+		}
+	}
+	sort.Strings(r)
+
+	return r, nil
+}
+
+// Compact rewrites the file with the live values only
+func (s *LogStore) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tmpPath := s.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[string]logEntry, len(s.index))
+	size := int64(0)
+	for key, entry := range s.index {
+		value := make([]byte, entry.length)
+		if _, err := s.file.ReadAt(value, entry.offset); err != nil {
+			tmp.Close()
+			return err
+		}
+
+		record := encodeRecord(opPut, key, value)
+		if _, err := tmp.WriteAt(record, size); err != nil {
+			tmp.Close()
+			return err
+		}
+
+		index[key] = logEntry{size + int64(len(record)-len(value)-4), entry.length}
+		size += int64(len(record))
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	s.file.Close()
+	s.file = tmp
+	s.index = index
+	s.size = size
+
+	return nil
+}
+
+func (s *LogStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.file.Close()
+}
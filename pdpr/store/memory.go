@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type memoryStore struct {
+	values map[string][]byte
+	mutex  sync.RWMutex
+}
+
+var _ Store = (*memoryStore)(nil)
+
+func NewMemory() Store {
+	r := new(memoryStore)
+	r.values = make(map[string][]byte)
+
+	return r
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, value []byte) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.values[key] = append([]byte{}, value...)
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	v, ok := s.values[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return append([]byte{}, v...), nil
+}
+
+func (s *memoryStore) GetRange(ctx context.Context, key string, offset int64, length int64) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	v, ok := s.values[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return slice(v, offset, length)
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.values[key]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.values, key)
+	return nil
+}
+
+func (s *memoryStore) List(ctx context.Context, prefix string) ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	r := make([]string, 0)
+	for key := range s.values {
+		if strings.HasPrefix(key, prefix) {
+			r = append(r, key)
+		}
+	}
+	sort.Strings(r)
+
+	return r, nil
+}
@@ -0,0 +1,244 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// S3Config locates a bucket on an S3-compatible service.
+// Objects are addressed path-style, as in Endpoint/Bucket/key
+type S3Config struct {
+	Endpoint   string
+	Region     string
+	Bucket     string
+	AccessKey  string
+	SecretKey  string
+	HTTPClient *http.Client
+}
+
+type s3Store struct {
+	config S3Config
+	http   *http.Client
+	now    func() time.Time
+}
+
+var _ Store = (*s3Store)(nil)
+
+func NewS3(config S3Config) (Store, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.Region == "" {
+		return nil, errorutils.New("S3 endpoint, region and bucket are required")
+	}
+
+	r := new(s3Store)
+	r.config = config
+	r.config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	r.http = config.HTTPClient
+	if r.http == nil {
+		r.http = http.DefaultClient
+	}
+	r.now = time.Now
+
+	return r, nil
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, value []byte) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, nil, nil, value)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return s.check(resp, http.StatusOK)
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := s.check(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (s *s3Store) GetRange(ctx context.Context, key string, offset int64, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 {
+		return nil, ErrInvalidRange
+	}
+
+	// S3 cannot express empty ranges, nor ranges starting at the end of the object
+	size, err := s.size(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > size {
+		return nil, ErrInvalidRange
+	}
+
+	if length == 0 || offset == size {
+		return []byte{}, nil
+	}
+
+	// clamped before adding, as offset+length may overflow
+	if length > size-offset {
+		length = size - offset
+	}
+
+	headers := map[string]string{"Range": fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := s.check(resp, http.StatusPartialContent); err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	// S3 deletes missing objects successfully, but Store must report them
+	if _, err := s.size(ctx, key); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return s.check(resp, http.StatusNoContent)
+}
+
+func (s *s3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	r := make([]string, 0)
+	token := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		result := new(s3ListResult)
+		err = s.check(resp, http.StatusOK)
+		if err == nil {
+			err = xml.NewDecoder(resp.Body).Decode(result)
+		}
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range result.Contents {
+			r = append(r, c.Key)
+		}
+
+		if !result.IsTruncated {
+			return r, nil
+		}
+
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *s3Store) size(ctx context.Context, key string) (int64, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := s.check(resp, http.StatusOK); err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+}
+
+// do sends a signed request for key, or for the bucket itself if key is empty
+func (s *s3Store) do(ctx context.Context, method string, key string, query url.Values, headers map[string]string, body []byte) (*http.Response, error) {
+	path := "/" + uriEncode(s.config.Bucket, true)
+	if key != "" {
+		path += "/" + uriEncode(key, false)
+	}
+
+	u, err := url.Parse(s.config.Endpoint + path)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	payloadHash := hexSHA256(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signV4(req, payloadHash, s.config.AccessKey, s.config.SecretKey, s.config.Region, "s3", s.now())
+
+	return s.http.Do(req)
+}
+
+func (s *s3Store) check(resp *http.Response, expected int) error {
+	if resp.StatusCode == expected || (expected == http.StatusNoContent && resp.StatusCode == http.StatusOK) {
+		return nil
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	msg := new(s3Error)
+	if err := xml.NewDecoder(resp.Body).Decode(msg); err != nil {
+		return errorutils.Newf("S3 answered %s", resp.Status)
+	}
+
+	return errorutils.Newf("S3 answered %s: %s: %s", resp.Status, msg.Code, msg.Message)
+}
@@ -0,0 +1,180 @@
+// Package s3test is a local stand-in for an S3-compatible service, to test
+// S3 stores without network access. It keeps objects in memory, and supports
+// path-style PUT, GET (with single ranges), HEAD and DELETE of objects, and
+// ListObjectsV2. Requests must be signed with AWS Signature Version 4 by the
+// expected access key; the signature itself is not checked.
+package s3test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Server struct {
+	AccessKey string
+	// MaxKeys bounds the size of list pages, to exercise pagination
+	MaxKeys int
+
+	buckets map[string]map[string][]byte
+	mutex   sync.Mutex
+}
+
+var _ http.Handler = (*Server)(nil)
+
+func New(accessKey string, buckets ...string) *Server {
+	r := new(Server)
+	r.AccessKey = accessKey
+	r.MaxKeys = 1000
+	r.buckets = make(map[string]map[string][]byte)
+
+	for _, b := range buckets {
+		r.buckets[b] = make(map[string][]byte)
+	}
+
+	return r
+}
+
+type listResult struct {
+	XMLName               xml.Name   `xml:"ListBucketResult"`
+	Name                  string     `xml:"Name"`
+	Prefix                string     `xml:"Prefix"`
+	KeyCount              int        `xml:"KeyCount"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken,omitempty"`
+	Contents              []contents `xml:"Contents"`
+}
+
+type contents struct {
+	Key  string `xml:"Key"`
+	Size int    `xml:"Size"`
+}
+
+type errorResult struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+s.AccessKey+"/") {
+		writeError(w, http.StatusForbidden, "AccessDenied", "missing or foreign signature")
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/")
+	bucketName, key, _ := strings.Cut(path, "/")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	bucket, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", bucketName)
+		return
+	}
+
+	if key == "" && req.Method == http.MethodGet {
+		s.list(w, req, bucketName, bucket)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+
+		bucket[key] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		value, ok := bucket[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", key)
+			return
+		}
+
+		s.get(w, req, value)
+	case http.MethodDelete:
+		delete(bucket, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", req.Method)
+	}
+}
+
+func (s *Server) get(w http.ResponseWriter, req *http.Request, value []byte) {
+	rangeHeader := req.Header.Get("Range")
+	if rangeHeader == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(len(value)))
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			w.Write(value)
+		}
+
+		return
+	}
+
+	var start, end int
+	if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end); err != nil || start > end || start >= len(value) {
+		writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", rangeHeader)
+		return
+	}
+
+	end = min(end, len(value)-1)
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(value)))
+	w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+	w.WriteHeader(http.StatusPartialContent)
+	w.Write(value[start : end+1])
+}
+
+func (s *Server) list(w http.ResponseWriter, req *http.Request, name string, bucket map[string][]byte) {
+	query := req.URL.Query()
+	if query.Get("list-type") != "2" {
+		writeError(w, http.StatusNotImplemented, "NotImplemented", "only ListObjectsV2 is supported")
+		return
+	}
+
+	prefix := query.Get("prefix")
+	keys := make([]string, 0)
+	for key := range bucket {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// the continuation token is the last key of the previous page
+	token := query.Get("continuation-token")
+	start := sort.SearchStrings(keys, token)
+	if token != "" && start < len(keys) && keys[start] == token {
+		start++
+	}
+
+	result := listResult{Name: name, Prefix: prefix}
+	for i := start; i < len(keys) && len(result.Contents) < s.MaxKeys; i++ {
+		result.Contents = append(result.Contents, contents{Key: keys[i], Size: len(bucket[keys[i]])})
+	}
+
+	result.KeyCount = len(result.Contents)
+	if start+len(result.Contents) < len(keys) {
+		result.IsTruncated = true
+		result.NextContinuationToken = result.Contents[len(result.Contents)-1].Key
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, msg string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(errorResult{Code: code, Message: msg})
+}
@@ -0,0 +1,26 @@
+package store
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+)
+
+// Example from the AWS documentation of Signature Version 4
+func Test__SignV4__ShouldMatch__AWSExample(t *testing.T) {
+	ez := ez.New(t)
+
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	ez.AssertNoError(err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	date := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signV4(req, hexSHA256(nil), "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "iam", date)
+
+	auth := req.Header.Get("Authorization")
+	ez.Assert(strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, "), auth)
+	ez.Assert(strings.HasSuffix(auth, "Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"), auth)
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+// Store keeps opaque values, such as ciphertexts and tags, under string keys.
+// Keys are made of '/'-separated segments, and List returns them sorted
+type Store interface {
+	Put(ctx context.Context, key string, value []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// GetRange returns length bytes of the value, starting at offset.
+	// The range is truncated at the end of the value
+	GetRange(ctx context.Context, key string, offset int64, length int64) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+var ErrNotFound = errors.New("key not found")
+var ErrInvalidKey = errors.New("invalid key")
+var ErrInvalidRange = errors.New("invalid range")
+
+// CheckKey rejects empty keys, and keys with empty, "." or ".." segments
+func CheckKey(key string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return errorutils.NewfWithInner(ErrInvalidKey, "key %q has an invalid segment", key)
+		}
+	}
+
+	return nil
+}
+
+// slice returns the requested range of value
+func slice(value []byte, offset int64, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 || offset > int64(len(value)) {
+		return nil, ErrInvalidRange
+	}
+
+	// clamped before adding, as offset+length may overflow
+	if length > int64(len(value))-offset {
+		length = int64(len(value)) - offset
+	}
+
+	return append([]byte{}, value[offset:offset+length]...), nil
+}
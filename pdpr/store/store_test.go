@@ -0,0 +1,208 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/pdpr/store"
+	"github.com/titosilva/pdpr-go/pdpr/store/s3test"
+)
+
+func stores(t *testing.T) map[string]store.Store {
+	dir, err := store.NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := store.OpenLog(filepath.Join(t.TempDir(), "store.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	fake := s3test.New("AKIDEXAMPLE", "pdpr")
+	fake.MaxKeys = 2
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s3, err := store.NewS3(store.S3Config{
+		Endpoint:   srv.URL,
+		Region:     "us-east-1",
+		Bucket:     "pdpr",
+		AccessKey:  "AKIDEXAMPLE",
+		SecretKey:  "secret",
+		HTTPClient: srv.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]store.Store{"memory": store.NewMemory(), "dir": dir, "log": log, "s3": s3}
+}
+
+func Test__Store__PutThenGet__ShouldReturnOriginalValue(t *testing.T) {
+	ctx := context.Background()
+
+	for name, s := range stores(t) {
+		ez := ez.New(t)
+
+		ez.AssertNoError(s.Put(ctx, "files/a/ciphertext", []byte("Hello, World!")), name)
+		ez.AssertNoError(s.Put(ctx, "files/a/ciphertext", []byte("Hello, World!!")), name)
+		ez.AssertNoError(s.Put(ctx, "files/b c/meta", []byte{}), name)
+
+		v, err := s.Get(ctx, "files/a/ciphertext")
+		ez.AssertNoError(err, name)
+		ez.AssertAreEqual(string(v), "Hello, World!!", name)
+
+		v, err = s.Get(ctx, "files/b c/meta")
+		ez.AssertNoError(err, name)
+		ez.AssertAreEqual(len(v), 0, name)
+
+		_, err = s.Get(ctx, "files/missing")
+		ez.Assert(errors.Is(err, store.ErrNotFound), name)
+	}
+}
+
+func Test__Store__GetRange__ShouldReturnSlice(t *testing.T) {
+	ctx := context.Background()
+
+	for name, s := range stores(t) {
+		ez := ez.New(t)
+		ez.AssertNoError(s.Put(ctx, "k", []byte("Hello, World!")), name)
+
+		v, err := s.GetRange(ctx, "k", 7, 5)
+		ez.AssertNoError(err, name)
+		ez.AssertAreEqual(string(v), "World", name)
+
+		v, err = s.GetRange(ctx, "k", 7, 100)
+		ez.AssertNoError(err, name)
+		ez.AssertAreEqual(string(v), "World!", name)
+
+		v, err = s.GetRange(ctx, "k", 13, 1)
+		ez.AssertNoError(err, name)
+		ez.AssertAreEqual(len(v), 0, name)
+
+		_, err = s.GetRange(ctx, "k", 14, 1)
+		ez.Assert(errors.Is(err, store.ErrInvalidRange), name)
+
+		// offset+length overflows int64
+		v, err = s.GetRange(ctx, "k", 1, math.MaxInt64)
+		ez.AssertNoError(err, name)
+		ez.AssertAreEqual(string(v), "ello, World!", name)
+
+		_, err = s.GetRange(ctx, "missing", 0, 1)
+		ez.Assert(errors.Is(err, store.ErrNotFound), name)
+	}
+}
+
+func Test__Store__DeleteAndList__ShouldUpdateKeys(t *testing.T) {
+	ctx := context.Background()
+
+	for name, s := range stores(t) {
+		ez := ez.New(t)
+		for _, key := range []string{"files/c", "files/a", "other/x", "files/b", "files/d"} {
+			ez.AssertNoError(s.Put(ctx, key, []byte(key)), name)
+		}
+
+		ez.AssertNoError(s.Delete(ctx, "files/b"), name)
+		ez.Assert(errors.Is(s.Delete(ctx, "files/b"), store.ErrNotFound), name)
+
+		keys, err := s.List(ctx, "files/")
+		ez.AssertNoError(err, name)
+		ez.AssertAreEqual(keys, []string{"files/a", "files/c", "files/d"}, name)
+	}
+}
+
+func Test__Store__Put__ShouldFail__WhenKeyIsInvalid(t *testing.T) {
+	ctx := context.Background()
+
+	for name, s := range stores(t) {
+		ez := ez.New(t)
+
+		for _, key := range []string{"", "/a", "a//b", "a/../b", "a/"} {
+			ez.Assert(errors.Is(s.Put(ctx, key, nil), store.ErrInvalidKey), name, key)
+		}
+	}
+}
+
+func Test__LogStore__Reopen__ShouldRecover__AfterTornWrite(t *testing.T) {
+	ez := ez.New(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.log")
+
+	s, err := store.OpenLog(path)
+	ez.AssertNoError(err)
+	ez.AssertNoError(s.Put(ctx, "a", []byte("first")))
+	ez.AssertNoError(s.Put(ctx, "b", []byte("second")))
+	ez.AssertNoError(s.Delete(ctx, "a"))
+	ez.AssertNoError(s.Put(ctx, "c", []byte("third")))
+	ez.AssertNoError(s.Close())
+
+	// simulate a crash in the middle of the last write
+	stat, _ := os.Stat(path)
+	ez.AssertNoError(os.Truncate(path, stat.Size()-2))
+
+	s, err = store.OpenLog(path)
+	ez.AssertNoError(err)
+	keys, _ := s.List(ctx, "")
+	ez.AssertAreEqual(keys, []string{"b"})
+
+	ez.AssertNoError(s.Put(ctx, "d", []byte("fourth")))
+	ez.AssertNoError(s.Compact())
+	ez.AssertNoError(s.Close())
+
+	s, err = store.OpenLog(path)
+	ez.AssertNoError(err)
+	defer s.Close()
+
+	v, err := s.Get(ctx, "d")
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(string(v), "fourth")
+
+	keys, _ = s.List(ctx, "")
+	ez.AssertAreEqual(keys, []string{"b", "d"})
+}
+
+func Test__LogStore__Reopen__ShouldFail__WhenARecordBeforeTheEndIsCorrupted(t *testing.T) {
+	ez := ez.New(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.log")
+
+	s, err := store.OpenLog(path)
+	ez.AssertNoError(err)
+	ez.AssertNoError(s.Put(ctx, "a", []byte("first")))
+	ez.AssertNoError(s.Put(ctx, "b", []byte("second")))
+	ez.AssertNoError(s.Close())
+
+	contents, _ := os.ReadFile(path)
+	stat, _ := os.Stat(path)
+
+	// a bit flip in the value of the first record
+	corrupted := bytes.Clone(contents)
+	corrupted[5] ^= 1
+	ez.AssertNoError(os.WriteFile(path, corrupted, 0600))
+
+	_, err = store.OpenLog(path)
+	ez.AssertErrorIs(err, store.ErrCorrupted)
+	after, _ := os.Stat(path)
+	ez.AssertAreEqual(after.Size(), stat.Size())
+
+	// the same bit flip in the last record discards it only
+	corrupted = bytes.Clone(contents)
+	corrupted[len(corrupted)-6] ^= 1
+	ez.AssertNoError(os.WriteFile(path, corrupted, 0600))
+
+	s, err = store.OpenLog(path)
+	ez.AssertNoError(err)
+	defer s.Close()
+
+	keys, _ := s.List(ctx, "")
+	ez.AssertAreEqual(keys, []string{"a"})
+}
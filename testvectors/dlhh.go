@@ -0,0 +1,65 @@
+package testvectors
+
+import (
+	"github.com/titosilva/pdpr-go/crypto/homomorphic_hiding/dlhh"
+	"github.com/titosilva/pdpr-go/math/dl"
+)
+
+// DLHHVector is over the Oakley group 2, with big-endian numbers
+type DLHHVector struct {
+	Plain1         Hex `json:"plain1"`
+	Plain2         Hex `json:"plain2"`
+	Scalar         Hex `json:"scalar"`
+	Hidden1        Hex `json:"hidden1"`
+	Hidden2        Hex `json:"hidden2"`
+	CombinedHidden Hex `json:"combined_hidden"`
+	CombinedPlain  Hex `json:"combined_plain"`
+	ScaledHidden   Hex `json:"scaled_hidden"`
+}
+
+func (v DLHHVector) compute() DLHHVector {
+	dlh := dlhh.New(dl.NewOakley2Group())
+
+	r := DLHHVector{Plain1: v.Plain1, Plain2: v.Plain2, Scalar: v.Scalar}
+	r.Hidden1 = dlh.Hide(v.Plain1)
+	r.Hidden2 = dlh.Hide(v.Plain2)
+	r.CombinedHidden = dlh.CombineHidden(r.Hidden1, r.Hidden2)
+	r.CombinedPlain = dlh.CombinePlain(v.Plain1, v.Plain2)
+	r.ScaledHidden = dlh.ScaleHidden(r.Hidden1, v.Scalar)
+
+	return r
+}
+
+func (v DLHHVector) Check() error {
+	got := v.compute()
+
+	for _, field := range []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{"hidden1", got.Hidden1, v.Hidden1},
+		{"hidden2", got.Hidden2, v.Hidden2},
+		{"combined hidden", got.CombinedHidden, v.CombinedHidden},
+		{"combined plain", got.CombinedPlain, v.CombinedPlain},
+		{"scaled hidden", got.ScaledHidden, v.ScaledHidden},
+	} {
+		if err := compare(field.name, field.got, field.want); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func generateDLHH() *Set[DLHHVector] {
+	src := newSource("dlhh")
+	vectors := []DLHHVector{}
+
+	for _, size := range []int{1, 16, 32, 64} {
+		v := DLHHVector{Plain1: src.bytes(size), Plain2: src.bytes(size), Scalar: src.bytes(8)}
+		vectors = append(vectors, v.compute())
+	}
+
+	return newSet("dlhh", "Hide, CombineHidden, CombinePlain and ScaleHidden(hidden1, scalar) over the Oakley group 2", vectors)
+}
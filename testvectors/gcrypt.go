@@ -0,0 +1,69 @@
+package testvectors
+
+import (
+	"github.com/titosilva/pdpr-go/crypto/encryption/gcrypt"
+)
+
+type GCryptVector struct {
+	ModulusBits uint64 `json:"modulus_bits"`
+	Key         Hex    `json:"key"`
+	Plaintext   Hex    `json:"plaintext"`
+	Ciphertext  Hex    `json:"ciphertext"`
+	// BitsPerBlock and Headroom are the encoding, the bit encoding if zero
+	BitsPerBlock uint64 `json:"bits_per_block,omitempty"`
+	Headroom     uint64 `json:"headroom,omitempty"`
+}
+
+func (v GCryptVector) encoding() gcrypt.Encoding {
+	if v.BitsPerBlock == 0 {
+		return gcrypt.BitEncoding
+	}
+
+	return gcrypt.Encoding{BitsPerBlock: v.BitsPerBlock, Headroom: v.Headroom}
+}
+
+func (v GCryptVector) Check() error {
+	crypt, err := gcrypt.NewWithEncoding(v.ModulusBits, v.encoding())
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := crypt.Encrypt(v.Plaintext, v.Key)
+	if err != nil {
+		return err
+	}
+
+	if err := compare("ciphertext", ciphertext, v.Ciphertext); err != nil {
+		return err
+	}
+
+	// decryption pads the plaintext with zeros to whole blocks
+	decrypted := crypt.Decrypt(v.Ciphertext, v.Key)
+	padded := make([]byte, max(len(decrypted), len(v.Plaintext)))
+	copy(padded, v.Plaintext)
+
+	return compare("decryption", decrypted, padded)
+}
+
+func generateGCrypt() *Set[GCryptVector] {
+	src := newSource("gcrypt")
+	vectors := []GCryptVector{}
+
+	for _, bits := range []uint64{64, 128, 192, 256} {
+		for _, size := range []int{0, 1, 13, 16} {
+			v := GCryptVector{ModulusBits: bits, Key: src.bytes(16), Plaintext: src.bytes(size)}
+			v.Ciphertext = must(gcrypt.MustNew(bits).Encrypt(v.Plaintext, v.Key))
+			vectors = append(vectors, v)
+		}
+	}
+
+	for _, encoding := range []gcrypt.Encoding{{BitsPerBlock: 8}, {BitsPerBlock: 16, Headroom: 8}, {BitsPerBlock: 64}} {
+		for _, size := range []int{0, 13, 16} {
+			v := GCryptVector{ModulusBits: 128, Key: src.bytes(16), Plaintext: src.bytes(size), BitsPerBlock: encoding.BitsPerBlock, Headroom: encoding.Headroom}
+			v.Ciphertext = must(gcrypt.MustNewWithEncoding(128, encoding).Encrypt(v.Plaintext, v.Key))
+			vectors = append(vectors, v)
+		}
+	}
+
+	return newSet("gcrypt", "Encrypt(plaintext, key) with a modulus of modulus_bits, and the encoding of bits_per_block and headroom if set", vectors)
+}
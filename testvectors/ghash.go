@@ -0,0 +1,77 @@
+package testvectors
+
+import (
+	"github.com/titosilva/pdpr-go/crypto/hash/ghash"
+)
+
+// GHashVector sets the nonce, then adds the data with AddBytes.
+// The block size is the chunk size, as in package pdpr
+type GHashVector struct {
+	ChunkCount    uint `json:"chunk_count"`
+	ChunkSizeBits uint `json:"chunk_size_bits"`
+	Key           Hex  `json:"key"`
+	Nonce         Hex  `json:"nonce"`
+	Data          Hex  `json:"data"`
+	NonceHash     Hex  `json:"nonce_hash"`
+	Digest        Hex  `json:"digest"`
+}
+
+func (v GHashVector) hash() (*ghash.GHash, error) {
+	var key []byte
+	if len(v.Key) > 0 {
+		key = v.Key
+	}
+
+	hash, err := ghash.NewWithParams(v.ChunkCount, v.ChunkSizeBits, int(v.ChunkSizeBits/8), key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := hash.SetNonce(v.Nonce); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+func (v GHashVector) Check() error {
+	hash, err := v.hash()
+	if err != nil {
+		return err
+	}
+
+	if err := compare("nonce hash", hash.GetNonceHash(), v.NonceHash); err != nil {
+		return err
+	}
+
+	if err := hash.AddBytes(v.Data); err != nil {
+		return err
+	}
+
+	return compare("digest", hash.GetDigest(), v.Digest)
+}
+
+func generateGHash() *Set[GHashVector] {
+	src := newSource("ghash")
+	vectors := []GHashVector{}
+
+	for _, bits := range []uint{64, 128, 256} {
+		for _, keyed := range []bool{false, true} {
+			v := GHashVector{ChunkCount: 8, ChunkSizeBits: bits, Key: Hex{}, Nonce: src.bytes(32), Data: src.bytes(int(3*bits/8) + 5)}
+			if keyed {
+				v.Key = src.bytes(32)
+			}
+
+			hash := must(v.hash())
+			v.NonceHash = hash.GetNonceHash()
+			if err := hash.AddBytes(v.Data); err != nil {
+				panic(err)
+			}
+			v.Digest = hash.GetDigest()
+
+			vectors = append(vectors, v)
+		}
+	}
+
+	return newSet("ghash", "SetNonce(nonce), then AddBytes(data), with blocks of chunk_size_bits; an empty key is unkeyed", vectors)
+}
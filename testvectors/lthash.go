@@ -0,0 +1,81 @@
+package testvectors
+
+import (
+	"github.com/titosilva/pdpr-go/crypto/hash/lthash"
+)
+
+// LtHashVector adds every element, then removes the ones in Removed.
+// An empty key is the unkeyed hash
+type LtHashVector struct {
+	ChunkCount     uint  `json:"chunk_count"`
+	ChunkSizeBits  uint  `json:"chunk_size_bits"`
+	BlockSizeBytes int   `json:"block_size_bytes"`
+	Key            Hex   `json:"key"`
+	Elements       []Hex `json:"elements"`
+	Removed        []Hex `json:"removed"`
+	Digest         Hex   `json:"digest"`
+}
+
+func (v LtHashVector) digest() ([]byte, error) {
+	var key []byte
+	if len(v.Key) > 0 {
+		key = v.Key
+	}
+
+	hash, err := lthash.New(v.ChunkCount, v.ChunkSizeBits, v.BlockSizeBytes, key)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, element := range v.Elements {
+		if err := hash.Add(element); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, element := range v.Removed {
+		if err := hash.Remove(element); err != nil {
+			return nil, err
+		}
+	}
+
+	return hash.GetDigest(), nil
+}
+
+func (v LtHashVector) Check() error {
+	digest, err := v.digest()
+	if err != nil {
+		return err
+	}
+
+	return compare("digest", digest, v.Digest)
+}
+
+func generateLtHash() *Set[LtHashVector] {
+	src := newSource("lthash")
+	vectors := []LtHashVector{}
+
+	for _, params := range []lthash.Params{
+		{ChunkCount: 1, ChunkSizeBits: 64, BlockSizeBytes: 8},
+		{ChunkCount: 16, ChunkSizeBits: 64, BlockSizeBytes: 8},
+		{ChunkCount: 8, ChunkSizeBits: 128, BlockSizeBytes: 16},
+		{ChunkCount: 4, ChunkSizeBits: 256, BlockSizeBytes: 32},
+	} {
+		for _, keyed := range []bool{false, true} {
+			v := LtHashVector{ChunkCount: params.ChunkCount, ChunkSizeBits: params.ChunkSizeBits, BlockSizeBytes: params.BlockSizeBytes, Key: Hex{}}
+			if keyed {
+				v.Key = src.bytes(32)
+			}
+
+			for i := 0; i < 3; i++ {
+				v.Elements = append(v.Elements, src.bytes(1+i*params.BlockSizeBytes))
+			}
+			v.Removed = []Hex{v.Elements[1]}
+
+			v.Digest = must(v.digest())
+			vectors = append(vectors, v)
+		}
+	}
+
+	return newSet("lthash", "Add every element in order, then Remove the removed ones; an empty key is unkeyed", vectors)
+}
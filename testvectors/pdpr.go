@@ -0,0 +1,139 @@
+package testvectors
+
+import (
+	"slices"
+
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+	"github.com/titosilva/pdpr-go/pdpr"
+)
+
+// PDPrVector fixes the nonces of NewTagWithNonces, so that the tag, state and
+// proofs are deterministic. Every token of the tag is issued and proven
+type PDPrVector struct {
+	ModulusBits uint        `json:"modulus_bits"`
+	ChunkCount  uint        `json:"chunk_count"`
+	Key         Hex         `json:"key"`
+	Plaintext   Hex         `json:"plaintext"`
+	DataNonce   Hex         `json:"data_nonce"`
+	KeyNonce    Hex         `json:"key_nonce"`
+	TokenCount  int         `json:"token_count"`
+	SampleCount int         `json:"sample_count"`
+	Ciphertext  Hex         `json:"ciphertext"`
+	NonceState  Hex         `json:"nonce_state"`
+	Tokens      []PDPrToken `json:"tokens"`
+}
+
+// PDPrToken is a token of the tag, with the challenge it issues and its proof
+type PDPrToken struct {
+	Nonce    Hex      `json:"nonce"`
+	Expected Hex      `json:"expected"`
+	Indices  []uint64 `json:"indices"`
+	Proof    Hex      `json:"proof"`
+}
+
+func (v PDPrVector) params() pdpr.Params {
+	return pdpr.Params{ModulusBits: v.ModulusBits, ChunkCount: v.ChunkCount}
+}
+
+func (v PDPrVector) compute() (PDPrVector, error) {
+	params := v.params()
+	r := PDPrVector{ModulusBits: v.ModulusBits, ChunkCount: v.ChunkCount, Key: v.Key, Plaintext: v.Plaintext, DataNonce: v.DataNonce, KeyNonce: v.KeyNonce, TokenCount: v.TokenCount, SampleCount: v.SampleCount}
+
+	var err error
+	if r.Ciphertext, err = pdpr.Encrypt(params, v.Key, v.Plaintext); err != nil {
+		return r, err
+	}
+
+	tag, state, err := pdpr.NewTagWithNonces(params, v.Key, r.Ciphertext, len(v.Plaintext), v.TokenCount, v.SampleCount, v.DataNonce, v.KeyNonce)
+	if err != nil {
+		return r, err
+	}
+	r.NonceState = state.NonceState
+
+	for _, token := range tag.Tokens {
+		challenge, err := tag.NextChallenge(params)
+		if err != nil {
+			return r, err
+		}
+
+		proof, err := pdpr.Prove(params, r.Ciphertext, state, challenge)
+		if err != nil {
+			return r, err
+		}
+
+		r.Tokens = append(r.Tokens, PDPrToken{Nonce: token.Nonce, Expected: token.Expected, Indices: challenge.Indices, Proof: proof})
+	}
+
+	return r, nil
+}
+
+func (v PDPrVector) Check() error {
+	got, err := v.compute()
+	if err != nil {
+		return err
+	}
+
+	if err := compare("ciphertext", got.Ciphertext, v.Ciphertext); err != nil {
+		return err
+	}
+
+	if err := compare("nonce state", got.NonceState, v.NonceState); err != nil {
+		return err
+	}
+
+	tag := &pdpr.Tag{Length: len(v.Plaintext), KeyNonce: v.KeyNonce, SampleCount: v.SampleCount}
+	for _, token := range v.Tokens {
+		tag.Tokens = append(tag.Tokens, pdpr.Token{Nonce: token.Nonce, Expected: token.Expected})
+	}
+
+	if len(v.Tokens) != v.TokenCount {
+		return errorutils.NewfWithInner(ErrMismatch, "%d tokens, want %d", len(v.Tokens), v.TokenCount)
+	}
+
+	for i, token := range v.Tokens {
+		want := got.Tokens[i]
+		for _, field := range []struct {
+			name string
+			got  []byte
+			want []byte
+		}{
+			{"token nonce", want.Nonce, token.Nonce},
+			{"token expected digest", want.Expected, token.Expected},
+			{"proof", want.Proof, token.Proof},
+		} {
+			if err := compare(field.name, field.got, field.want); err != nil {
+				return err
+			}
+		}
+
+		if !slices.Equal(want.Indices, token.Indices) {
+			return errorutils.NewfWithInner(ErrMismatch, "indices are %v, want %v", want.Indices, token.Indices)
+		}
+
+		challenge := &pdpr.Challenge{Nonce: token.Nonce, Indices: token.Indices}
+		if !pdpr.Verify(v.params(), v.Key, tag, challenge, token.Proof) {
+			return errorutils.NewWithInner(ErrMismatch, "proof does not verify")
+		}
+	}
+
+	return nil
+}
+
+func generatePDPr() *Set[PDPrVector] {
+	src := newSource("pdpr")
+	vectors := []PDPrVector{}
+
+	for _, params := range []pdpr.Params{{ModulusBits: 64, ChunkCount: 16}, {ModulusBits: 128, ChunkCount: 32}} {
+		for _, size := range []int{1, 29} {
+			v := PDPrVector{ModulusBits: params.ModulusBits, ChunkCount: params.ChunkCount, TokenCount: 2, SampleCount: 8}
+			v.Key = src.bytes(32)
+			v.Plaintext = src.bytes(size)
+			v.DataNonce = src.bytes(32)
+			v.KeyNonce = src.bytes(32)
+
+			vectors = append(vectors, must(v.compute()))
+		}
+	}
+
+	return newSet("pdpr", "Encrypt, NewTagWithNonces with 2 tokens, and Prove of each challenge of NextChallenge; the tag of Verify has the key nonce, sample count and tokens", vectors)
+}
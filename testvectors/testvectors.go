@@ -0,0 +1,183 @@
+// Package testvectors holds known-answer test vectors for GCrypt, LtHash,
+// GHash, DLHH and PDPr, so that refactors cannot silently change outputs and
+// implementations in other languages can check their compatibility.
+//
+// The vectors are JSON files under vectors/, written by cmd/pdpr-vectors.
+// Byte strings are hex encoded, and every input is derived deterministically,
+// so that regenerating the vectors yields the same files
+package testvectors
+
+import (
+	"bytes"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/titosilva/pdpr-go/crypto/random/drbg/sha256drbg"
+	errorutils "github.com/titosilva/pdpr-go/internal/error"
+)
+
+//go:embed vectors/*.json
+var files embed.FS
+
+// Version is increased whenever the format of the files changes
+const Version = 2
+
+// Names lists every set of vectors, which is stored in vectors/{name}.json
+var Names = []string{"gcrypt", "lthash", "ghash", "dlhh", "pdpr"}
+
+var ErrMismatch = errors.New("output does not match the test vector")
+var ErrUnknownSet = errors.New("unknown set of test vectors")
+
+// Hex is a byte string, encoded in JSON as a hex string
+type Hex []byte
+
+func (h Hex) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
+
+func (h *Hex) UnmarshalJSON(bs []byte) error {
+	var s string
+	if err := json.Unmarshal(bs, &s); err != nil {
+		return err
+	}
+
+	r, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+
+	*h = r
+	return nil
+}
+
+// Vector is a single test vector, which can check the implementation against itself
+type Vector interface {
+	Check() error
+}
+
+// Set is the content of a vector file
+type Set[V Vector] struct {
+	Algorithm   string `json:"algorithm"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Vectors     []V    `json:"vectors"`
+}
+
+// Check checks every vector of the set
+func (s *Set[V]) Check() error {
+	for i, v := range s.Vectors {
+		if err := v.Check(); err != nil {
+			return errorutils.NewfWithInner(err, "%s vector %d", s.Algorithm, i)
+		}
+	}
+
+	return nil
+}
+
+func newSet[V Vector](algorithm string, description string, vectors []V) *Set[V] {
+	return &Set[V]{Algorithm: algorithm, Version: Version, Description: description, Vectors: vectors}
+}
+
+// Load reads a set of vectors from the embedded files
+func Load[V Vector](name string) (*Set[V], error) {
+	bs, err := files.ReadFile("vectors/" + name + ".json")
+	if err != nil {
+		return nil, errorutils.NewfWithInner(ErrUnknownSet, "%s: %v", name, err)
+	}
+
+	r := new(Set[V])
+	if err := json.Unmarshal(bs, r); err != nil {
+		return nil, errorutils.NewfWithInner(err, "could not parse the %s vectors", name)
+	}
+
+	return r, nil
+}
+
+// Embedded returns the embedded file of a set of vectors
+func Embedded(name string) ([]byte, error) {
+	return files.ReadFile("vectors/" + name + ".json")
+}
+
+// Generate computes a set of vectors with the current implementation, and
+// encodes it as in the vector files
+func Generate(name string) ([]byte, error) {
+	var set any
+
+	switch name {
+	case "gcrypt":
+		set = generateGCrypt()
+	case "lthash":
+		set = generateLtHash()
+	case "ghash":
+		set = generateGHash()
+	case "dlhh":
+		set = generateDLHH()
+	case "pdpr":
+		set = generatePDPr()
+	default:
+		return nil, errorutils.NewfWithInner(ErrUnknownSet, "%s", name)
+	}
+
+	bs, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bs, '\n'), nil
+}
+
+// Write generates every set of vectors into dir
+func Write(dir string) error {
+	for _, name := range Names {
+		bs, err := Generate(name)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), bs, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// source derives the inputs of a set of vectors from its name
+type source struct {
+	drbg *sha256drbg.SHA256DRBG
+}
+
+func newSource(name string) *source {
+	r := new(source)
+	r.drbg = sha256drbg.New()
+	r.drbg.Seed([]byte("pdpr-go/testvectors/" + name))
+
+	return r
+}
+
+func (s *source) bytes(n int) Hex {
+	r, _ := s.drbg.Generate(n)
+	return r
+}
+
+func compare(field string, got []byte, want []byte) error {
+	if !bytes.Equal(got, want) {
+		return errorutils.NewfWithInner(ErrMismatch, "%s is %x, want %x", field, got, want)
+	}
+
+	return nil
+}
+
+// must panics on errors of the implementation while generating vectors,
+// which only happen if the parameters of the generator are wrong
+func must[T any](r T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
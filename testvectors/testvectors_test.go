@@ -0,0 +1,70 @@
+package testvectors_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/titosilva/pdpr-go/internal/ez"
+	"github.com/titosilva/pdpr-go/testvectors"
+)
+
+func Test__Vectors__ShouldMatch__Generator(t *testing.T) {
+	ez := ez.New(t)
+
+	for _, name := range testvectors.Names {
+		generated, err := testvectors.Generate(name)
+		ez.AssertNoError(err)
+
+		embedded, err := testvectors.Embedded(name)
+		ez.AssertNoError(err)
+
+		if string(generated) != string(embedded) {
+			t.Fatalf("%s vectors changed; rerun go run ./cmd/pdpr-vectors if the change is intended", name)
+		}
+	}
+}
+
+func Test__GCryptVectors__ShouldPass(t *testing.T) {
+	checkSet[testvectors.GCryptVector](t, "gcrypt")
+}
+
+func Test__LtHashVectors__ShouldPass(t *testing.T) {
+	checkSet[testvectors.LtHashVector](t, "lthash")
+}
+
+func Test__GHashVectors__ShouldPass(t *testing.T) {
+	checkSet[testvectors.GHashVector](t, "ghash")
+}
+
+func Test__DLHHVectors__ShouldPass(t *testing.T) {
+	checkSet[testvectors.DLHHVector](t, "dlhh")
+}
+
+func Test__PDPrVectors__ShouldPass(t *testing.T) {
+	checkSet[testvectors.PDPrVector](t, "pdpr")
+}
+
+func Test__Vector__ShouldFail__WhenOutputDiffers(t *testing.T) {
+	ez := ez.New(t)
+
+	set, err := testvectors.Load[testvectors.GHashVector]("ghash")
+	ez.AssertNoError(err)
+
+	v := set.Vectors[0]
+	v.Digest = append(testvectors.Hex{}, v.Digest...)
+	v.Digest[0] ^= 1
+	ez.Assert(errors.Is(v.Check(), testvectors.ErrMismatch))
+
+	_, err = testvectors.Load[testvectors.GHashVector]("unknown")
+	ez.Assert(errors.Is(err, testvectors.ErrUnknownSet))
+}
+
+func checkSet[V testvectors.Vector](t *testing.T, name string) {
+	ez := ez.New(t)
+
+	set, err := testvectors.Load[V](name)
+	ez.AssertNoError(err)
+	ez.AssertAreEqual(set.Version, testvectors.Version)
+	ez.Assert(len(set.Vectors) > 0)
+	ez.AssertNoError(set.Check())
+}